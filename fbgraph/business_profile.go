@@ -0,0 +1,203 @@
+package fbgraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// BusinessVertical is the industry of a business profile.
+type BusinessVertical string
+
+const (
+	VerticalUndefined     BusinessVertical = "UNDEFINED"
+	VerticalOther         BusinessVertical = "OTHER"
+	VerticalAuto          BusinessVertical = "AUTO"
+	VerticalBeauty        BusinessVertical = "BEAUTY"
+	VerticalApparel       BusinessVertical = "APPAREL"
+	VerticalEducation     BusinessVertical = "EDU"
+	VerticalEntertainment BusinessVertical = "ENTERTAIN"
+	VerticalEventPlanning BusinessVertical = "EVENT_PLAN"
+	VerticalFinance       BusinessVertical = "FINANCE"
+	VerticalGrocery       BusinessVertical = "GROCERY"
+	VerticalGovernment    BusinessVertical = "GOVT"
+	VerticalHotel         BusinessVertical = "HOTEL"
+	VerticalHealth        BusinessVertical = "HEALTH"
+	VerticalNonprofit     BusinessVertical = "NONPROFIT"
+	VerticalProfServices  BusinessVertical = "PROF_SERVICES"
+	VerticalRetail        BusinessVertical = "RETAIL"
+	VerticalTravel        BusinessVertical = "TRAVEL"
+	VerticalRestaurant    BusinessVertical = "RESTAURANT"
+	VerticalNotABusiness  BusinessVertical = "NOT_A_BIZ"
+)
+
+// Business profile limits, in characters.
+const (
+	MaxProfileAboutLength       = 139
+	MaxProfileAddressLength     = 256
+	MaxProfileDescriptionLength = 512
+	MaxProfileEmailLength       = 128
+	MaxProfileWebsiteLength     = 256
+	MaxProfileWebsites          = 2
+)
+
+// BusinessProfile is the WhatsApp Business profile of a phone number, shown to
+// users in the business' contact info.
+type BusinessProfile struct {
+	About             string           `json:"about,omitempty"`
+	Address           string           `json:"address,omitempty"`
+	Description       string           `json:"description,omitempty"`
+	Email             string           `json:"email,omitempty"`
+	ProfilePictureURL string           `json:"profile_picture_url,omitempty"`
+	Vertical          BusinessVertical `json:"vertical,omitempty"`
+	Websites          []string         `json:"websites,omitempty"`
+}
+
+// UpdateBusinessProfileParams are the fields UpdateBusinessProfile changes.
+// Empty fields are left as they are.
+type UpdateBusinessProfileParams struct {
+	About       string           `json:"about,omitempty"`
+	Address     string           `json:"address,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Email       string           `json:"email,omitempty"`
+	Vertical    BusinessVertical `json:"vertical,omitempty"`
+	// Websites replaces the profile's websites; at most 2, each starting
+	// with http:// or https://.
+	Websites []string `json:"websites,omitempty"`
+	// ProfilePictureHandle is an upload session file handle, as returned by
+	// UploadHeaderHandle. UpdateBusinessProfilePicture sets it.
+	ProfilePictureHandle string `json:"profile_picture_handle,omitempty"`
+}
+
+// Validate checks the params against the profile limits, so an update is not
+// rejected by Meta halfway through a batch of phone numbers.
+func (p UpdateBusinessProfileParams) Validate() error {
+	fields := []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"about", p.About, MaxProfileAboutLength},
+		{"address", p.Address, MaxProfileAddressLength},
+		{"description", p.Description, MaxProfileDescriptionLength},
+		{"email", p.Email, MaxProfileEmailLength},
+	}
+	for _, f := range fields {
+		if n := utf8.RuneCountInString(f.value); n > f.max {
+			return fmt.Errorf("%s is %d characters long, the limit is %d", f.name, n, f.max)
+		}
+	}
+	if p.Email != "" && !strings.Contains(p.Email, "@") {
+		return fmt.Errorf("email %q is not an email address", p.Email)
+	}
+	if len(p.Websites) > MaxProfileWebsites {
+		return fmt.Errorf("%d websites, the limit is %d", len(p.Websites), MaxProfileWebsites)
+	}
+	for _, w := range p.Websites {
+		if !strings.HasPrefix(w, "http://") && !strings.HasPrefix(w, "https://") {
+			return fmt.Errorf("website %q must start with http:// or https://", w)
+		}
+		if n := utf8.RuneCountInString(w); n > MaxProfileWebsiteLength {
+			return fmt.Errorf("website %q is %d characters long, the limit is %d", w, n, MaxProfileWebsiteLength)
+		}
+	}
+	return nil
+}
+
+// GetBusinessProfile reads the business profile of a phone number.
+//
+// GET /{PHONE_NUMBER_ID}/whatsapp_business_profile
+func (c *Client) GetBusinessProfile(ctx context.Context, phoneID string) (*BusinessProfile, error) {
+	c.resetLastError()
+
+	q := url.Values{"fields": {"about,address,description,email,profile_picture_url,vertical,websites"}}
+	u := fmt.Sprintf("%s/%s/%s/whatsapp_business_profile?%s", c.baseURL(), c.graphVersion(), url.PathEscape(phoneID), q.Encode())
+	req, err := NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.errorFromResponse(resp)
+	}
+	result := struct {
+		Data []BusinessProfile `json:"data"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(result.Data) == 0 {
+		return &BusinessProfile{}, nil
+	}
+	return &result.Data[0], nil
+}
+
+// UpdateBusinessProfile changes the business profile of a phone number.
+//
+// POST /{PHONE_NUMBER_ID}/whatsapp_business_profile
+func (c *Client) UpdateBusinessProfile(ctx context.Context, phoneID string, params UpdateBusinessProfileParams) error {
+	c.resetLastError()
+
+	if err := params.Validate(); err != nil {
+		return fmt.Errorf("invalid business profile: %w", err)
+	}
+	body := struct {
+		MessagingProduct string `json:"messaging_product"`
+		UpdateBusinessProfileParams
+	}{"whatsapp", params}
+	jd, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+	u := fmt.Sprintf("%s/%s/%s/whatsapp_business_profile", c.baseURL(), c.graphVersion(), url.PathEscape(phoneID))
+	req, err := NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(jd))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return c.errorFromResponse(resp)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// UpdateBusinessProfilePicture uploads a JPEG or PNG image through an upload
+// session of the app fbAppID (NewUploadSession, then UploadHeaderHandle) and
+// sets it as the profile picture of a phone number. Meta recommends a square
+// image of at least 640x640 pixels.
+func (c *Client) UpdateBusinessProfilePicture(ctx context.Context, fbAppID, phoneID, mimeType string, r io.Reader, size int64) error {
+	if mimeType != "image/jpeg" && mimeType != "image/png" {
+		return fmt.Errorf("profile picture must be image/jpeg or image/png, not %q", mimeType)
+	}
+	id, err := c.NewUploadSessionWithContext(ctx, fbAppID, NewUploadSessionParams{
+		FileLength: size,
+		FileName:   "profile_picture",
+		FileType:   mimeType,
+	})
+	if err != nil {
+		return fmt.Errorf("new upload session: %w", err)
+	}
+	h, err := c.UploadHeaderHandleWithContext(ctx, id, r)
+	if err != nil {
+		return fmt.Errorf("upload profile picture: %w", err)
+	}
+	return c.UpdateBusinessProfile(ctx, phoneID, UpdateBusinessProfileParams{ProfilePictureHandle: h})
+}
@@ -0,0 +1,72 @@
+package fbgraph_test
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+	"github.com/pedidopago/wabaman-contrib/fbgraph/fbgraphtest"
+)
+
+func TestBusinessProfile(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	srv.AddPhoneNumber("waba1", fbgraph.WABAPhoneNumber{ID: "phone1"})
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	err := c.UpdateBusinessProfile(ctx, "phone1", fbgraph.UpdateBusinessProfileParams{
+		About:    "Farmácia de manipulação",
+		Address:  "Rua Augusta, 100 - São Paulo",
+		Email:    "contato@example.com",
+		Vertical: fbgraph.VerticalHealth,
+		Websites: []string{"https://example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.UpdateBusinessProfile(ctx, "phone1", fbgraph.UpdateBusinessProfileParams{Description: "Desde 1998"}); err != nil {
+		t.Fatal(err)
+	}
+
+	img := bytes.Repeat([]byte{0xff}, 2048)
+	if err := c.UpdateBusinessProfilePicture(ctx, "app1", "phone1", "image/jpeg", bytes.NewReader(img), int64(len(img))); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := c.GetBusinessProfile(ctx, "phone1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.About != "Farmácia de manipulação" || p.Description != "Desde 1998" || p.Vertical != fbgraph.VerticalHealth ||
+		!slices.Equal(p.Websites, []string{"https://example.com"}) || p.ProfilePictureURL == "" {
+		t.Errorf("profile = %+v", p)
+	}
+}
+
+func TestUpdateBusinessProfileValidation(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	srv.AddPhoneNumber("waba1", fbgraph.WABAPhoneNumber{ID: "phone1"})
+	c := srv.Client("tok")
+
+	for _, params := range []fbgraph.UpdateBusinessProfileParams{
+		{About: strings.Repeat("a", fbgraph.MaxProfileAboutLength+1)},
+		{Email: "not-an-email"},
+		{Websites: []string{"example.com"}},
+		{Websites: []string{"https://a.com", "https://b.com", "https://c.com"}},
+	} {
+		if err := c.UpdateBusinessProfile(context.Background(), "phone1", params); err == nil {
+			t.Errorf("%+v was accepted", params)
+		}
+	}
+	if n := srv.CountRequests("POST", "/whatsapp_business_profile"); n != 0 {
+		t.Errorf("%d invalid updates were sent", n)
+	}
+	if err := c.UpdateBusinessProfilePicture(context.Background(), "app1", "phone1", "image/gif", bytes.NewReader(nil), 0); err == nil {
+		t.Error("gif profile picture was accepted")
+	}
+}
@@ -196,7 +196,7 @@ func (c *Client) GetWhatsappSettings(ctx context.Context, whatsappID string) (*W
 		apiVersion = c.GraphAPIVersion
 	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/settings", apiVersion, whatsappID)
+	url := fmt.Sprintf("%s/%s/%s/settings", c.baseURL(), apiVersion, whatsappID)
 
 	req, err := NewRequestWithContext(ctx, http.MethodGet, url, nil)
 
@@ -207,7 +207,7 @@ func (c *Client) GetWhatsappSettings(ctx context.Context, whatsappID string) (*W
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -240,15 +240,14 @@ func (c *Client) GetWhatsappSettings(ctx context.Context, whatsappID string) (*W
 // Calling restriction errors
 
 func (c *Client) UpdateWhatsappSettings(ctx context.Context, whatsappID string, settings *WhatsappSettings) error {
-	c.lastErrorRawBody = ""
-	c.lastGraphError = nil
+	c.resetLastError()
 
 	apiVersion := DefaultGraphAPIVersion
 	if c.GraphAPIVersion != "" {
 		apiVersion = c.GraphAPIVersion
 	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/settings", apiVersion, whatsappID)
+	url := fmt.Sprintf("%s/%s/%s/settings", c.baseURL(), apiVersion, whatsappID)
 
 	jd, err := json.Marshal(settings)
 	if err != nil {
@@ -266,7 +265,7 @@ func (c *Client) UpdateWhatsappSettings(ctx context.Context, whatsappID string,
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
@@ -299,7 +298,7 @@ func (c *Client) GetMessagingLimitingTier(ctx context.Context, whatsappID string
 		apiVersion = c.GraphAPIVersion
 	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s?fields=messaging_limit_tier,whatsapp_business_manager_messaging_limit", apiVersion, whatsappID)
+	url := fmt.Sprintf("%s/%s/%s?fields=messaging_limit_tier,whatsapp_business_manager_messaging_limit", c.baseURL(), apiVersion, whatsappID)
 
 	req, err := NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -309,7 +308,7 @@ func (c *Client) GetMessagingLimitingTier(ctx context.Context, whatsappID string
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
 	}
@@ -355,8 +354,7 @@ type WebhookObject struct {
 }
 
 func (c *Client) GetAppSubscribedWebhooks(ctx context.Context, appID, appSecret string) ([]WebhookObject, error) {
-	c.lastErrorRawBody = ""
-	c.lastGraphError = nil
+	c.resetLastError()
 
 	apiVersion := DefaultGraphAPIVersion
 	if c.GraphAPIVersion != "" {
@@ -365,7 +363,7 @@ func (c *Client) GetAppSubscribedWebhooks(ctx context.Context, appID, appSecret
 
 	specialToken := fmt.Sprintf("%s|%s", appID, appSecret)
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/subscriptions", apiVersion, appID)
+	url := fmt.Sprintf("%s/%s/%s/subscriptions", c.baseURL(), apiVersion, appID)
 
 	req, err := NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -376,7 +374,7 @@ func (c *Client) GetAppSubscribedWebhooks(ctx context.Context, appID, appSecret
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", specialToken))
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -414,8 +412,7 @@ type commonAcceptCallObject struct {
 }
 
 func (c *Client) PreAcceptCall(ctx context.Context, whatsappID string, r *AcceptCallRequest) error {
-	c.lastErrorRawBody = ""
-	c.lastGraphError = nil
+	c.resetLastError()
 
 	if r == nil {
 		return fmt.Errorf("request is nil")
@@ -426,7 +423,7 @@ func (c *Client) PreAcceptCall(ctx context.Context, whatsappID string, r *Accept
 		apiVersion = c.GraphAPIVersion
 	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/calls", apiVersion, whatsappID)
+	url := fmt.Sprintf("%s/%s/%s/calls", c.baseURL(), apiVersion, whatsappID)
 
 	reqO := commonAcceptCallObject{
 		AcceptCallRequest: *r,
@@ -450,7 +447,7 @@ func (c *Client) PreAcceptCall(ctx context.Context, whatsappID string, r *Accept
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
@@ -466,8 +463,7 @@ func (c *Client) PreAcceptCall(ctx context.Context, whatsappID string, r *Accept
 }
 
 func (c *Client) AcceptCall(ctx context.Context, whatsappID string, r *AcceptCallRequest) error {
-	c.lastErrorRawBody = ""
-	c.lastGraphError = nil
+	c.resetLastError()
 
 	if r == nil {
 		return fmt.Errorf("request is nil")
@@ -478,7 +474,7 @@ func (c *Client) AcceptCall(ctx context.Context, whatsappID string, r *AcceptCal
 		apiVersion = c.GraphAPIVersion
 	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/calls", apiVersion, whatsappID)
+	url := fmt.Sprintf("%s/%s/%s/calls", c.baseURL(), apiVersion, whatsappID)
 
 	reqO := commonAcceptCallObject{
 		AcceptCallRequest: *r,
@@ -502,7 +498,7 @@ func (c *Client) AcceptCall(ctx context.Context, whatsappID string, r *AcceptCal
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
@@ -518,15 +514,14 @@ func (c *Client) AcceptCall(ctx context.Context, whatsappID string, r *AcceptCal
 }
 
 func (c *Client) TerminateCall(ctx context.Context, whatsappID string, callID string) error {
-	c.lastErrorRawBody = ""
-	c.lastGraphError = nil
+	c.resetLastError()
 
 	apiVersion := DefaultGraphAPIVersion
 	if c.GraphAPIVersion != "" {
 		apiVersion = c.GraphAPIVersion
 	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/calls", apiVersion, whatsappID)
+	url := fmt.Sprintf("%s/%s/%s/calls", c.baseURL(), apiVersion, whatsappID)
 
 	reqO := struct {
 		MessagingProduct string `json:"messaging_product"`
@@ -554,7 +549,7 @@ func (c *Client) TerminateCall(ctx context.Context, whatsappID string, callID st
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
@@ -580,15 +575,14 @@ type InitiateCallResponse struct {
 }
 
 func (c *Client) InitiateCall(ctx context.Context, phoneNumberID string, r InitiateCallRequest) (*InitiateCallResponse, error) {
-	c.lastErrorRawBody = ""
-	c.lastGraphError = nil
+	c.resetLastError()
 
 	apiVersion := DefaultGraphAPIVersion
 	if c.GraphAPIVersion != "" {
 		apiVersion = c.GraphAPIVersion
 	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/calls", apiVersion, phoneNumberID)
+	url := fmt.Sprintf("%s/%s/%s/calls", c.baseURL(), apiVersion, phoneNumberID)
 
 	reqO := struct {
 		MessagingProduct      string             `json:"messaging_product"`
@@ -620,7 +614,7 @@ func (c *Client) InitiateCall(ctx context.Context, phoneNumberID string, r Initi
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -686,7 +680,7 @@ func (c *Client) GetCallPermissions(ctx context.Context, phoneNumberID, userWAID
 		apiVersion = c.GraphAPIVersion
 	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/call_permissions?user_wa_id=%s", apiVersion, phoneNumberID, userWAID)
+	url := fmt.Sprintf("%s/%s/%s/call_permissions?user_wa_id=%s", c.baseURL(), apiVersion, phoneNumberID, userWAID)
 
 	req, err := NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -696,7 +690,7 @@ func (c *Client) GetCallPermissions(ctx context.Context, phoneNumberID, userWAID
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -713,6 +707,11 @@ func (c *Client) GetCallPermissions(ctx context.Context, phoneNumberID, userWAID
 }
 
 func (c *Client) SendCallPermissionRequest(phoneNumberID, to, bodyText string) (*MessageObjectResult, error) {
+	return c.SendCallPermissionRequestWithContext(context.Background(), phoneNumberID, to, bodyText)
+}
+
+// SendCallPermissionRequestWithContext is SendCallPermissionRequest bound to ctx.
+func (c *Client) SendCallPermissionRequestWithContext(ctx context.Context, phoneNumberID, to, bodyText string) (*MessageObjectResult, error) {
 	msgObj := &MessageObject{
 		MessagingProduct: "whatsapp",
 		To:               to,
@@ -727,7 +726,7 @@ func (c *Client) SendCallPermissionRequest(phoneNumberID, to, bodyText string) (
 	if bodyText != "" {
 		msgObj.Interactive.Body = &InteractiveTextObject{Text: bodyText}
 	}
-	return c.SendMessage(phoneNumberID, msgObj)
+	return c.SendMessageWithContext(ctx, phoneNumberID, msgObj)
 }
 
 func IsSubscribedToCalls(objs []WebhookObject, minVersion string) (bool, error) {
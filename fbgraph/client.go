@@ -2,15 +2,19 @@ package fbgraph
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -19,19 +23,41 @@ import (
 var (
 	DebugTrace             bool
 	DefaultGraphAPIVersion = "v23.0"
+	// DefaultBaseURL is the Graph API host used when Client.BaseURL is empty.
+	DefaultBaseURL = "https://graph.facebook.com"
+	// DefaultUnversionedBaseURL is the host of the few endpoints Meta only
+	// serves without a version prefix (see UnarchiveMessageTemplates).
+	DefaultUnversionedBaseURL = "https://api.facebook.com"
 )
 
 var DefaultHTTPClient = &http.Client{
 	Timeout: time.Second * 120,
 }
 
-// Client is not safe for concurrent use. Each goroutine must use its own
-// Client instance; shared instances require external synchronization.
+// Client is safe for concurrent use once configured: every method reports its
+// failure through the returned error (see AsGraphError), so a single Client
+// can be shared by all goroutines sending on behalf of a WABA. Do not change
+// the exported fields while requests are in flight.
 type Client struct {
 	HTTPClient      *http.Client
 	AccessToken     string
 	GraphAPIVersion string // use this to override the default API version (check DefaultGraphAPIVersion)
-
+	// BaseURL points every endpoint at another host, e.g. a local emulator, a
+	// recording proxy or a regional egress proxy. It may carry a path prefix.
+	// When set, media downloads are sent to this host too, keeping the path and
+	// query of the URL Meta returned. Defaults to DefaultBaseURL.
+	BaseURL string
+	// Middleware wraps the transport of every request made by this client, in
+	// order: Middleware[0] sees the request first.
+	Middleware []Middleware
+	// Retry, when set, retries requests that failed for a transient reason.
+	// See RetryPolicy.
+	Retry *RetryPolicy
+	// ValidateMessages makes the send methods check every message with
+	// (*MessageObject).Validate, failing before the Graph call.
+	ValidateMessages bool
+
+	mu               sync.Mutex
 	lastGraphError   *GraphError
 	lastErrorRawBody string
 }
@@ -43,57 +69,64 @@ func NewClient(accessToken string) *Client {
 	}
 }
 
+// LastGraphError returns the Graph error of the most recent failed call.
+//
+// Deprecated: when the Client is shared this may belong to another goroutine's
+// call. Use AsGraphError on the error returned by the method instead.
 func (c *Client) LastGraphError() *GraphError {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.lastGraphError
 }
 
+// LastErrorRawBody returns the response body of the most recent failed call.
+//
+// Deprecated: when the Client is shared this may belong to another goroutine's
+// call. Use GraphError.RawBody on the error returned by the method instead.
 func (c *Client) LastErrorRawBody() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.lastErrorRawBody
 }
 
+func (c *Client) resetLastError() {
+	c.mu.Lock()
+	c.lastGraphError = nil
+	c.lastErrorRawBody = ""
+	c.mu.Unlock()
+}
+
+func (c *Client) setLastError(gerr *GraphError, rawBody string) {
+	c.mu.Lock()
+	c.lastGraphError = gerr
+	c.lastErrorRawBody = rawBody
+	c.mu.Unlock()
+}
+
 // SendMessageFn is a function type for sending messages.
 // (*Client).SendMessage is the default implementation of this function.
 // (*Client).SendMarketingMessage is a specialized implementation for marketing messages.
 type SendMessageFn func(phoneID string, msg *MessageObject) (*MessageObjectResult, error)
 
-func (c *Client) SendMessage(phoneID string, msg *MessageObject) (*MessageObjectResult, error) {
-	if msg == nil {
-		return nil, fmt.Errorf("message is nil")
-	}
-	msg.routeBSUIDRecipient()
+// SendMessageWithContextFn is the context-aware counterpart of SendMessageFn.
+// (*Client).SendMessageWithContext and (*Client).SendMarketingMessageWithContext
+// implement it.
+type SendMessageWithContextFn func(ctx context.Context, phoneID string, msg *MessageObject) (*MessageObjectResult, error)
 
-	apiVersion := DefaultGraphAPIVersion
-	if c.GraphAPIVersion != "" {
-		apiVersion = c.GraphAPIVersion
+// WithoutContext adapts fn to the legacy SendMessageFn signature, sending with
+// context.Background().
+func (fn SendMessageWithContextFn) WithoutContext() SendMessageFn {
+	return func(phoneID string, msg *MessageObject) (*MessageObjectResult, error) {
+		return fn(context.Background(), phoneID, msg)
 	}
+}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/messages", apiVersion, phoneID)
-	buf := new(bytes.Buffer)
-	if err := json.NewEncoder(buf).Encode(msg); err != nil {
-		return nil, fmt.Errorf("encode message: %w", err)
-	}
-	if DebugTrace {
-		println("fbgraph SendMessage", url, "\n", buf.String())
-	}
-	req, err := NewRequest(http.MethodPost, url, buf)
-	if err != nil {
-		return nil, fmt.Errorf("new request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-	if resp.StatusCode != http.StatusOK {
-		return nil, c.errorFromResponse(resp)
-	}
-	result := &MessageObjectResult{}
-	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
-	}
-	return result, nil
+func (c *Client) SendMessage(phoneID string, msg *MessageObject) (*MessageObjectResult, error) {
+	return c.SendMessageWithContext(context.Background(), phoneID, msg)
+}
+
+func (c *Client) SendMessageWithContext(ctx context.Context, phoneID string, msg *MessageObject) (*MessageObjectResult, error) {
+	return c.sendMessage(ctx, "SendMessage", "messages", phoneID, msg)
 }
 
 // SendMarketingMessage uses the Marketing Messages API to send a marketing message to a phone ID.
@@ -101,31 +134,42 @@ func (c *Client) SendMessage(phoneID string, msg *MessageObject) (*MessageObject
 //
 // See https://developers.facebook.com/documentation/business-messaging/whatsapp/marketing-messages/overview
 func (c *Client) SendMarketingMessage(phoneID string, msg *MessageObject) (*MessageObjectResult, error) {
+	return c.SendMarketingMessageWithContext(context.Background(), phoneID, msg)
+}
+
+// SendMarketingMessageWithContext is SendMarketingMessage bound to ctx.
+func (c *Client) SendMarketingMessageWithContext(ctx context.Context, phoneID string, msg *MessageObject) (*MessageObjectResult, error) {
+	return c.sendMessage(ctx, "SendMarketingMessage", "marketing_messages", phoneID, msg)
+}
+
+func (c *Client) sendMessage(ctx context.Context, op, edge, phoneID string, msg *MessageObject) (*MessageObjectResult, error) {
+	c.resetLastError()
+
 	if msg == nil {
 		return nil, fmt.Errorf("message is nil")
 	}
 	msg.routeBSUIDRecipient()
-
-	apiVersion := DefaultGraphAPIVersion
-	if c.GraphAPIVersion != "" {
-		apiVersion = c.GraphAPIVersion
+	if c.ValidateMessages {
+		if err := msg.Validate(); err != nil {
+			return nil, err
+		}
 	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/marketing_messages", apiVersion, phoneID)
+	url := fmt.Sprintf("%s/%s/%s/%s", c.baseURL(), c.graphVersion(), phoneID, edge)
 	buf := new(bytes.Buffer)
 	if err := json.NewEncoder(buf).Encode(msg); err != nil {
 		return nil, fmt.Errorf("encode message: %w", err)
 	}
 	if DebugTrace {
-		println("fbgraph SendMarketingMessage", url, "\n", buf.String())
+		println("fbgraph", op, url, "\n", buf.String())
 	}
-	req, err := NewRequest(http.MethodPost, url, buf)
+	req, err := NewRequestWithContext(ctx, http.MethodPost, url, buf)
 	if err != nil {
 		return nil, fmt.Errorf("new request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -146,67 +190,83 @@ func escapeQuotes(s string) string {
 	return quoteEscaper.Replace(s)
 }
 
+// UploadMedia uploads fsize bytes of r as media of the business phone number.
+// The media is checked with ValidateMedia before anything is sent, so a type
+// WhatsApp does not take, or a file over its limit, fails with a
+// *MediaValidationError. An empty mimeType is detected from the content. When
+// fsize is 0 the size is what is left of r if it has a Len method or is a
+// regular file, and any other reader, os.Stdin included, is read into memory
+// first.
 func (c *Client) UploadMedia(phoneID string, mimeType string, r io.Reader, fsize int64, filename string) (id string, err error) {
-	pr, pw := io.Pipe()
-	mw := multipart.NewWriter(pw)
-
-	apiVersion := DefaultGraphAPIVersion
-	if c.GraphAPIVersion != "" {
-		apiVersion = c.GraphAPIVersion
-	}
+	return c.UploadMediaWithContext(context.Background(), phoneID, mimeType, r, fsize, filename)
+}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/media", apiVersion, phoneID)
+// UploadMediaWithContext is UploadMedia bound to ctx. Cancelling ctx aborts the
+// upload mid-stream.
+func (c *Client) UploadMediaWithContext(ctx context.Context, phoneID string, mimeType string, r io.Reader, fsize int64, filename string) (id string, err error) {
+	c.resetLastError()
 
-	// do the request concurrently
-	var resp *http.Response
-	done := make(chan error)
-	go func() {
-		req, err := NewRequest(http.MethodPost, url, pr)
+	if fsize <= 0 {
+		n, ok, err := readerSize(r)
 		if err != nil {
-			done <- fmt.Errorf("new request: %w", err)
-			return
+			return "", err
 		}
-		req.ContentLength = -1
-		//TODO: calculate content length like in https://gist.github.com/cryptix/9dd094008b6236f4fc57
-		req.Header.Set("Content-Type", mw.FormDataContentType())
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
-		resp, err = c.HTTPClient.Do(req)
-		if err != nil {
-			done <- fmt.Errorf("request failed: %w", err)
-			return
-		}
-		done <- nil
-	}()
-	allok := false
-	defer func() {
-		if !allok {
-			_ = mw.Close()
-			_ = pw.Close()
+		if !ok {
+			// a reader of unknown size is buffered, as the streaming upload
+			// this replaced took any reader
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return "", fmt.Errorf("read file: %w", err)
+			}
+			r, n = bytes.NewReader(b), int64(len(b))
 		}
-	}()
+		fsize = n
+	}
+	// a file under 512 bytes must not be read past its declared size, or the
+	// body outgrows Content-Length
+	head := make([]byte, min(512, fsize))
+	nhead, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read file: %w", err)
+	}
+	head = head[:nhead]
+	if mimeType, err = ValidateMedia(mimeType, head, fsize); err != nil {
+		return "", err
+	}
 
+	// the multipart envelope is built up front so the request has an exact
+	// Content-Length
+	envelope := new(bytes.Buffer)
+	mw := multipart.NewWriter(envelope)
 	fh := make(textproto.MIMEHeader)
 	fh.Set("Content-Type", mimeType)
 	fh.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes("file"), escapeQuotes(filename)))
-	fpart, err := mw.CreatePart(fh)
-	if err != nil {
+	if _, err := mw.CreatePart(fh); err != nil {
 		return "", fmt.Errorf("create form file: %w", err)
 	}
-	if _, err := io.Copy(fpart, r); err != nil {
-		return "", fmt.Errorf("copy file: %w", err)
-	}
+	prefix := bytes.Clone(envelope.Bytes())
+	envelope.Reset()
 	if err := mw.WriteField("messaging_product", "whatsapp"); err != nil {
 		return "", fmt.Errorf("write field: %w", err)
 	}
-	allok = true
 	if err := mw.Close(); err != nil {
 		return "", fmt.Errorf("close multipart writer: %w", err)
 	}
-	if err := pw.Close(); err != nil {
-		return "", fmt.Errorf("close pipe writer: %w", err)
+	// WriteField opened with the boundary that ends the file part
+	suffix := envelope.Bytes()
+
+	body := io.MultiReader(bytes.NewReader(prefix), bytes.NewReader(head), io.LimitReader(r, fsize-int64(nhead)), bytes.NewReader(suffix))
+	url := fmt.Sprintf("%s/%s/%s/media", c.baseURL(), c.graphVersion(), phoneID)
+	req, err := NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return "", fmt.Errorf("new request: %w", err)
 	}
-	if err := <-done; err != nil {
-		return "", fmt.Errorf("request: %w", err)
+	req.ContentLength = int64(len(prefix)) + fsize + int64(len(suffix))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
+	resp, err := c.do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 	if resp.StatusCode != http.StatusOK {
@@ -221,20 +281,49 @@ func (c *Client) UploadMedia(phoneID string, mimeType string, r io.Reader, fsize
 	return idstruct.ID, nil
 }
 
-func (c *Client) GetMedia(mediaID string) (*GetMediaResult, error) {
-	apiVersion := DefaultGraphAPIVersion
-	if c.GraphAPIVersion != "" {
-		apiVersion = c.GraphAPIVersion
+// readerSize returns the size of what is left to read from r when it can
+// tell it, as *bytes.Reader, *strings.Reader and a regular *os.File can. A
+// file counts from its current offset; pipes and other special files have no
+// size.
+func readerSize(r io.Reader) (int64, bool, error) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true, nil
+	case interface{ Stat() (os.FileInfo, error) }:
+		fi, err := v.Stat()
+		if err != nil {
+			return 0, false, fmt.Errorf("stat file: %w", err)
+		}
+		if !fi.Mode().IsRegular() {
+			return 0, false, nil
+		}
+		var offset int64
+		if sk, ok := r.(io.Seeker); ok {
+			if offset, err = sk.Seek(0, io.SeekCurrent); err != nil {
+				return 0, false, fmt.Errorf("seek file: %w", err)
+			}
+		}
+		return max(fi.Size()-offset, 0), true, nil
 	}
+	return 0, false, nil
+}
+
+func (c *Client) GetMedia(mediaID string) (*GetMediaResult, error) {
+	return c.GetMediaWithContext(context.Background(), mediaID)
+}
+
+// GetMediaWithContext is GetMedia bound to ctx.
+func (c *Client) GetMediaWithContext(ctx context.Context, mediaID string) (*GetMediaResult, error) {
+	c.resetLastError()
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s", apiVersion, mediaID)
-	req, err := NewRequest(http.MethodGet, url, nil)
+	url := fmt.Sprintf("%s/%s/%s", c.baseURL(), c.graphVersion(), mediaID)
+	req, err := NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("new request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -250,13 +339,20 @@ func (c *Client) GetMedia(mediaID string) (*GetMediaResult, error) {
 }
 
 func (c *Client) DownloadMedia(mr *GetMediaResult, out io.Writer) (nwritten int64, err error) {
-	req, err := NewRequest(http.MethodGet, mr.URL, nil)
+	return c.DownloadMediaWithContext(context.Background(), mr, out)
+}
+
+// DownloadMediaWithContext is DownloadMedia bound to ctx.
+func (c *Client) DownloadMediaWithContext(ctx context.Context, mr *GetMediaResult, out io.Writer) (nwritten int64, err error) {
+	c.resetLastError()
+
+	req, err := NewRequestWithContext(ctx, http.MethodGet, c.mediaURL(mr.URL), nil)
 	if err != nil {
 		return 0, fmt.Errorf("new request: %w", err)
 	}
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 	req.Header.Set("Accept", mr.MimeType)
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return 0, fmt.Errorf("request failed: %w", err)
 	}
@@ -315,44 +411,35 @@ type NewUploadSessionParams struct {
 }
 
 func (c *Client) NewUploadSession(fbAppID string, params NewUploadSessionParams) (id string, err error) {
-	c.lastGraphError = nil
-	c.lastErrorRawBody = ""
+	return c.NewUploadSessionWithContext(context.Background(), fbAppID, params)
+}
+
+// NewUploadSessionWithContext is NewUploadSession bound to ctx.
+func (c *Client) NewUploadSessionWithContext(ctx context.Context, fbAppID string, params NewUploadSessionParams) (id string, err error) {
+	c.resetLastError()
 
 	if params.SessionType == "" {
 		params.SessionType = "attachment"
 	}
 
-	apiVersion := DefaultGraphAPIVersion
-	if c.GraphAPIVersion != "" {
-		apiVersion = c.GraphAPIVersion
-	}
-
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/uploads", apiVersion, fbAppID)
+	url := fmt.Sprintf("%s/%s/%s/uploads", c.baseURL(), c.graphVersion(), fbAppID)
 	buf := new(bytes.Buffer)
 	if err := json.NewEncoder(buf).Encode(params); err != nil {
 		return "", fmt.Errorf("encode message: %w", err)
 	}
-	req, err := NewRequest(http.MethodPost, url, buf)
+	req, err := NewRequestWithContext(ctx, http.MethodPost, url, buf)
 	if err != nil {
 		return "", fmt.Errorf("new request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 	if resp.StatusCode != http.StatusOK {
-		gerr := c.errorFromResponse(resp)
-
-		if ge, ok := AsGraphError(gerr); ok {
-			if ge.Code == 4 {
-				return "", ErrApplicationRateLimitReached
-			}
-		}
-
-		return "", gerr
+		return "", rateLimitError(c.errorFromResponse(resp))
 	}
 	idstruct := struct {
 		ID string `json:"id"`
@@ -363,17 +450,19 @@ func (c *Client) NewUploadSession(fbAppID string, params NewUploadSessionParams)
 	return idstruct.ID, nil
 }
 
+// UploadHeaderHandle uploads r into an upload session in a single request.
+// A dropped connection loses the whole upload; large files should use
+// UploadResumable or ResumeUpload instead.
 func (c *Client) UploadHeaderHandle(uploadSessionID string, r io.Reader) (h string, err error) {
-	c.lastGraphError = nil
-	c.lastErrorRawBody = ""
+	return c.UploadHeaderHandleWithContext(context.Background(), uploadSessionID, r)
+}
 
-	apiVersion := DefaultGraphAPIVersion
-	if c.GraphAPIVersion != "" {
-		apiVersion = c.GraphAPIVersion
-	}
+// UploadHeaderHandleWithContext is UploadHeaderHandle bound to ctx.
+func (c *Client) UploadHeaderHandleWithContext(ctx context.Context, uploadSessionID string, r io.Reader) (h string, err error) {
+	c.resetLastError()
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s", apiVersion, uploadSessionID)
-	req, err := NewRequest(http.MethodPost, url, r)
+	url := fmt.Sprintf("%s/%s/%s", c.baseURL(), c.graphVersion(), uploadSessionID)
+	req, err := NewRequestWithContext(ctx, http.MethodPost, url, r)
 	if err != nil {
 		return "", fmt.Errorf("new request: %w", err)
 	}
@@ -381,21 +470,13 @@ func (c *Client) UploadHeaderHandle(uploadSessionID string, r io.Reader) (h stri
 	req.Header.Set("Authorization", fmt.Sprintf("OAuth %s", c.AccessToken))
 	req.Header.Set("Content-Range", "bytes 0-0/*")
 	req.Header.Set("file_offset", "0")
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 	if resp.StatusCode != http.StatusOK {
-		gerr := c.errorFromResponse(resp)
-
-		if ge, ok := AsGraphError(gerr); ok {
-			if ge.Code == 4 {
-				return "", ErrApplicationRateLimitReached
-			}
-		}
-
-		return "", gerr
+		return "", rateLimitError(c.errorFromResponse(resp))
 	}
 	hstruct := struct {
 		H string `json:"h"`
@@ -407,6 +488,9 @@ func (c *Client) UploadHeaderHandle(uploadSessionID string, r io.Reader) (h stri
 	return hstruct.H, nil
 }
 
+// errorFromResponse reads a non-2xx response into an error. When the body
+// carries a Graph error it returns a *GraphError with RawBody set; the error is
+// self-contained, so concurrent callers never see each other's failures.
 func (c *Client) errorFromResponse(resp *http.Response) error {
 	if DebugTrace {
 		fmt.Printf("HTTP STATUS %d\nHEADERS:\n", resp.StatusCode)
@@ -420,20 +504,32 @@ func (c *Client) errorFromResponse(resp *http.Response) error {
 	}{}
 	jbdbuff := new(bytes.Buffer)
 	_, _ = io.Copy(jbdbuff, resp.Body)
-
-	c.lastErrorRawBody = jbdbuff.String()
+	rawBody := jbdbuff.String()
 
 	if DebugTrace {
-		fmt.Printf("BODY:\n%s\n", jbdbuff.String())
+		fmt.Printf("BODY:\n%s\n", rawBody)
 	}
 
 	if err := json.Unmarshal(jbdbuff.Bytes(), &eparent); err != nil {
-		return fmt.Errorf("http status: %d (%s); %w - %s", resp.StatusCode, resp.Status, err, jbdbuff.String())
+		c.setLastError(nil, rawBody)
+		return fmt.Errorf("http status: %d (%s); %w - %s", resp.StatusCode, resp.Status, err, rawBody)
 	}
 	if eparent.Error.Code == 0 {
-		return fmt.Errorf("http status: %d (%s); %s", resp.StatusCode, resp.Status, jbdbuff.String())
+		c.setLastError(nil, rawBody)
+		return fmt.Errorf("http status: %d (%s); %s", resp.StatusCode, resp.Status, rawBody)
+	}
+	gerr := &eparent.Error
+	gerr.HTTPStatusCode = resp.StatusCode
+	gerr.RawBody = rawBody
+	c.setLastError(gerr, rawBody)
+	return gerr
+}
+
+// rateLimitError marks a code 4 Graph error with ErrApplicationRateLimitReached
+// while keeping the *GraphError reachable through AsGraphError.
+func rateLimitError(err error) error {
+	if ge, ok := AsGraphError(err); ok && ge.Code == 4 {
+		return fmt.Errorf("%w: %w", ErrApplicationRateLimitReached, ge)
 	}
-	eparent.Error.HTTPStatusCode = resp.StatusCode
-	c.lastGraphError = &eparent.Error
-	return c.lastGraphError
+	return err
 }
@@ -0,0 +1,88 @@
+package fbgraph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSendMessageConcurrentErrorsArePerCall(t *testing.T) {
+	// Every recipient gets its own error code back, so a response leaking into
+	// another goroutine's error is detectable.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			To string `json:"to"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, `{"error":{"message":"fail %s","code":%s,"fbtrace_id":"t%s"}}`, body.To, body.To, body.To)
+	}))
+	defer srv.Close()
+
+	c := NewClient("tok")
+	c.HTTPClient = &http.Client{Transport: rewriteHost{srv.URL, http.DefaultTransport}}
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 32; i++ {
+		wg.Add(1)
+		go func(code int) {
+			defer wg.Done()
+			to := fmt.Sprint(code)
+			_, err := c.SendMessageWithContext(context.Background(), "123", &MessageObject{
+				MessagingProduct: "whatsapp",
+				To:               to,
+				Type:             "text",
+				Text:             &TextObject{Body: "hi"},
+			})
+			ge, ok := AsGraphError(err)
+			if !ok {
+				t.Errorf("%s: expected a *GraphError, got %v", to, err)
+				return
+			}
+			if ge.Code != code {
+				t.Errorf("%s: code = %d, want %d", to, ge.Code, code)
+			}
+			if !strings.Contains(ge.RawBody, `"fail `+to+`"`) {
+				t.Errorf("%s: raw body = %q", to, ge.RawBody)
+			}
+			if ge.HTTPStatusCode != http.StatusBadRequest {
+				t.Errorf("%s: status = %d", to, ge.HTTPStatusCode)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestRateLimitErrorKeepsGraphError(t *testing.T) {
+	cl, _ := newTestClient(t, http.StatusBadRequest, `{"error":{"message":"slow down","code":4,"fbtrace_id":"abc"}}`)
+
+	_, err := cl.NewUploadSessionWithContext(context.Background(), "app", NewUploadSessionParams{FileLength: 1})
+	if !errors.Is(err, ErrApplicationRateLimitReached) {
+		t.Fatalf("err = %v, want ErrApplicationRateLimitReached", err)
+	}
+	ge, ok := AsGraphError(err)
+	if !ok {
+		t.Fatal("rate limit error must still unwrap to *GraphError")
+	}
+	if ge.FBTraceID != "abc" || ge.RawBody == "" {
+		t.Errorf("graph error = %+v", ge)
+	}
+}
+
+func TestSendMessageHonoursContext(t *testing.T) {
+	cl, _ := newTestClient(t, http.StatusOK, `{}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := cl.SendMessageWithContext(ctx, "123", &MessageObject{To: "1", Type: "text"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
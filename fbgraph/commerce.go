@@ -0,0 +1,284 @@
+package fbgraph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CommerceSettings are the commerce settings of a phone number.
+type CommerceSettings struct {
+	ID string `json:"id,omitempty"`
+	// IsCartEnabled lets customers add products to a cart and send it as an
+	// order message.
+	IsCartEnabled bool `json:"is_cart_enabled"`
+	// IsCatalogVisible shows the catalog button in the chat and the business
+	// profile.
+	IsCatalogVisible bool `json:"is_catalog_visible"`
+}
+
+// UpdateCommerceSettingsParams are the settings to change; nil fields are left
+// as they are.
+type UpdateCommerceSettingsParams struct {
+	IsCartEnabled    *bool
+	IsCatalogVisible *bool
+}
+
+// GetCommerceSettings reads the commerce settings of a phone number.
+//
+// GET /{PHONE_NUMBER_ID}/whatsapp_commerce_settings
+func (c *Client) GetCommerceSettings(ctx context.Context, phoneID string) (*CommerceSettings, error) {
+	c.resetLastError()
+	u := fmt.Sprintf("%s/%s/%s/whatsapp_commerce_settings", c.baseURL(), c.graphVersion(), url.PathEscape(phoneID))
+	req, err := NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.errorFromResponse(resp)
+	}
+	result := struct {
+		Data []CommerceSettings `json:"data"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(result.Data) == 0 {
+		return &CommerceSettings{}, nil
+	}
+	return &result.Data[0], nil
+}
+
+// UpdateCommerceSettings changes the commerce settings of a phone number.
+//
+// POST /{PHONE_NUMBER_ID}/whatsapp_commerce_settings
+func (c *Client) UpdateCommerceSettings(ctx context.Context, phoneID string, params UpdateCommerceSettingsParams) error {
+	c.resetLastError()
+	q := make(url.Values)
+	if params.IsCartEnabled != nil {
+		q.Set("is_cart_enabled", strconv.FormatBool(*params.IsCartEnabled))
+	}
+	if params.IsCatalogVisible != nil {
+		q.Set("is_catalog_visible", strconv.FormatBool(*params.IsCatalogVisible))
+	}
+	if len(q) == 0 {
+		return nil
+	}
+	u := fmt.Sprintf("%s/%s/%s/whatsapp_commerce_settings?%s", c.baseURL(), c.graphVersion(), url.PathEscape(phoneID), q.Encode())
+	req, err := NewRequestWithContext(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return c.errorFromResponse(resp)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// CatalogProduct is a product of a catalog linked to the WABA.
+type CatalogProduct struct {
+	ID         string `json:"id"`
+	RetailerID string `json:"retailer_id"`
+	Name       string `json:"name"`
+	// Description is the product description. Optional.
+	Description string `json:"description,omitempty"`
+	// Availability is e.g. "in stock" or "out of stock".
+	Availability string `json:"availability,omitempty"`
+	// Price is formatted by Meta, e.g. "R$12,90".
+	Price    string `json:"price,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// GetCatalogProductsParams select a page of catalog products.
+type GetCatalogProductsParams struct {
+	// RetailerIDs, when set, only returns the products with these retailer IDs.
+	RetailerIDs []string
+	// Limit is the page size. Meta defaults to 25.
+	Limit int
+	// After is Paging.Cursors.After of the previous page.
+	After string
+}
+
+// CatalogProductsPage is a page of GetCatalogProducts.
+type CatalogProductsPage struct {
+	Data   []CatalogProduct `json:"data"`
+	Paging struct {
+		Cursors struct {
+			Before string `json:"before"`
+			After  string `json:"after"`
+		} `json:"cursors"`
+		Next string `json:"next"`
+	} `json:"paging"`
+}
+
+const catalogProductFields = "id,retailer_id,name,description,availability,price,currency,image_url,url"
+
+// GetCatalogProducts reads a page of the products of a catalog.
+//
+// GET /{CATALOG_ID}/products
+func (c *Client) GetCatalogProducts(ctx context.Context, catalogID string, params GetCatalogProductsParams) (*CatalogProductsPage, error) {
+	c.resetLastError()
+	q := url.Values{"fields": {catalogProductFields}}
+	if len(params.RetailerIDs) > 0 {
+		filter, err := json.Marshal(map[string]any{"retailer_id": map[string]any{"is_any": params.RetailerIDs}})
+		if err != nil {
+			return nil, fmt.Errorf("marshal filter: %w", err)
+		}
+		q.Set("filter", string(filter))
+	}
+	if params.Limit > 0 {
+		q.Set("limit", strconv.Itoa(params.Limit))
+	}
+	if params.After != "" {
+		q.Set("after", params.After)
+	}
+	u := fmt.Sprintf("%s/%s/%s/products?%s", c.baseURL(), c.graphVersion(), url.PathEscape(catalogID), q.Encode())
+	req, err := NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.errorFromResponse(resp)
+	}
+	page := &CatalogProductsPage{}
+	if err := json.NewDecoder(resp.Body).Decode(page); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return page, nil
+}
+
+// CatalogProducts iterates over the products of a catalog matching params,
+// following Paging.Cursors.After from params.After on. Iteration stops after
+// the first error, which is yielded with a zero CatalogProduct.
+func (c *Client) CatalogProducts(ctx context.Context, catalogID string, params GetCatalogProductsParams) iter.Seq2[CatalogProduct, error] {
+	return func(yield func(CatalogProduct, error) bool) {
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(CatalogProduct{}, err)
+				return
+			}
+			page, err := c.GetCatalogProducts(ctx, catalogID, params)
+			if err != nil {
+				yield(CatalogProduct{}, err)
+				return
+			}
+			for _, p := range page.Data {
+				if !yield(p, nil) {
+					return
+				}
+			}
+			if page.Paging.Next == "" || page.Paging.Cursors.After == "" || len(page.Data) == 0 {
+				return
+			}
+			if page.Paging.Cursors.After == params.After {
+				yield(CatalogProduct{}, fmt.Errorf("catalog products: paging cursor %q did not advance", params.After))
+				return
+			}
+			params.After = page.Paging.Cursors.After
+		}
+	}
+}
+
+// MissingProductsError is returned by VerifyCatalogProducts with the retailer
+// IDs that are not in the catalog.
+type MissingProductsError struct {
+	CatalogID   string
+	RetailerIDs []string
+}
+
+func (e *MissingProductsError) Error() string {
+	return fmt.Sprintf("catalog %s has no products with retailer id %s", e.CatalogID, strings.Join(e.RetailerIDs, ", "))
+}
+
+// VerifyCatalogProducts checks that every retailer ID is a product of the
+// catalog, returning a *MissingProductsError with the ones that are not. An
+// empty catalog or retailer ID is an error, without calling the API.
+func (c *Client) VerifyCatalogProducts(ctx context.Context, catalogID string, retailerIDs ...string) error {
+	if catalogID == "" {
+		return errors.New("verify catalog products: catalog id is required")
+	}
+	if slices.Contains(retailerIDs, "") {
+		return errors.New("verify catalog products: empty product retailer id")
+	}
+	want := make([]string, 0, len(retailerIDs))
+	for _, id := range retailerIDs {
+		if !slices.Contains(want, id) {
+			want = append(want, id)
+		}
+	}
+	found := make(map[string]bool, len(want))
+	// A product list holds at most MaxProductListItems items, so this is
+	// usually one request.
+	for chunk := range slices.Chunk(want, 100) {
+		params := GetCatalogProductsParams{RetailerIDs: chunk, Limit: len(chunk)}
+		for p, err := range c.CatalogProducts(ctx, catalogID, params) {
+			if err != nil {
+				return fmt.Errorf("get catalog products: %w", err)
+			}
+			found[p.RetailerID] = true
+		}
+	}
+	missing := &MissingProductsError{CatalogID: catalogID}
+	for _, id := range want {
+		if !found[id] {
+			missing.RetailerIDs = append(missing.RetailerIDs, id)
+		}
+	}
+	if len(missing.RetailerIDs) > 0 {
+		return missing
+	}
+	return nil
+}
+
+// VerifyProductMessage checks that the products of a product or product_list
+// message exist in its catalog. Other messages are not checked. A message
+// without a catalog ID or with an empty product retailer ID is an error.
+func (c *Client) VerifyProductMessage(ctx context.Context, m *MessageObject) error {
+	if m == nil || m.Interactive == nil || m.Interactive.Action == nil {
+		return nil
+	}
+	a := m.Interactive.Action
+	var ids []string
+	switch m.Interactive.Type {
+	case InteractiveMessageProduct:
+		ids = append(ids, a.ProductRetailerID)
+	case InteractiveMessageProductList:
+		for _, s := range a.Sections {
+			for _, it := range s.ProductItems {
+				ids = append(ids, it.ProductRetailerID)
+			}
+		}
+	default:
+		return nil
+	}
+	return c.VerifyCatalogProducts(ctx, a.CatalogID, ids...)
+}
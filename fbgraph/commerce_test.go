@@ -0,0 +1,119 @@
+package fbgraph_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+	"github.com/pedidopago/wabaman-contrib/fbgraph/fbgraphtest"
+)
+
+func TestCommerceSettings(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	srv.AddPhoneNumber("waba1", fbgraph.WABAPhoneNumber{ID: "phone1"})
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	on := true
+	if err := c.UpdateCommerceSettings(ctx, "phone1", fbgraph.UpdateCommerceSettingsParams{IsCatalogVisible: &on}); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := c.GetCommerceSettings(ctx, "phone1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cs.IsCatalogVisible || cs.IsCartEnabled {
+		t.Errorf("settings = %+v", cs)
+	}
+
+	if err := c.UpdateCommerceSettings(ctx, "phone1", fbgraph.UpdateCommerceSettingsParams{IsCartEnabled: &on}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := srv.CommerceSettings("phone1"); !got.IsCartEnabled || !got.IsCatalogVisible {
+		t.Errorf("settings = %+v", got)
+	}
+}
+
+func TestCatalogProducts(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	for i := range 60 {
+		srv.AddCatalogProduct("cat1", fbgraph.CatalogProduct{RetailerID: fmt.Sprintf("sku-%02d", i), Name: "Produto"})
+	}
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	var ids []string
+	for p, err := range c.CatalogProducts(ctx, "cat1", fbgraph.GetCatalogProductsParams{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, p.RetailerID)
+	}
+	if len(ids) != 60 || ids[59] != "sku-59" {
+		t.Errorf("read %d products, last %q", len(ids), ids[len(ids)-1])
+	}
+	if n := srv.CountRequests("GET", "/cat1/products"); n != 3 {
+		t.Errorf("%d requests, want 3 pages", n)
+	}
+
+	if err := c.VerifyCatalogProducts(ctx, "cat1", "sku-01", "sku-42"); err != nil {
+		t.Errorf("VerifyCatalogProducts: %v", err)
+	}
+
+	msg := fbgraph.NewProductList("5511999999999", "Ofertas", "cat1",
+		fbgraph.ProductSection("Analgésicos", "sku-01", "sku-99"),
+		fbgraph.ProductSection("Vitaminas", "sku-02", "vit-c"),
+	).WithHeaderText("Ofertas da semana")
+	if err := msg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	var missing *fbgraph.MissingProductsError
+	if err := c.VerifyProductMessage(ctx, msg); !errors.As(err, &missing) {
+		t.Fatalf("VerifyProductMessage = %v", err)
+	}
+	if !slices.Equal(missing.RetailerIDs, []string{"sku-99", "vit-c"}) {
+		t.Errorf("missing = %v", missing.RetailerIDs)
+	}
+
+	if err := c.VerifyProductMessage(ctx, fbgraph.NewProduct("5511999999999", "", "cat1", "sku-07")); err != nil {
+		t.Errorf("single product: %v", err)
+	}
+
+	before := srv.CountRequests("GET", "/cat1/products")
+	if err := c.VerifyProductMessage(ctx, fbgraph.NewProduct("5511999999999", "", "", "sku-07")); err == nil {
+		t.Error("product without a catalog id verified")
+	}
+	if err := c.VerifyProductMessage(ctx, fbgraph.NewProduct("5511999999999", "", "cat1", "")); err == nil {
+		t.Error("product without a retailer id verified")
+	}
+	if n := srv.CountRequests("GET", "/cat1/products"); n != before {
+		t.Errorf("%d requests for invalid products", n-before)
+	}
+}
+
+func TestCatalogProductsStuckCursor(t *testing.T) {
+	pages := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		_, _ = fmt.Fprint(w, `{"data":[{"retailer_id":"sku-01"}],"paging":{"cursors":{"after":"p1"},"next":"https://graph.facebook.com/next"}}`)
+	}))
+	defer srv.Close()
+	c := fbgraph.NewClient("tok")
+	c.BaseURL = srv.URL
+
+	err := c.VerifyCatalogProducts(context.Background(), "cat1", "sku-01", "sku-02")
+	if err == nil || !strings.Contains(err.Error(), "did not advance") {
+		t.Errorf("VerifyCatalogProducts = %v", err)
+	}
+	if pages != 2 {
+		t.Errorf("%d requests, want 2", pages)
+	}
+}
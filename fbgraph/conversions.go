@@ -63,10 +63,9 @@ func (c *Client) httpError(resp *http.Response) error {
 // CreateDataset provisions a Conversions API dataset on a WhatsApp Business
 // Account and returns its id. POST /{WABA_ID}/dataset.
 func (c *Client) CreateDataset(ctx context.Context, wabaID string) (datasetID string, err error) {
-	c.lastGraphError = nil
-	c.lastErrorRawBody = ""
+	c.resetLastError()
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/dataset", c.graphVersion(), wabaID)
+	url := fmt.Sprintf("%s/%s/%s/dataset", c.baseURL(), c.graphVersion(), wabaID)
 
 	req, err := NewRequest(http.MethodPost, url, nil)
 	if err != nil {
@@ -75,7 +74,7 @@ func (c *Client) CreateDataset(ctx context.Context, wabaID string) (datasetID st
 	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
 	}
@@ -99,10 +98,9 @@ func (c *Client) CreateDataset(ctx context.Context, wabaID string) (datasetID st
 // an empty string when none exists. GET /{WABA_ID}/dataset. Used to reuse a
 // dataset the client provisioned themselves before creating a new one.
 func (c *Client) GetDatasetID(ctx context.Context, wabaID string) (datasetID string, err error) {
-	c.lastGraphError = nil
-	c.lastErrorRawBody = ""
+	c.resetLastError()
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/dataset", c.graphVersion(), wabaID)
+	url := fmt.Sprintf("%s/%s/%s/dataset", c.baseURL(), c.graphVersion(), wabaID)
 
 	req, err := NewRequest(http.MethodGet, url, nil)
 	if err != nil {
@@ -111,7 +109,7 @@ func (c *Client) GetDatasetID(ctx context.Context, wabaID string) (datasetID str
 	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
 	}
@@ -142,10 +140,9 @@ func (c *Client) GetDatasetID(ctx context.Context, wabaID string) (datasetID str
 // batch if any event_time is older than 7 days, so callers should send small
 // batches and guard the window before calling.
 func (c *Client) SendConversionEvents(ctx context.Context, datasetID string, events []ConversionEvent) (received int, err error) {
-	c.lastGraphError = nil
-	c.lastErrorRawBody = ""
+	c.resetLastError()
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/events", c.graphVersion(), datasetID)
+	url := fmt.Sprintf("%s/%s/%s/events", c.baseURL(), c.graphVersion(), datasetID)
 
 	body := struct {
 		Data []ConversionEvent `json:"data"`
@@ -164,7 +161,7 @@ func (c *Client) SendConversionEvents(ctx context.Context, datasetID string, eve
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return 0, fmt.Errorf("request failed: %w", err)
 	}
@@ -0,0 +1,258 @@
+package fbgraph
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrorCategory says what it takes to get past a Cloud API error. Categories
+// are errors themselves, so a whole class can be tested at once:
+//
+//	if errors.Is(err, fbgraph.CategoryRetryable) { ... }
+type ErrorCategory int
+
+const (
+	// CategoryUnknown is the category of codes missing from the catalog.
+	CategoryUnknown ErrorCategory = iota
+	// CategoryRetryable errors go away by themselves; try again later.
+	CategoryRetryable
+	// CategoryPermanent errors will fail the same way until the request is
+	// changed.
+	CategoryPermanent
+	// CategoryNeedsUserAction errors depend on the WhatsApp user, e.g. them
+	// replying to reopen the customer service window.
+	CategoryNeedsUserAction
+	// CategoryNeedsOps errors need someone on the business side: a new token,
+	// a payment method, a policy appeal, a re-registration.
+	CategoryNeedsOps
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case CategoryRetryable:
+		return "retryable"
+	case CategoryPermanent:
+		return "permanent"
+	case CategoryNeedsUserAction:
+		return "needs-user-action"
+	case CategoryNeedsOps:
+		return "needs-ops"
+	}
+	return "unknown"
+}
+
+func (c ErrorCategory) Error() string {
+	return "error category: " + c.String()
+}
+
+// CodeError is an entry of the Cloud API error catalog. The Err* values of this
+// type match, through errors.Is, any *GraphError (or whapi.ErrorObject) with
+// the same code and, when Subcode is set, the same subcode:
+//
+//	if errors.Is(err, fbgraph.ErrReEngagementRequired) { ... }
+//
+// See https://developers.facebook.com/docs/whatsapp/cloud-api/support/error-codes
+type CodeError struct {
+	Code int
+	// LastCode, when set, makes the entry cover the range Code..LastCode.
+	LastCode int
+	// Subcode, when set, narrows the entry to one error_subcode.
+	Subcode  int
+	Title    string
+	Category ErrorCategory
+}
+
+func (e *CodeError) Error() string {
+	s := e.Title + " (" + strconv.Itoa(e.Code)
+	if e.Subcode != 0 {
+		s += "/" + strconv.Itoa(e.Subcode)
+	}
+	return s + ")"
+}
+
+func (e *CodeError) matches(code, subcode int) bool {
+	if e.LastCode != 0 {
+		if code < e.Code || code > e.LastCode {
+			return false
+		}
+	} else if code != e.Code {
+		return false
+	}
+	return e.Subcode == 0 || e.Subcode == subcode
+}
+
+var errorCatalog []*CodeError
+
+func catalogued(code, subcode int, title string, cat ErrorCategory) *CodeError {
+	e := &CodeError{Code: code, Subcode: subcode, Title: title, Category: cat}
+	errorCatalog = append(errorCatalog, e)
+	return e
+}
+
+// Authorization errors.
+var (
+	ErrAuthException      = catalogued(0, 0, "auth exception", CategoryNeedsOps)
+	ErrAPIMethod          = catalogued(3, 0, "API method", CategoryNeedsOps)
+	ErrPermissionDenied   = catalogued(10, 0, "permission denied", CategoryNeedsOps)
+	ErrAccessTokenExpired = catalogued(190, 0, "access token has expired", CategoryNeedsOps)
+	ErrAPIPermission      = func() *CodeError {
+		e := catalogued(200, 0, "API permission", CategoryNeedsOps)
+		e.LastCode = 299
+		return e
+	}()
+	ErrPhoneNotAllowed = catalogued(200, 2494049, "phone number not allowed on Cloud API", CategoryNeedsOps)
+)
+
+// Throttling errors.
+var (
+	ErrTooManyAPICalls   = catalogued(4, 0, "too many API calls", CategoryRetryable)
+	ErrWABARateLimit     = catalogued(80007, 0, "rate limit issues", CategoryRetryable)
+	ErrThroughputReached = catalogued(130429, 0, "rate limit hit", CategoryRetryable)
+	ErrSpamRateLimit     = catalogued(131048, 0, "spam rate limit hit", CategoryNeedsOps)
+	ErrPairRateLimit     = catalogued(131056, 0, "(business account, consumer account) pair rate limit hit", CategoryRetryable)
+	ErrRegisterRateLimit = catalogued(133016, 0, "account register or deregister rate limit exceeded", CategoryRetryable)
+)
+
+// Integrity errors.
+var (
+	ErrPolicyBlock       = catalogued(368, 0, "temporarily blocked for policy violations", CategoryNeedsOps)
+	ErrCountryRestricted = catalogued(130497, 0, "business account is restricted from messaging users in this country", CategoryPermanent)
+	ErrAccountLocked     = catalogued(131031, 0, "account has been locked", CategoryNeedsOps)
+)
+
+// General and send errors.
+var (
+	ErrUnknownAPI             = catalogued(1, 0, "API unknown", CategoryRetryable)
+	ErrAPIService             = catalogued(2, 0, "API service", CategoryRetryable)
+	ErrParameterValue         = catalogued(33, 0, "parameter value is not valid", CategoryPermanent)
+	ErrInvalidParameter       = catalogued(100, 0, "invalid parameter", CategoryPermanent)
+	ErrDuplicatePost          = catalogued(506, 0, "duplicate post", CategoryPermanent)
+	ErrExperimentNumber       = catalogued(130472, 0, "user's number is part of an experiment", CategoryPermanent)
+	ErrSomethingWentWrong     = catalogued(131000, 0, "something went wrong", CategoryRetryable)
+	ErrMessageTooLong         = catalogued(131001, 0, "message too long", CategoryPermanent)
+	ErrInvalidRecipientType   = catalogued(131002, 0, "invalid recipient type", CategoryPermanent)
+	ErrAccessDenied           = catalogued(131005, 0, "access denied", CategoryNeedsOps)
+	ErrResourceNotFound       = catalogued(131006, 0, "resource not found", CategoryPermanent)
+	ErrRequiredParamMissing   = catalogued(131008, 0, "required parameter is missing", CategoryPermanent)
+	ErrParamValueInvalid      = catalogued(131009, 0, "parameter value is not valid", CategoryPermanent)
+	ErrServiceUnavailable     = catalogued(131016, 0, "service unavailable", CategoryRetryable)
+	ErrRecipientIsSender      = catalogued(131021, 0, "recipient cannot be sender", CategoryPermanent)
+	ErrUndeliverable          = catalogued(131026, 0, "message undeliverable", CategoryNeedsUserAction)
+	ErrDisplayNameApproval    = catalogued(131037, 0, "display name approval needed", CategoryNeedsOps)
+	ErrPaymentIssue           = catalogued(131042, 0, "business eligibility payment issue", CategoryNeedsOps)
+	ErrMessageExpired         = catalogued(131043, 0, "message expired", CategoryPermanent)
+	ErrIncorrectCertificate   = catalogued(131045, 0, "incorrect certificate", CategoryNeedsOps)
+	ErrReEngagementRequired   = catalogued(131047, 0, "re-engagement message", CategoryNeedsUserAction)
+	ErrEcosystemEngagement    = catalogued(131049, 0, "message not delivered to maintain healthy ecosystem engagement", CategoryPermanent)
+	ErrMarketingOptOut        = catalogued(131050, 0, "user has stopped marketing messages", CategoryNeedsUserAction)
+	ErrUnsupportedMessageType = catalogued(131051, 0, "unsupported message type", CategoryPermanent)
+	ErrMediaDownload          = catalogued(131052, 0, "media download error", CategoryNeedsUserAction)
+	ErrMediaUpload            = catalogued(131053, 0, "media upload error", CategoryPermanent)
+	ErrMethodNotAllowed       = catalogued(131055, 0, "method not allowed", CategoryPermanent)
+	ErrMaintenanceMode        = catalogued(131057, 0, "account in maintenance mode", CategoryRetryable)
+	ErrGenericUserError       = catalogued(135000, 0, "generic user error", CategoryPermanent)
+)
+
+// Template and flow errors.
+var (
+	ErrTemplateParamCount    = catalogued(132000, 0, "template param count mismatch", CategoryPermanent)
+	ErrTemplateNotFound      = catalogued(132001, 0, "template does not exist", CategoryPermanent)
+	ErrTemplateTextTooLong   = catalogued(132005, 0, "template hydrated text too long", CategoryPermanent)
+	ErrTemplateFormatPolicy  = catalogued(132007, 0, "template format character policy violated", CategoryPermanent)
+	ErrTemplateParamFormat   = catalogued(132012, 0, "template parameter format mismatch", CategoryPermanent)
+	ErrTemplatePaused        = catalogued(132015, 0, "template is paused", CategoryNeedsOps)
+	ErrTemplateDisabled      = catalogued(132016, 0, "template is disabled", CategoryNeedsOps)
+	ErrFlowBlocked           = catalogued(132068, 0, "flow is in blocked state", CategoryNeedsOps)
+	ErrFlowThrottled         = catalogued(132069, 0, "flow is in throttled state", CategoryRetryable)
+	ErrDuplicateTemplateName = catalogued(100, 2388024, "a template with this name and language already exists", CategoryPermanent)
+)
+
+// Registration errors.
+var (
+	ErrIncompleteDeregistration     = catalogued(133000, 0, "incomplete deregistration", CategoryNeedsOps)
+	ErrDecryptionError              = catalogued(133001, 0, "decryption error", CategoryNeedsOps)
+	ErrBackupBlobDecryption         = catalogued(133002, 0, "backup blob decryption error", CategoryNeedsOps)
+	ErrRecoveryTokenDecryption      = catalogued(133003, 0, "recovery token decryption error", CategoryNeedsOps)
+	ErrServerTemporarilyUnavailable = catalogued(133004, 0, "server temporarily unavailable", CategoryRetryable)
+	ErrTwoStepPINMismatch           = catalogued(133005, 0, "two step verification PIN mismatch", CategoryNeedsOps)
+	ErrPhoneNeedsReverification     = catalogued(133006, 0, "phone number re-verification needed", CategoryNeedsOps)
+	ErrAccountBlocked               = catalogued(133007, 0, "account blocked by the registration server", CategoryNeedsOps)
+	ErrTooManyPINGuesses            = catalogued(133008, 0, "too many two step verification PIN guesses", CategoryNeedsOps)
+	ErrPINGuessedTooFast            = catalogued(133009, 0, "two step verification PIN guessed too fast", CategoryRetryable)
+	ErrPhoneNotRegistered           = catalogued(133010, 0, "phone number not registered", CategoryNeedsOps)
+	ErrRegisterTooSoon              = catalogued(133015, 0, "please wait a few minutes before attempting to register", CategoryRetryable)
+)
+
+// LookupErrorCode returns the catalog entry for a code and subcode, preferring
+// an entry for the exact subcode. It returns nil for unknown codes.
+func LookupErrorCode(code, subcode int) *CodeError {
+	var best *CodeError
+	for _, e := range errorCatalog {
+		if !e.matches(code, subcode) {
+			continue
+		}
+		switch {
+		case best == nil,
+			e.Subcode != 0 && best.Subcode == 0,
+			e.LastCode == 0 && best.LastCode != 0 && e.Subcode == best.Subcode:
+			best = e
+		}
+	}
+	return best
+}
+
+// GraphCoder is implemented by errors that carry a Cloud API code without
+// being a *GraphError, such as the errors of webhook payloads
+// (whapi.ErrorObject).
+type GraphCoder interface {
+	GraphCode() int
+}
+
+// ClassifyError returns the category of the Cloud API error in err's chain,
+// a *GraphError or a GraphCoder, or CategoryUnknown.
+func ClassifyError(err error) ErrorCategory {
+	var ge *GraphError
+	if errors.As(err, &ge) {
+		return ErrorCodeCategory(ge.Code, ge.ErrorSubcode)
+	}
+	var gc GraphCoder
+	if errors.As(err, &gc) {
+		return ErrorCodeCategory(gc.GraphCode(), 0)
+	}
+	var ce *CodeError
+	if errors.As(err, &ce) {
+		return ce.Category
+	}
+	return CategoryUnknown
+}
+
+// ErrorCodeCategory returns the category of a code and subcode.
+func ErrorCodeCategory(code, subcode int) ErrorCategory {
+	if e := LookupErrorCode(code, subcode); e != nil {
+		return e.Category
+	}
+	return CategoryUnknown
+}
+
+// MatchErrorCode reports whether an error with code and subcode matches target,
+// which may be a *CodeError, an ErrorCategory or ErrApplicationRateLimitReached.
+// It implements errors.Is for the error types carrying Cloud API codes. Code 0
+// never matches: it is also the code of a zero value, so ErrAuthException is
+// only found through LookupErrorCode.
+func MatchErrorCode(code, subcode int, target error) bool {
+	if code == 0 {
+		return false
+	}
+	switch t := target.(type) {
+	case *CodeError:
+		return t.matches(code, subcode)
+	case ErrorCategory:
+		return ErrorCodeCategory(code, subcode) == t
+	}
+	return target == ErrApplicationRateLimitReached && code == 4
+}
+
+// Is makes errors.Is match er against the error catalog. See MatchErrorCode.
+func (er *GraphError) Is(target error) bool {
+	return MatchErrorCode(er.Code, er.ErrorSubcode, target)
+}
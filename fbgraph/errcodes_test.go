@@ -0,0 +1,72 @@
+package fbgraph
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestGraphErrorIsCatalogued(t *testing.T) {
+	tests := []struct {
+		code, subcode int
+		is            error
+		category      ErrorCategory
+	}{
+		{190, 0, ErrAccessTokenExpired, CategoryNeedsOps},
+		{131047, 0, ErrReEngagementRequired, CategoryNeedsUserAction},
+		{131026, 0, ErrUndeliverable, CategoryNeedsUserAction},
+		{131056, 0, ErrPairRateLimit, CategoryRetryable},
+		{368, 0, ErrPolicyBlock, CategoryNeedsOps},
+		{4, 0, ErrApplicationRateLimitReached, CategoryRetryable},
+		{230, 0, ErrAPIPermission, CategoryNeedsOps},
+		{200, 2494049, ErrPhoneNotAllowed, CategoryNeedsOps},
+		{100, 2388024, ErrDuplicateTemplateName, CategoryPermanent},
+		{100, 2388024, ErrInvalidParameter, CategoryPermanent},
+		{133007, 0, ErrAccountBlocked, CategoryNeedsOps},
+		{999999, 0, nil, CategoryUnknown},
+	}
+	for _, tt := range tests {
+		err := fmt.Errorf("wrapped: %w", &GraphError{Code: tt.code, ErrorSubcode: tt.subcode})
+		if tt.is != nil && !errors.Is(err, tt.is) {
+			t.Errorf("%d/%d is not %v", tt.code, tt.subcode, tt.is)
+		}
+		if got := ClassifyError(err); got != tt.category {
+			t.Errorf("%d/%d category = %v, want %v", tt.code, tt.subcode, got, tt.category)
+		}
+		if tt.category != CategoryUnknown && !errors.Is(err, tt.category) {
+			t.Errorf("%d/%d does not match its category", tt.code, tt.subcode)
+		}
+	}
+
+	if errors.Is(&GraphError{Code: 131047}, ErrUndeliverable) {
+		t.Error("131047 must not match 131026")
+	}
+	if errors.Is(&GraphError{Code: 100}, ErrDuplicateTemplateName) {
+		t.Error("a subcode entry must not match other subcodes")
+	}
+	if errors.Is(&GraphError{}, ErrAuthException) || errors.Is(&GraphError{}, CategoryNeedsOps) {
+		t.Error("a zero GraphError must not match code 0")
+	}
+	if ClassifyError(fmt.Errorf("webhook: %w", graphCode(131056))) != CategoryRetryable {
+		t.Error("GraphCoder is not classified")
+	}
+}
+
+func TestErrorCatalogHasNoDuplicates(t *testing.T) {
+	seen := map[[2]int]bool{}
+	for _, e := range errorCatalog {
+		k := [2]int{e.Code, e.Subcode}
+		if seen[k] {
+			t.Errorf("duplicate catalog entry %v", e)
+		}
+		seen[k] = true
+		if e.Category == CategoryUnknown {
+			t.Errorf("%v has no category", e)
+		}
+	}
+}
+
+type graphCode int
+
+func (c graphCode) Error() string  { return "code " + fmt.Sprint(int(c)) }
+func (c graphCode) GraphCode() int { return int(c) }
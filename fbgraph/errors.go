@@ -5,4 +5,6 @@ import "errors"
 var (
 	// ErrApplicationRateLimitReached is returned when the Facebook application rate limit is reached (code 4).
 	ErrApplicationRateLimitReached = errors.New("application rate limit reached")
+	// ErrSendRateLimited is returned when a SendLimiter rejects a send.
+	ErrSendRateLimited = errors.New("send rate limited")
 )
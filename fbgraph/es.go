@@ -10,8 +10,7 @@ import (
 
 // PostSubscribedApps is a required step for Embedded SignUp
 func (c *Client) PostSubscribedApps(ctx context.Context, wabaID string) error {
-	c.lastGraphError = nil
-	c.lastErrorRawBody = ""
+	c.resetLastError()
 
 	apiVersion := DefaultGraphAPIVersion
 	if c.GraphAPIVersion != "" {
@@ -20,7 +19,7 @@ func (c *Client) PostSubscribedApps(ctx context.Context, wabaID string) error {
 
 	nilContent := strings.NewReader("{}")
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/subscribed_apps", apiVersion, wabaID)
+	url := fmt.Sprintf("%s/%s/%s/subscribed_apps", c.baseURL(), apiVersion, wabaID)
 
 	req, err := NewRequestWithContext(ctx, http.MethodPost, url, nilContent)
 	if err != nil {
@@ -28,7 +27,7 @@ func (c *Client) PostSubscribedApps(ctx context.Context, wabaID string) error {
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
@@ -36,13 +35,7 @@ func (c *Client) PostSubscribedApps(ctx context.Context, wabaID string) error {
 
 	defer func() { _ = resp.Body.Close() }()
 	if resp.StatusCode != http.StatusOK {
-		gerr := c.errorFromResponse(resp)
-		if ge, ok := AsGraphError(gerr); ok {
-			if ge.Code == 4 {
-				return ErrApplicationRateLimitReached
-			}
-		}
-		return gerr
+		return rateLimitError(c.errorFromResponse(resp))
 	}
 
 	_, _ = io.Copy(io.Discard, resp.Body)
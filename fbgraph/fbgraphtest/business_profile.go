@@ -0,0 +1,99 @@
+package fbgraphtest
+
+import (
+	"net/http"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+// BusinessProfile returns the business profile of a phone number.
+func (s *Server) BusinessProfile(phoneID string) (fbgraph.BusinessProfile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.profiles[phoneID]
+	if !ok {
+		return fbgraph.BusinessProfile{}, false
+	}
+	return *p, true
+}
+
+func (s *Server) handleGetBusinessProfile(w http.ResponseWriter, _ *http.Request, phoneID string) {
+	if s.phones[phoneID] == "" {
+		s.writeError(w, http.StatusBadRequest, unknownObject(phoneID))
+		return
+	}
+	p, ok := s.profiles[phoneID]
+	if !ok {
+		p = &fbgraph.BusinessProfile{}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"data": []map[string]any{{
+		"about":               p.About,
+		"address":             p.Address,
+		"description":         p.Description,
+		"email":               p.Email,
+		"profile_picture_url": p.ProfilePictureURL,
+		"vertical":            p.Vertical,
+		"websites":            p.Websites,
+		"messaging_product":   "whatsapp",
+	}}})
+}
+
+func (s *Server) handleUpdateBusinessProfile(w http.ResponseWriter, r *http.Request, phoneID string) {
+	if s.phones[phoneID] == "" {
+		s.writeError(w, http.StatusBadRequest, unknownObject(phoneID))
+		return
+	}
+	var body struct {
+		MessagingProduct string `json:"messaging_product"`
+		fbgraph.UpdateBusinessProfileParams
+	}
+	if err := decodeBody(r, &body); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	if body.MessagingProduct != "whatsapp" {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The parameter messaging_product is required."))
+		return
+	}
+	if err := body.Validate(); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	var picture *UploadSession
+	if body.ProfilePictureHandle != "" {
+		for _, us := range s.uploadSessions {
+			if us.Finished && us.Handle == body.ProfilePictureHandle {
+				picture = us
+			}
+		}
+		if picture == nil {
+			s.writeError(w, http.StatusBadRequest, invalidParameter("Invalid profile_picture_handle."))
+			return
+		}
+	}
+
+	p, ok := s.profiles[phoneID]
+	if !ok {
+		p = &fbgraph.BusinessProfile{}
+		s.profiles[phoneID] = p
+	}
+	set := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	set(&p.About, body.About)
+	set(&p.Address, body.Address)
+	set(&p.Description, body.Description)
+	set(&p.Email, body.Email)
+	if body.Vertical != "" {
+		p.Vertical = body.Vertical
+	}
+	if body.Websites != nil {
+		p.Websites = body.Websites
+	}
+	if picture != nil {
+		p.ProfilePictureURL = s.URL + "/pps/" + picture.ID
+	}
+	s.writeSuccess(w)
+}
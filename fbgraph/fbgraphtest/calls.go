@@ -0,0 +1,129 @@
+package fbgraphtest
+
+import (
+	"net/http"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+// CallAction is a request received by the calls edge.
+type CallAction struct {
+	PhoneID string
+	Action  string // connect, pre_accept, accept, terminate
+	CallID  string
+	To      string
+	Session fbgraph.CallRequestSession
+}
+
+// Calls returns every call action received so far, in order.
+func (s *Server) Calls() []CallAction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]CallAction(nil), s.calls...)
+}
+
+// SetCallPermission sets what call_permissions reports for a user. Users
+// default to no_permission.
+func (s *Server) SetCallPermission(phoneID, userWAID string, status fbgraph.CallPermissionStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callPerms[phoneID+"/"+userWAID] = status
+}
+
+// Settings returns the stored settings of a phone number.
+func (s *Server) Settings(phoneID string) (fbgraph.WhatsappSettings, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.settings[phoneID]
+	if !ok {
+		return fbgraph.WhatsappSettings{}, false
+	}
+	return *st, true
+}
+
+func (s *Server) handleCalls(w http.ResponseWriter, r *http.Request, phoneID string) {
+	var body struct {
+		MessagingProduct string                     `json:"messaging_product"`
+		Action           string                     `json:"action"`
+		CallID           string                     `json:"call_id"`
+		To               string                     `json:"to"`
+		Session          fbgraph.CallRequestSession `json:"session"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	if body.MessagingProduct != "whatsapp" {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The parameter messaging_product is required."))
+		return
+	}
+
+	ca := CallAction{PhoneID: phoneID, Action: body.Action, CallID: body.CallID, To: body.To, Session: body.Session}
+	switch body.Action {
+	case "connect":
+		if body.To == "" {
+			s.writeError(w, http.StatusBadRequest, invalidParameter("The parameter to is required."))
+			return
+		}
+		ca.CallID = "wacid.FAKE" + s.nextID()
+		s.calls = append(s.calls, ca)
+		s.writeJSON(w, http.StatusOK, map[string]any{
+			"messaging_product": "whatsapp",
+			"calls":             []map[string]string{{"id": ca.CallID}},
+		})
+	case "pre_accept", "accept", "terminate", "reject":
+		if body.CallID == "" {
+			s.writeError(w, http.StatusBadRequest, invalidParameter("The parameter call_id is required."))
+			return
+		}
+		s.calls = append(s.calls, ca)
+		s.writeSuccess(w)
+	default:
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Invalid action."))
+	}
+}
+
+func (s *Server) handleCallPermissions(w http.ResponseWriter, r *http.Request, phoneID string) {
+	user := r.URL.Query().Get("user_wa_id")
+	if user == "" {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The parameter user_wa_id is required."))
+		return
+	}
+	status, ok := s.callPerms[phoneID+"/"+user]
+	if !ok {
+		status = fbgraph.CallPermissionStatusNoPermission
+	}
+	s.writeJSON(w, http.StatusOK, fbgraph.CallPermissionsResponse{
+		MessagingProduct: "whatsapp",
+		Permission:       fbgraph.CallPermissionInfo{Status: status},
+		Actions: []fbgraph.CallPermissionAction{
+			{ActionName: "send_call_permission_request", CanPerformAction: status == fbgraph.CallPermissionStatusNoPermission},
+			{ActionName: "start_call", CanPerformAction: status != fbgraph.CallPermissionStatusNoPermission},
+		},
+	})
+}
+
+func (s *Server) handleGetSettings(w http.ResponseWriter, _ *http.Request, phoneID string) {
+	st, ok := s.settings[phoneID]
+	if !ok {
+		st = &fbgraph.WhatsappSettings{}
+		st.Calling.Status = fbgraph.CallingStatusNotSet
+		st.Calling.CallIconVisibility = fbgraph.CallVisibilityNotSet
+		st.Calling.CallbackPermissionStatus = fbgraph.CallingStatusNotSet
+	}
+	s.writeJSON(w, http.StatusOK, st)
+}
+
+func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request, phoneID string) {
+	st := &fbgraph.WhatsappSettings{}
+	if err := decodeBody(r, st); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	if st.Calling.Status != "" && !st.Calling.Status.IsValid() {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Invalid status."))
+		return
+	}
+	s.settings[phoneID] = st
+	s.writeSuccess(w)
+}
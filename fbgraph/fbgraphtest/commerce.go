@@ -0,0 +1,119 @@
+package fbgraphtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+// CommerceSettings returns the commerce settings of a phone number.
+func (s *Server) CommerceSettings(phoneID string) (fbgraph.CommerceSettings, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cs, ok := s.commerce[phoneID]
+	if !ok {
+		return fbgraph.CommerceSettings{}, false
+	}
+	return *cs, true
+}
+
+// AddCatalogProduct adds a product to a catalog, creating the catalog on first
+// use, and returns the product ID.
+func (s *Server) AddCatalogProduct(catalogID string, p fbgraph.CatalogProduct) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.ID == "" {
+		p.ID = s.nextID()
+	}
+	s.catalogs[catalogID] = append(s.catalogs[catalogID], p)
+	return p.ID
+}
+
+func (s *Server) handleGetCommerceSettings(w http.ResponseWriter, _ *http.Request, phoneID string) {
+	if s.phones[phoneID] == "" {
+		s.writeError(w, http.StatusBadRequest, unknownObject(phoneID))
+		return
+	}
+	cs, ok := s.commerce[phoneID]
+	if !ok {
+		cs = &fbgraph.CommerceSettings{}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"data": []map[string]any{{
+		"id":                 phoneID,
+		"is_cart_enabled":    cs.IsCartEnabled,
+		"is_catalog_visible": cs.IsCatalogVisible,
+	}}})
+}
+
+func (s *Server) handleUpdateCommerceSettings(w http.ResponseWriter, r *http.Request, phoneID string) {
+	if s.phones[phoneID] == "" {
+		s.writeError(w, http.StatusBadRequest, unknownObject(phoneID))
+		return
+	}
+	q := r.URL.Query()
+	cs, ok := s.commerce[phoneID]
+	if !ok {
+		cs = &fbgraph.CommerceSettings{}
+	}
+	for name, dst := range map[string]*bool{"is_cart_enabled": &cs.IsCartEnabled, "is_catalog_visible": &cs.IsCatalogVisible} {
+		if !q.Has(name) {
+			continue
+		}
+		v, err := strconv.ParseBool(q.Get(name))
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, invalidParameter("Invalid "+name+"."))
+			return
+		}
+		*dst = v
+	}
+	s.commerce[phoneID] = cs
+	s.writeSuccess(w)
+}
+
+func (s *Server) handleCatalogProducts(w http.ResponseWriter, r *http.Request, catalogID string) {
+	products, ok := s.catalogs[catalogID]
+	if !ok {
+		s.writeError(w, http.StatusBadRequest, unknownObject(catalogID))
+		return
+	}
+	q := r.URL.Query()
+	if raw := q.Get("filter"); raw != "" {
+		var filter struct {
+			RetailerID struct {
+				IsAny []string `json:"is_any"`
+			} `json:"retailer_id"`
+		}
+		if err := json.Unmarshal([]byte(raw), &filter); err != nil {
+			s.writeError(w, http.StatusBadRequest, invalidParameter("Invalid filter."))
+			return
+		}
+		products = slices.DeleteFunc(slices.Clone(products), func(p fbgraph.CatalogProduct) bool {
+			return !slices.Contains(filter.RetailerID.IsAny, p.RetailerID)
+		})
+	}
+	limit := 25
+	if v, err := strconv.Atoi(q.Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	start := 0
+	if v, err := strconv.Atoi(q.Get("after")); err == nil && v > 0 {
+		start = min(v, len(products))
+	}
+	end := min(start+limit, len(products))
+
+	paging := map[string]any{"cursors": map[string]string{
+		"before": strconv.Itoa(start),
+		"after":  strconv.Itoa(end),
+	}}
+	if end < len(products) {
+		next := *r.URL
+		nq := next.Query()
+		nq.Set("after", strconv.Itoa(end))
+		next.RawQuery = nq.Encode()
+		paging["next"] = s.URL + next.RequestURI()
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"data": products[start:end], "paging": paging})
+}
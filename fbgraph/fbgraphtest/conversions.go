@@ -0,0 +1,58 @@
+package fbgraphtest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+// Meta rejects the whole batch when any event is older than this.
+const maxConversionEventAge = 7 * 24 * time.Hour
+
+// ConversionEvents returns the events accepted by a dataset.
+func (s *Server) ConversionEvents(datasetID string) []fbgraph.ConversionEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]fbgraph.ConversionEvent(nil), s.events[datasetID]...)
+}
+
+func (s *Server) handleCreateDataset(w http.ResponseWriter, _ *http.Request, wabaID string) {
+	id, ok := s.datasets[wabaID]
+	if !ok {
+		id = s.nextID()
+		s.datasets[wabaID] = id
+	}
+	s.writeJSON(w, http.StatusOK, map[string]string{"id": id})
+}
+
+func (s *Server) handleGetDataset(w http.ResponseWriter, _ *http.Request, wabaID string) {
+	data := []map[string]string{}
+	if id, ok := s.datasets[wabaID]; ok {
+		data = append(data, map[string]string{"id": id})
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (s *Server) handleConversionEvents(w http.ResponseWriter, r *http.Request, datasetID string) {
+	var body struct {
+		Data []fbgraph.ConversionEvent `json:"data"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	oldest := s.Now().Add(-maxConversionEventAge).Unix()
+	for _, ev := range body.Data {
+		if ev.EventTime < oldest {
+			ge := invalidParameter("")
+			ge.Message = "Invalid parameter"
+			ge.ErrorUserTitle = "Event Timestamp Too Old"
+			ge.ErrorUserMsg = "The timestamp for this event is more than 7 days in the past."
+			s.writeError(w, http.StatusBadRequest, ge)
+			return
+		}
+	}
+	s.events[datasetID] = append(s.events[datasetID], body.Data...)
+	s.writeJSON(w, http.StatusOK, map[string]any{"events_received": len(body.Data), "fbtrace_id": "Afake" + s.nextID()})
+}
@@ -0,0 +1,177 @@
+package fbgraphtest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+// Errors as Meta sends them, ready to be used in a Failure.
+var (
+	ErrInvalidToken = fbgraph.GraphError{
+		Message: "Invalid OAuth access token - Cannot parse access token",
+		Type:    "OAuthException",
+		Code:    190,
+	}
+	ErrAppRateLimit = fbgraph.GraphError{
+		Message:     "(#4) Application request limit reached",
+		Type:        "OAuthException",
+		Code:        4,
+		IsTransient: true,
+	}
+	ErrTemporarilyUnavailable = fbgraph.GraphError{
+		Message:     "(#2) Service temporarily unavailable",
+		Type:        "OAuthException",
+		Code:        2,
+		IsTransient: true,
+	}
+	ErrThroughputReached = fbgraph.GraphError{
+		Message: "(#130429) Rate limit hit",
+		Type:    "OAuthException",
+		Code:    130429,
+		ErrorData: fbgraph.ErrorData{
+			MessagingProduct: "whatsapp",
+			Details:          "Cloud API message throughput has been reached.",
+		},
+	}
+	ErrPairRateLimit = fbgraph.GraphError{
+		Message: "(#131056) (Business Account, Consumer Account) pair rate limit hit",
+		Type:    "OAuthException",
+		Code:    131056,
+		ErrorData: fbgraph.ErrorData{
+			MessagingProduct: "whatsapp",
+			Details:          "Too many messages sent from the sender phone number to the same recipient phone number in a short period of time.",
+		},
+	}
+	ErrReEngagementRequired = fbgraph.GraphError{
+		Message: "(#131047) Re-engagement message",
+		Type:    "OAuthException",
+		Code:    131047,
+		ErrorData: fbgraph.ErrorData{
+			MessagingProduct: "whatsapp",
+			Details:          "Message failed to send because more than 24 hours have passed since the customer last replied to this number.",
+		},
+	}
+	ErrInternal = fbgraph.GraphError{
+		Message:     "(#131000) Something went wrong",
+		Type:        "OAuthException",
+		Code:        131000,
+		IsTransient: true,
+	}
+	ErrTemplateNotFound = fbgraph.GraphError{
+		Message: "(#132001) Template name does not exist in the translation",
+		Type:    "OAuthException",
+		Code:    132001,
+	}
+	ErrTwoStepPINMismatch = fbgraph.GraphError{
+		Message: "(#133005) Two step verification PIN Mismatch",
+		Type:    "OAuthException",
+		Code:    133005,
+	}
+	ErrRegisterRateLimit = fbgraph.GraphError{
+		Message: "(#133016) Account register deregister rate limit exceeded",
+		Type:    "OAuthException",
+		Code:    133016,
+	}
+)
+
+func invalidParameter(details string) fbgraph.GraphError {
+	return fbgraph.GraphError{
+		Message: "(#100) Invalid parameter",
+		Type:    "OAuthException",
+		Code:    100,
+		ErrorData: fbgraph.ErrorData{
+			MessagingProduct: "whatsapp",
+			Details:          details,
+		},
+	}
+}
+
+func unknownPath(path string) fbgraph.GraphError {
+	return fbgraph.GraphError{
+		Message: fmt.Sprintf("Unknown path components: %s", path),
+		Type:    "OAuthException",
+		Code:    2500,
+	}
+}
+
+func unknownObject(id string) fbgraph.GraphError {
+	return fbgraph.GraphError{
+		Message:      fmt.Sprintf("Unsupported get request. Object with ID '%s' does not exist, cannot be loaded due to missing permissions, or does not support this operation.", id),
+		Type:         "GraphMethodException",
+		Code:         100,
+		ErrorSubcode: 33,
+	}
+}
+
+// Failure scripts an error answer. The first unexhausted Failure matching a
+// request is used, before any state is touched.
+type Failure struct {
+	// Method restricts the failure to one HTTP method. Empty matches any.
+	Method string
+	// PathSuffix is matched against the end of the request path, e.g.
+	// "/messages" or "/message_templates". Empty matches any path.
+	PathSuffix string
+	// Status is the HTTP status. Defaults to 400.
+	Status int
+	// Error is sent in the Graph error envelope.
+	Error fbgraph.GraphError
+	// Body, when set, is sent verbatim instead of Error, e.g. an HTML gateway
+	// page for a bare 502.
+	Body string
+	// Times is how many requests fail. 0 means once; a negative value means
+	// every matching request fails.
+	Times int
+
+	used int
+}
+
+// Fail queues a scripted failure.
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &f)
+}
+
+// ClearFailures drops every queued failure.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+func (s *Server) matchFailure(r *http.Request) *Failure {
+	for _, f := range s.failures {
+		times := f.Times
+		if times == 0 {
+			times = 1
+		}
+		if times > 0 && f.used >= times {
+			continue
+		}
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if !strings.HasSuffix(r.URL.Path, f.PathSuffix) {
+			continue
+		}
+		f.used++
+		return f
+	}
+	return nil
+}
+
+func (s *Server) writeFailure(w http.ResponseWriter, f *Failure) {
+	status := f.Status
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+	if f.Body != "" {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(f.Body))
+		return
+	}
+	s.writeError(w, status, f.Error)
+}
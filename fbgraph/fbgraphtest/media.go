@@ -0,0 +1,178 @@
+package fbgraphtest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+// MediaFile is media stored through the media edge, or added with AddMedia.
+type MediaFile struct {
+	ID       string
+	PhoneID  string
+	MimeType string
+	Filename string
+	Data     []byte
+}
+
+// UploadSession is a resumable upload session created through the uploads
+// edge.
+type UploadSession struct {
+	ID       string
+	AppID    string
+	Params   fbgraph.NewUploadSessionParams
+	Data     []byte
+	Handle   string
+	Finished bool
+}
+
+// Media returns a stored media file.
+func (s *Server) Media(id string) (MediaFile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.media[id]
+	if !ok {
+		return MediaFile{}, false
+	}
+	return *m, true
+}
+
+// AddMedia stores a media file as if a user had sent it, and returns its ID.
+func (s *Server) AddMedia(mimeType string, data []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextID()
+	s.media[id] = &MediaFile{ID: id, MimeType: mimeType, Data: data}
+	return id
+}
+
+// UploadSession returns a stored upload session.
+func (s *Server) UploadSession(id string) (UploadSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	us, ok := s.uploadSessions[id]
+	if !ok {
+		return UploadSession{}, false
+	}
+	return *us, true
+}
+
+func (s *Server) handleUploadMedia(w http.ResponseWriter, r *http.Request, phoneID string) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	if r.FormValue("messaging_product") != "whatsapp" {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The parameter messaging_product is required."))
+		return
+	}
+	f, fh, err := r.FormFile("file")
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The parameter file is required."))
+		return
+	}
+	defer func() { _ = f.Close() }()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+
+	id := s.nextID()
+	s.media[id] = &MediaFile{
+		ID:       id,
+		PhoneID:  phoneID,
+		MimeType: fh.Header.Get("Content-Type"),
+		Filename: fh.Filename,
+		Data:     data,
+	}
+	s.writeJSON(w, http.StatusOK, map[string]string{"id": id})
+}
+
+func (s *Server) handleGetMedia(w http.ResponseWriter, _ *http.Request, id string) {
+	m := s.media[id]
+	sum := sha256.Sum256(m.Data)
+	s.writeJSON(w, http.StatusOK, fbgraph.GetMediaResult{
+		MessagingProduct: "whatsapp",
+		URL:              fmt.Sprintf("%s/media-bin/%s?ext=%d", s.URL, id, s.Now().Unix()),
+		MimeType:         m.MimeType,
+		Sha256:           hex.EncodeToString(sum[:]),
+		FileSize:         float64(len(m.Data)),
+		ID:               id,
+	})
+}
+
+func (s *Server) handleMediaDownload(w http.ResponseWriter, r *http.Request, id string) {
+	m, ok := s.media[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Header.Get("Authorization") == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if ext, err := strconv.ParseInt(r.URL.Query().Get("ext"), 10, 64); err == nil && s.MediaURLTTL > 0 &&
+		s.Now().Sub(time.Unix(ext, 0)) > s.MediaURLTTL {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", m.MimeType)
+	// ServeContent answers Range requests, which resumed downloads use
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(m.Data))
+}
+
+func (s *Server) handleNewUploadSession(w http.ResponseWriter, r *http.Request, appID string) {
+	var params fbgraph.NewUploadSessionParams
+	if err := decodeBody(r, &params); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	if params.FileLength <= 0 {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The parameter file_length is required."))
+		return
+	}
+	id := "upload:" + s.nextID()
+	s.uploadSessions[id] = &UploadSession{ID: id, AppID: appID, Params: params}
+	s.writeJSON(w, http.StatusOK, map[string]string{"id": id})
+}
+
+func (s *Server) handleGetUploadSession(w http.ResponseWriter, _ *http.Request, id string) {
+	us := s.uploadSessions[id]
+	s.writeJSON(w, http.StatusOK, map[string]any{"id": id, "file_offset": len(us.Data)})
+}
+
+func (s *Server) handleUploadData(w http.ResponseWriter, r *http.Request, id string) {
+	us := s.uploadSessions[id]
+	offset, err := strconv.Atoi(r.Header.Get("file_offset"))
+	if err != nil {
+		offset = 0
+	}
+	if offset != len(us.Data) {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(
+			fmt.Sprintf("file_offset %d does not match the uploaded length %d", offset, len(us.Data))))
+		return
+	}
+	// like the real session, keep whatever arrived before a dropped
+	// connection so the upload can resume from there
+	data, err := io.ReadAll(r.Body)
+	us.Data = append(us.Data, data...)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	if int64(len(us.Data)) < us.Params.FileLength {
+		s.writeJSON(w, http.StatusOK, map[string]any{"id": id, "file_offset": len(us.Data)})
+		return
+	}
+	us.Finished = true
+	us.Handle = "4:" + us.Params.FileName + ":" + s.nextID()
+	s.writeJSON(w, http.StatusOK, map[string]string{"h": us.Handle})
+}
@@ -0,0 +1,142 @@
+package fbgraphtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+// SentMessage is a message accepted by the messages or marketing_messages
+// edge.
+type SentMessage struct {
+	ID        string
+	PhoneID   string
+	Marketing bool
+	Message   fbgraph.MessageObject
+}
+
+// Messages returns every message accepted so far, in order.
+func (s *Server) Messages() []SentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SentMessage(nil), s.messages...)
+}
+
+// ReadReceipt is a status update accepted by the messages edge: a message
+// marked as read, with a typing indicator or not.
+type ReadReceipt struct {
+	PhoneID   string
+	MessageID string
+	Typing    bool
+}
+
+// ReadReceipts returns every status update accepted so far, in order.
+func (s *Server) ReadReceipts() []ReadReceipt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ReadReceipt(nil), s.readReceipts...)
+}
+
+func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request, phoneID string) {
+	// status updates share the edge with sends
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	var update fbgraph.MessageStatusUpdate
+	if json.Unmarshal(body, &update) == nil && update.Status != "" {
+		s.updateMessageStatus(w, phoneID, update)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	s.sendMessage(w, r, phoneID, false)
+}
+
+func (s *Server) updateMessageStatus(w http.ResponseWriter, phoneID string, update fbgraph.MessageStatusUpdate) {
+	switch {
+	case update.MessagingProduct != "whatsapp":
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The parameter messaging_product is required."))
+		return
+	case update.Status != "read":
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Param status must be one of {READ}."))
+		return
+	case update.MessageID == "":
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The parameter message_id is required."))
+		return
+	case update.TypingIndicator != nil && update.TypingIndicator.Type != "text":
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Param typing_indicator['type'] must be one of {TEXT}."))
+		return
+	}
+	s.readReceipts = append(s.readReceipts, ReadReceipt{PhoneID: phoneID, MessageID: update.MessageID, Typing: update.TypingIndicator != nil})
+	s.writeSuccess(w)
+}
+
+func (s *Server) handleSendMarketingMessage(w http.ResponseWriter, r *http.Request, phoneID string) {
+	s.sendMessage(w, r, phoneID, true)
+}
+
+func (s *Server) sendMessage(w http.ResponseWriter, r *http.Request, phoneID string, marketing bool) {
+	var msg fbgraph.MessageObject
+	if err := decodeBody(r, &msg); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	if ge, ok := s.validateMessage(phoneID, &msg); !ok {
+		s.writeError(w, http.StatusBadRequest, ge)
+		return
+	}
+
+	id := "wamid.FAKE" + s.nextID()
+	s.messages = append(s.messages, SentMessage{ID: id, PhoneID: phoneID, Marketing: marketing, Message: msg})
+
+	input := msg.To
+	if input == "" {
+		input = msg.Recipient
+	}
+	s.writeJSON(w, http.StatusOK, fbgraph.MessageObjectResult{
+		MessagingProduct: "whatsapp",
+		Contacts:         []fbgraph.ContactResult{{Input: input, WAID: input}},
+		Messages:         []fbgraph.MessageResult{{ID: id}},
+	})
+}
+
+func (s *Server) validateMessage(phoneID string, msg *fbgraph.MessageObject) (fbgraph.GraphError, bool) {
+	if msg.MessagingProduct != "whatsapp" {
+		return invalidParameter("The parameter messaging_product is required."), false
+	}
+	if msg.To == "" && msg.Recipient == "" {
+		return invalidParameter("The parameter to is required."), false
+	}
+	switch msg.Type {
+	case "":
+		return invalidParameter("The parameter type is required."), false
+	case "text":
+		if msg.Text == nil || msg.Text.Body == "" {
+			return invalidParameter("Param text['body'] is required."), false
+		}
+	case "template":
+		if msg.Template == nil || msg.Template.Name == "" {
+			return invalidParameter("Param template['name'] is required."), false
+		}
+		// only checked when the test told us which WABA owns the phone
+		if wabaID := s.phones[phoneID]; wabaID != "" {
+			lang := ""
+			if msg.Template.Language != nil {
+				lang = msg.Template.Language.Code
+			}
+			t := s.findTemplateByName(wabaID, msg.Template.Name, lang)
+			if t == nil || t.Status != "APPROVED" {
+				return ErrTemplateNotFound, false
+			}
+		}
+	case "interactive":
+		if msg.Interactive == nil {
+			return invalidParameter("Param interactive is required."), false
+		}
+	}
+	return fbgraph.GraphError{}, true
+}
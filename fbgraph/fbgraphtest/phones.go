@@ -0,0 +1,211 @@
+package fbgraphtest
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+// Registering and deregistering a number share a limit of 10 requests per 72
+// hours.
+const (
+	registerLimit  = 10
+	registerWindow = 72 * time.Hour
+)
+
+var pinRegexp = regexp.MustCompile(`^[0-9]{6}$`)
+
+// PhoneNumberState is the registration state of a phone number added with
+// AddPhoneNumber.
+type PhoneNumberState struct {
+	// VerificationCode is the last code sent through request_code.
+	VerificationCode       string
+	Verified               bool
+	Registered             bool
+	PIN                    string
+	DataLocalizationRegion string
+	// PendingDisplayName is the name of a display name change under review.
+	PendingDisplayName string
+	NewNameStatus      fbgraph.DisplayNameStatus
+
+	registrations []time.Time
+}
+
+// PhoneNumberState returns the registration state of a phone number.
+func (s *Server) PhoneNumberState(phoneID string) (PhoneNumberState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.phones[phoneID] == "" {
+		return PhoneNumberState{}, false
+	}
+	return *s.phoneState(phoneID), true
+}
+
+func (s *Server) phoneState(id string) *PhoneNumberState {
+	st, ok := s.phoneStates[id]
+	if !ok {
+		st = &PhoneNumberState{}
+		s.phoneStates[id] = st
+	}
+	return st
+}
+
+func (st *PhoneNumberState) codeVerificationStatus() string {
+	if st.Verified {
+		return "VERIFIED"
+	}
+	return "NOT_VERIFIED"
+}
+
+func (st *PhoneNumberState) status() string {
+	if st.Registered {
+		return "CONNECTED"
+	}
+	return "PENDING"
+}
+
+// phoneEdge resolves the phone number of an edge request, answering the
+// error itself when the number is unknown.
+func (s *Server) phoneEdge(w http.ResponseWriter, phoneID string) *PhoneNumberState {
+	if s.phones[phoneID] == "" {
+		s.writeError(w, http.StatusBadRequest, unknownObject(phoneID))
+		return nil
+	}
+	return s.phoneState(phoneID)
+}
+
+func (s *Server) handleRequestCode(w http.ResponseWriter, r *http.Request, phoneID string) {
+	st := s.phoneEdge(w, phoneID)
+	if st == nil {
+		return
+	}
+	var body struct {
+		CodeMethod string `json:"code_method"`
+		Language   string `json:"language"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	if body.CodeMethod != "SMS" && body.CodeMethod != "VOICE" {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Param code_method must be one of {SMS, VOICE}."))
+		return
+	}
+	if body.Language == "" {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The parameter language is required."))
+		return
+	}
+	st.VerificationCode = fmt.Sprintf("%06d", s.seq%900000+100000)
+	s.writeSuccess(w)
+}
+
+func (s *Server) handleVerifyCode(w http.ResponseWriter, r *http.Request, phoneID string) {
+	st := s.phoneEdge(w, phoneID)
+	if st == nil {
+		return
+	}
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	if st.VerificationCode == "" || body.Code != st.VerificationCode {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The verification code is not valid."))
+		return
+	}
+	st.Verified = true
+	s.writeSuccess(w)
+}
+
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request, phoneID string) {
+	st := s.phoneEdge(w, phoneID)
+	if st == nil {
+		return
+	}
+	var body struct {
+		MessagingProduct       string `json:"messaging_product"`
+		PIN                    string `json:"pin"`
+		DataLocalizationRegion string `json:"data_localization_region"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	switch {
+	case body.MessagingProduct != "whatsapp":
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The parameter messaging_product is required."))
+		return
+	case !pinRegexp.MatchString(body.PIN):
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Param pin must be 6 digits."))
+		return
+	case !s.allowRegistration(st):
+		s.writeError(w, http.StatusBadRequest, ErrRegisterRateLimit)
+		return
+	case st.PIN != "" && body.PIN != st.PIN:
+		s.writeError(w, http.StatusBadRequest, ErrTwoStepPINMismatch)
+		return
+	}
+	st.PIN = body.PIN
+	st.DataLocalizationRegion = body.DataLocalizationRegion
+	st.Registered = true
+	s.writeSuccess(w)
+}
+
+func (s *Server) handleDeregister(w http.ResponseWriter, _ *http.Request, phoneID string) {
+	st := s.phoneEdge(w, phoneID)
+	if st == nil {
+		return
+	}
+	if !s.allowRegistration(st) {
+		s.writeError(w, http.StatusBadRequest, ErrRegisterRateLimit)
+		return
+	}
+	st.Registered = false
+	s.writeSuccess(w)
+}
+
+// allowRegistration counts a register or deregister request against the
+// number's limit.
+func (s *Server) allowRegistration(st *PhoneNumberState) bool {
+	now := s.Now()
+	recent := st.registrations[:0]
+	for _, t := range st.registrations {
+		if now.Sub(t) < registerWindow {
+			recent = append(recent, t)
+		}
+	}
+	st.registrations = recent
+	if len(recent) >= registerLimit {
+		return false
+	}
+	st.registrations = append(st.registrations, now)
+	return true
+}
+
+func (s *Server) handleUpdatePhone(w http.ResponseWriter, r *http.Request, phoneID string) {
+	st := s.phoneState(phoneID)
+	if name := r.URL.Query().Get("new_display_name"); name != "" {
+		st.PendingDisplayName = name
+		st.NewNameStatus = fbgraph.NameStatusPendingReview
+		s.writeSuccess(w)
+		return
+	}
+	var body struct {
+		PIN string `json:"pin"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	if !pinRegexp.MatchString(body.PIN) {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Param pin must be 6 digits."))
+		return
+	}
+	st.PIN = body.PIN
+	s.writeSuccess(w)
+}
@@ -0,0 +1,141 @@
+package fbgraphtest
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+var qrImageFieldRe = regexp.MustCompile(`qr_image_url\.format\((PNG|SVG)\)`)
+
+// QRCodes returns the QR codes of a phone number, in creation order.
+func (s *Server) QRCodes(phoneID string) []fbgraph.QRCode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]fbgraph.QRCode, 0, len(s.qrCodes[phoneID]))
+	for _, qr := range s.qrCodes[phoneID] {
+		out = append(out, *qr)
+	}
+	return out
+}
+
+func (s *Server) findQRCode(phoneID, code string) *fbgraph.QRCode {
+	for _, qr := range s.qrCodes[phoneID] {
+		if qr.Code == code {
+			return qr
+		}
+	}
+	return nil
+}
+
+// qrView is qr as Graph returns it, with an image URL when format is set.
+func (s *Server) qrView(qr *fbgraph.QRCode, format string) fbgraph.QRCode {
+	v := *qr
+	v.QRImageURL = ""
+	if format != "" {
+		v.QRImageURL = fmt.Sprintf("%s/qr-bin/%s.%s", s.URL, qr.Code, strings.ToLower(format))
+	}
+	return v
+}
+
+func (s *Server) handleQRCodes(w http.ResponseWriter, r *http.Request, phoneID string) {
+	if s.phones[phoneID] == "" {
+		s.writeError(w, http.StatusBadRequest, unknownObject(phoneID))
+		return
+	}
+	q := r.URL.Query()
+	if r.Method == http.MethodGet {
+		out := make([]fbgraph.QRCode, 0, len(s.qrCodes[phoneID]))
+		for _, qr := range s.qrCodes[phoneID] {
+			out = append(out, s.qrView(qr, ""))
+		}
+		s.writeJSON(w, http.StatusOK, map[string]any{"data": out})
+		return
+	}
+
+	msg := q.Get("prefilled_message")
+	if msg == "" || utf8.RuneCountInString(msg) > fbgraph.MaxPrefilledMessageLength {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Param prefilled_message must be 1 to 140 characters."))
+		return
+	}
+	if code := q.Get("code"); code != "" {
+		qr := s.findQRCode(phoneID, code)
+		if qr == nil {
+			s.writeError(w, http.StatusBadRequest, unknownObject(code))
+			return
+		}
+		qr.PrefilledMessage = msg
+		s.writeJSON(w, http.StatusOK, s.qrView(qr, ""))
+		return
+	}
+	format := q.Get("generate_qr_image")
+	if format != "" && format != "PNG" && format != "SVG" {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Param generate_qr_image must be one of {PNG, SVG}."))
+		return
+	}
+	code := "QR" + s.nextID()
+	qr := &fbgraph.QRCode{
+		Code:             code,
+		PrefilledMessage: msg,
+		DeepLinkURL:      "https://wa.me/message/" + code,
+	}
+	s.qrCodes[phoneID] = append(s.qrCodes[phoneID], qr)
+	s.writeJSON(w, http.StatusOK, s.qrView(qr, format))
+}
+
+func (s *Server) handleQRCode(w http.ResponseWriter, r *http.Request, phoneID, code string) {
+	qr := s.findQRCode(phoneID, code)
+	if qr == nil {
+		s.writeError(w, http.StatusBadRequest, unknownObject(code))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		format := ""
+		if m := qrImageFieldRe.FindStringSubmatch(r.URL.Query().Get("fields")); m != nil {
+			format = m[1]
+		}
+		s.writeJSON(w, http.StatusOK, map[string]any{"data": []fbgraph.QRCode{s.qrView(qr, format)}})
+	case http.MethodDelete:
+		kept := s.qrCodes[phoneID][:0]
+		for _, q := range s.qrCodes[phoneID] {
+			if q != qr {
+				kept = append(kept, q)
+			}
+		}
+		s.qrCodes[phoneID] = kept
+		s.writeSuccess(w)
+	default:
+		s.writeError(w, http.StatusBadRequest, unknownPath(r.URL.Path))
+	}
+}
+
+// handleQRImage serves a stand-in image: a PNG signature or an SVG document
+// carrying the code.
+func (s *Server) handleQRImage(w http.ResponseWriter, _ *http.Request, file string) {
+	code, ext, _ := strings.Cut(file, ".")
+	found := false
+	for _, codes := range s.qrCodes {
+		for _, qr := range codes {
+			found = found || qr.Code == code
+		}
+	}
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch ext {
+	case "png":
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(append([]byte("\x89PNG\r\n\x1a\n"), code...))
+	case "svg":
+		w.Header().Set("Content-Type", "image/svg+xml")
+		_, _ = fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg"><text>%s</text></svg>`, code)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
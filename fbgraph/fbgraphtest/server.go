@@ -0,0 +1,310 @@
+// Package fbgraphtest provides an in-process stand-in for the WhatsApp Cloud
+// API, for integration tests of code built on fbgraph.Client.
+//
+// The Server keeps its state in memory, answers with the same JSON shapes Meta
+// does (errors included) and can be scripted to fail:
+//
+//	srv := fbgraphtest.NewServer()
+//	defer srv.Close()
+//	srv.Fail(fbgraphtest.Failure{PathSuffix: "/messages", Error: fbgraphtest.ErrPairRateLimit})
+//	c := srv.Client("token")
+package fbgraphtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+// Server is a fake Graph API host. Point a client at it with Client, or by
+// setting fbgraph.Client.BaseURL to URL.
+type Server struct {
+	*httptest.Server
+
+	// AccessToken, when set, is the only token accepted. When empty any
+	// non-empty token is.
+	AccessToken string
+	// AutoApproveTemplates makes created and edited templates APPROVED right
+	// away instead of PENDING.
+	AutoApproveTemplates bool
+	// Now is the server clock, used for edit windows and event ages.
+	Now func() time.Time
+	// MediaURLTTL, when set, makes media download URLs answer 404 once they
+	// are older than it, as Meta's signed URLs do after 5 minutes.
+	MediaURLTTL time.Duration
+
+	mu       sync.Mutex
+	seq      int64
+	requests []Request
+	failures []*Failure
+
+	messages       []SentMessage
+	readReceipts   []ReadReceipt
+	media          map[string]*MediaFile
+	uploadSessions map[string]*UploadSession
+	templates      map[string][]*storedTemplate // by WABA ID
+	subscribedApps map[string]bool
+	calls          []CallAction
+	callPerms      map[string]fbgraph.CallPermissionStatus // phoneID+"/"+user
+	settings       map[string]*fbgraph.WhatsappSettings
+	migrations     map[string]*fbgraph.MigrationStatusResponse
+	wabas          map[string]*wabaState
+	phones         map[string]string // phone ID -> WABA ID
+	phoneStates    map[string]*PhoneNumberState
+	profiles       map[string]*fbgraph.BusinessProfile
+	qrCodes        map[string][]*fbgraph.QRCode // by phone ID
+	datasets       map[string]string            // WABA ID -> dataset ID
+	events         map[string][]fbgraph.ConversionEvent
+	commerce       map[string]*fbgraph.CommerceSettings // by phone ID
+	catalogs       map[string][]fbgraph.CatalogProduct
+}
+
+// Request is one call the server received.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+}
+
+// NewServer starts a Server. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		Now:            time.Now,
+		media:          make(map[string]*MediaFile),
+		uploadSessions: make(map[string]*UploadSession),
+		templates:      make(map[string][]*storedTemplate),
+		subscribedApps: make(map[string]bool),
+		callPerms:      make(map[string]fbgraph.CallPermissionStatus),
+		settings:       make(map[string]*fbgraph.WhatsappSettings),
+		migrations:     make(map[string]*fbgraph.MigrationStatusResponse),
+		wabas:          make(map[string]*wabaState),
+		phones:         make(map[string]string),
+		phoneStates:    make(map[string]*PhoneNumberState),
+		profiles:       make(map[string]*fbgraph.BusinessProfile),
+		qrCodes:        make(map[string][]*fbgraph.QRCode),
+		datasets:       make(map[string]string),
+		events:         make(map[string][]fbgraph.ConversionEvent),
+		commerce:       make(map[string]*fbgraph.CommerceSettings),
+		catalogs:       make(map[string][]fbgraph.CatalogProduct),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns an fbgraph.Client wired to this server.
+func (s *Server) Client(accessToken string) *fbgraph.Client {
+	c := fbgraph.NewClient(accessToken)
+	c.HTTPClient = s.Server.Client()
+	c.BaseURL = s.URL
+	return c
+}
+
+// Requests returns every request received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// CountRequests returns how many requests had a path ending in pathSuffix.
+func (s *Server) CountRequests(method, pathSuffix string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, r := range s.requests {
+		if (method == "" || r.Method == method) && strings.HasSuffix(r.Path, pathSuffix) {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Server) nextID() string {
+	s.seq++
+	return strconv.FormatInt(1000000000+s.seq, 10)
+}
+
+var versionRe = regexp.MustCompile(`^v\d+\.\d+$`)
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery})
+
+	if f := s.matchFailure(r); f != nil {
+		s.writeFailure(w, f)
+		return
+	}
+
+	segs := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	versioned := versionRe.MatchString(segs[0])
+	if versioned {
+		segs = segs[1:]
+	}
+
+	// media downloads are signed URLs, not Graph calls
+	if !versioned && len(segs) == 2 && segs[0] == "media-bin" {
+		s.handleMediaDownload(w, r, segs[1])
+		return
+	}
+	if !versioned && len(segs) == 2 && segs[0] == "qr-bin" {
+		s.handleQRImage(w, r, segs[1])
+		return
+	}
+
+	if !s.authorized(r) {
+		s.writeError(w, http.StatusUnauthorized, ErrInvalidToken)
+		return
+	}
+
+	switch {
+	case !versioned && len(segs) == 3 && segs[1] == "message_templates" && segs[2] == "unarchive":
+		s.handleUnarchive(w, r, segs[0])
+	case versioned && len(segs) == 1:
+		s.handleNode(w, r, segs[0])
+	case versioned && len(segs) == 2:
+		s.handleEdge(w, r, segs[0], segs[1])
+	case versioned && len(segs) == 3 && segs[1] == "message_qrdls":
+		s.handleQRCode(w, r, segs[0], segs[2])
+	default:
+		s.writeError(w, http.StatusBadRequest, unknownPath(r.URL.Path))
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	tok := r.URL.Query().Get("access_token")
+	if h := r.Header.Get("Authorization"); h != "" {
+		if _, t, ok := strings.Cut(h, " "); ok {
+			tok = t
+		}
+	}
+	if tok == "" {
+		return false
+	}
+	return s.AccessToken == "" || tok == s.AccessToken
+}
+
+func (s *Server) handleNode(w http.ResponseWriter, r *http.Request, id string) {
+	switch r.Method {
+	case http.MethodGet:
+		switch {
+		case s.media[id] != nil:
+			s.handleGetMedia(w, r, id)
+		case s.uploadSessions[id] != nil:
+			s.handleGetUploadSession(w, r, id)
+		case s.findTemplate(id) != nil:
+			s.handleGetTemplate(w, r, id)
+		case s.migrations[id] != nil:
+			s.handleGetMigration(w, r, id)
+		case s.wabas[id] != nil:
+			s.handleGetWABA(w, r, id)
+		case s.phones[id] != "":
+			s.handleGetPhone(w, r, id)
+		default:
+			s.writeError(w, http.StatusBadRequest, unknownObject(id))
+		}
+	case http.MethodPost:
+		switch {
+		case s.uploadSessions[id] != nil:
+			s.handleUploadData(w, r, id)
+		case s.findTemplate(id) != nil:
+			s.handleUpdateTemplate(w, r, id)
+		case s.phones[id] != "":
+			s.handleUpdatePhone(w, r, id)
+		default:
+			s.writeError(w, http.StatusBadRequest, unknownObject(id))
+		}
+	default:
+		s.writeError(w, http.StatusBadRequest, unknownPath(r.URL.Path))
+	}
+}
+
+func (s *Server) handleEdge(w http.ResponseWriter, r *http.Request, id, edge string) {
+	type handler func(http.ResponseWriter, *http.Request, string)
+	routes := map[string]map[string]handler{
+		"messages":                            {http.MethodPost: s.handleSendMessage},
+		"marketing_messages":                  {http.MethodPost: s.handleSendMarketingMessage},
+		"media":                               {http.MethodPost: s.handleUploadMedia},
+		"uploads":                             {http.MethodPost: s.handleNewUploadSession},
+		"message_templates":                   {http.MethodGet: s.handleListTemplates, http.MethodPost: s.handleCreateTemplate, http.MethodDelete: s.handleDeleteTemplate},
+		"subscribed_apps":                     {http.MethodPost: s.handleSubscribedApps},
+		"calls":                               {http.MethodPost: s.handleCalls},
+		"call_permissions":                    {http.MethodGet: s.handleCallPermissions},
+		"settings":                            {http.MethodGet: s.handleGetSettings, http.MethodPost: s.handleUpdateSettings},
+		"set_payment_method_migration_intent": {http.MethodPost: s.handleMigrationIntent},
+		"resume_migration":                    {http.MethodPost: s.handleResumeMigration},
+		"phone_numbers":                       {http.MethodGet: s.handleListPhoneNumbers},
+		"request_code":                        {http.MethodPost: s.handleRequestCode},
+		"verify_code":                         {http.MethodPost: s.handleVerifyCode},
+		"register":                            {http.MethodPost: s.handleRegister},
+		"deregister":                          {http.MethodPost: s.handleDeregister},
+		"message_qrdls":                       {http.MethodGet: s.handleQRCodes, http.MethodPost: s.handleQRCodes},
+		"whatsapp_business_profile":           {http.MethodGet: s.handleGetBusinessProfile, http.MethodPost: s.handleUpdateBusinessProfile},
+		"dataset":                             {http.MethodGet: s.handleGetDataset, http.MethodPost: s.handleCreateDataset},
+		"events":                              {http.MethodPost: s.handleConversionEvents},
+		"whatsapp_commerce_settings":          {http.MethodGet: s.handleGetCommerceSettings, http.MethodPost: s.handleUpdateCommerceSettings},
+		"products":                            {http.MethodGet: s.handleCatalogProducts},
+	}
+	h, ok := routes[edge][r.Method]
+	if !ok {
+		s.writeError(w, http.StatusBadRequest, unknownPath(r.URL.Path))
+		return
+	}
+	h(w, r, id)
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) writeSuccess(w http.ResponseWriter) {
+	s.writeJSON(w, http.StatusOK, map[string]any{"success": true})
+}
+
+// writeError answers with a Graph error envelope. The fbtrace_id is filled in
+// when the error has none.
+func (s *Server) writeError(w http.ResponseWriter, status int, ge fbgraph.GraphError) {
+	if ge.FBTraceID == "" {
+		ge.FBTraceID = "Afake" + s.nextID()
+	}
+	body := map[string]any{
+		"message":    ge.Message,
+		"type":       ge.Type,
+		"code":       ge.Code,
+		"fbtrace_id": ge.FBTraceID,
+	}
+	if ge.ErrorSubcode != 0 {
+		body["error_subcode"] = ge.ErrorSubcode
+	}
+	if ge.IsTransient {
+		body["is_transient"] = true
+	}
+	if ge.ErrorUserTitle != "" {
+		body["error_user_title"] = ge.ErrorUserTitle
+	}
+	if ge.ErrorUserMsg != "" {
+		body["error_user_msg"] = ge.ErrorUserMsg
+	}
+	if ge.ErrorData.Details != "" {
+		body["error_data"] = ge.ErrorData
+	}
+	s.writeJSON(w, status, map[string]any{"error": body})
+}
+
+func decodeBody(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode body: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package fbgraphtest_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+	"github.com/pedidopago/wabaman-contrib/fbgraph/fbgraphtest"
+)
+
+func TestSendMessageAndScriptedFailure(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	msg := &fbgraph.MessageObject{
+		MessagingProduct: "whatsapp",
+		To:               "5511999999999",
+		Type:             "text",
+		Text:             &fbgraph.TextObject{Body: "oi"},
+	}
+	res, err := c.SendMessageWithContext(ctx, "phone1", msg)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if len(res.Messages) != 1 || !strings.HasPrefix(res.Messages[0].ID, "wamid.") {
+		t.Fatalf("result = %+v", res)
+	}
+
+	srv.Fail(fbgraphtest.Failure{PathSuffix: "/messages", Error: fbgraphtest.ErrPairRateLimit})
+	_, err = c.SendMessageWithContext(ctx, "phone1", msg)
+	ge, ok := fbgraph.AsGraphError(err)
+	if !ok || ge.Code != 131056 || ge.HTTPStatusCode != http.StatusBadRequest {
+		t.Fatalf("err = %v", err)
+	}
+	if !strings.Contains(ge.RawBody, "pair rate limit") {
+		t.Errorf("raw body = %s", ge.RawBody)
+	}
+
+	// the failure fires once, then sending works again
+	if _, err := c.SendMessageWithContext(ctx, "phone1", msg); err != nil {
+		t.Fatalf("send after failure: %v", err)
+	}
+	if got := len(srv.Messages()); got != 2 {
+		t.Errorf("accepted %d messages, want 2", got)
+	}
+	if got := srv.CountRequests(http.MethodPost, "/messages"); got != 3 {
+		t.Errorf("saw %d requests, want 3", got)
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+
+	_, err := c.SendMessageWithContext(context.Background(), "phone1", &fbgraph.MessageObject{To: "1", Type: "text"})
+	if ge, ok := fbgraph.AsGraphError(err); !ok || ge.Code != 100 {
+		t.Fatalf("err = %v, want code 100", err)
+	}
+
+	srv.AccessToken = "right"
+	_, err = srv.Client("wrong").GetMediaWithContext(context.Background(), "1")
+	if ge, ok := fbgraph.AsGraphError(err); !ok || ge.Code != 190 {
+		t.Fatalf("err = %v, want code 190", err)
+	}
+}
+
+func TestMediaRoundTrip(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	data := []byte("%PDF-1.4 fake")
+	id, err := c.UploadMediaWithContext(ctx, "phone1", "application/pdf", bytes.NewReader(data), int64(len(data)), "boleto.pdf")
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	mf, ok := srv.Media(id)
+	if !ok || mf.Filename != "boleto.pdf" || mf.MimeType != "application/pdf" {
+		t.Fatalf("stored media = %+v", mf)
+	}
+
+	mr, err := c.GetMediaWithContext(ctx, id)
+	if err != nil {
+		t.Fatalf("get media: %v", err)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := c.DownloadMediaWithContext(ctx, mr, buf); err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	if !mr.VerifyChecksum(bytes.NewReader(buf.Bytes())) || int(mr.FileSize) != len(data) {
+		t.Fatalf("downloaded %q, checksum/size mismatch for %+v", buf.String(), mr)
+	}
+}
+
+func TestUploadSession(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	data := []byte("fake-jpeg")
+	sid, err := c.NewUploadSessionWithContext(ctx, "app1", fbgraph.NewUploadSessionParams{
+		FileLength: int64(len(data)), FileName: "header.jpg", FileType: "image/jpeg",
+	})
+	if err != nil {
+		t.Fatalf("new session: %v", err)
+	}
+	h, err := c.UploadHeaderHandleWithContext(ctx, sid, bytes.NewReader(data))
+	if err != nil || h == "" {
+		t.Fatalf("upload: %q %v", h, err)
+	}
+	us, _ := srv.UploadSession(sid)
+	if !us.Finished || us.Handle != h || string(us.Data) != string(data) {
+		t.Fatalf("session = %+v", us)
+	}
+}
+
+func TestTemplateLifecycle(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	srv.Now = func() time.Time { return now }
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	for i := range 5 {
+		_, err := c.CreateMessageTemplate(ctx, "waba1", fbgraph.NewMessageTemplate{MessageTemplate: fbgraph.MessageTemplate{
+			Name:     fmt.Sprintf("tpl_%d", i),
+			Language: "pt_BR",
+			Category: fbgraph.MTCategoryUtility,
+			Components: []fbgraph.MessageTemplateComponent{
+				{Type: fbgraph.MTComponentBody, Text: "Olá {{1}}", Example: &fbgraph.MessageTemplateExample{BodyText: [][]string{{"Ana"}}}},
+			},
+		}})
+		if err != nil {
+			t.Fatalf("create %d: %v", i, err)
+		}
+	}
+
+	_, err := c.CreateMessageTemplate(ctx, "waba1", fbgraph.NewMessageTemplate{MessageTemplate: fbgraph.MessageTemplate{
+		Name: "tpl_0", Language: "pt_BR", Category: fbgraph.MTCategoryUtility,
+	}})
+	if ge, ok := fbgraph.AsGraphError(err); !ok || ge.ErrorSubcode != 2388024 {
+		t.Fatalf("duplicate create err = %v", err)
+	}
+
+	page1, err := c.GetMessageTemplates(ctx, fbgraph.GetMessageTemplatesParameters{WhatsAppBusinessAccountID: "waba1", Limit: 3})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page1.Data) != 3 || page1.Paging.Next == "" {
+		t.Fatalf("page1 = %+v", page1)
+	}
+	page2, err := c.GetMessageTemplates(ctx, fbgraph.GetMessageTemplatesParameters{WhatsAppBusinessAccountID: "waba1", Limit: 3, After: page1.Paging.Cursors.After})
+	if err != nil {
+		t.Fatalf("list page 2: %v", err)
+	}
+	if len(page2.Data) != 2 || page2.Paging.Next != "" || page2.Data[0].Name != "tpl_3" {
+		t.Fatalf("page2 = %+v", page2)
+	}
+
+	id := page1.Data[0].ID
+	if page1.Data[0].Status != "PENDING" {
+		t.Fatalf("status = %s, want PENDING", page1.Data[0].Status)
+	}
+	srv.SetTemplateStatus(id, "APPROVED")
+	edit := []fbgraph.MessageTemplateComponent{{Type: fbgraph.MTComponentBody, Text: "Oi"}}
+	if err := c.UpdateMessageTemplate(ctx, id, edit); err != nil {
+		t.Fatalf("first edit: %v", err)
+	}
+	srv.SetTemplateStatus(id, "APPROVED")
+	if err := c.UpdateMessageTemplate(ctx, id, edit); err == nil {
+		t.Fatal("second edit within 24h must fail")
+	}
+	now = now.Add(25 * time.Hour)
+	if err := c.UpdateMessageTemplate(ctx, id, edit); err != nil {
+		t.Fatalf("edit after 24h: %v", err)
+	}
+
+	srv.SetTemplateStatus(id, fbgraph.MessageTemplateStatusArchived)
+	unarchived, failed, err := c.UnarchiveMessageTemplates(ctx, "waba1", []string{id, page1.Data[1].ID})
+	if err != nil {
+		t.Fatalf("unarchive: %v", err)
+	}
+	if len(unarchived) != 1 || unarchived[0] != id || len(failed) != 1 {
+		t.Fatalf("unarchived=%v failed=%v", unarchived, failed)
+	}
+
+	if err := c.DeleteMessageTemplate(ctx, "waba1", "tpl_4"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got := len(srv.Templates("waba1")); got != 4 {
+		t.Fatalf("%d templates left, want 4", got)
+	}
+}
+
+func TestTemplateSendChecksApproval(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	srv.AddPhoneNumber("waba1", fbgraph.WABAPhoneNumber{ID: "phone1", DisplayPhoneNumber: "+55 11 3000-0000"})
+	tid := srv.AddTemplate("waba1", fbgraph.MessageTemplate{Name: "promo", Language: "pt_BR", Status: "PENDING"})
+	c := srv.Client("tok")
+
+	msg := &fbgraph.MessageObject{
+		MessagingProduct: "whatsapp",
+		To:               "5511999999999",
+		Type:             "template",
+		Template:         &fbgraph.TemplateObject{Name: "promo", Language: &fbgraph.LanguageObject{Code: "pt_BR"}},
+	}
+	_, err := c.SendMessageWithContext(context.Background(), "phone1", msg)
+	if ge, ok := fbgraph.AsGraphError(err); !ok || ge.Code != 132001 {
+		t.Fatalf("err = %v, want 132001", err)
+	}
+	srv.SetTemplateStatus(tid, "APPROVED")
+	if _, err := c.SendMessageWithContext(context.Background(), "phone1", msg); err != nil {
+		t.Fatalf("send approved: %v", err)
+	}
+}
+
+func TestCallsAndSettings(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	out, err := c.InitiateCall(ctx, "phone1", fbgraph.InitiateCallRequest{To: "5511999999999", Session: fbgraph.CallRequestSession{SDPType: "offer", SDP: "v=0"}})
+	if err != nil {
+		t.Fatalf("initiate: %v", err)
+	}
+	if err := c.TerminateCall(ctx, "phone1", out.CallID); err != nil {
+		t.Fatalf("terminate: %v", err)
+	}
+	calls := srv.Calls()
+	if len(calls) != 2 || calls[0].Action != "connect" || calls[1].CallID != out.CallID {
+		t.Fatalf("calls = %+v", calls)
+	}
+
+	srv.SetCallPermission("phone1", "5511999999999", fbgraph.CallPermissionStatusTemporary)
+	perms, err := c.GetCallPermissions(ctx, "phone1", "5511999999999")
+	if err != nil || perms.Permission.Status != fbgraph.CallPermissionStatusTemporary {
+		t.Fatalf("perms = %+v, %v", perms, err)
+	}
+
+	st := &fbgraph.WhatsappSettings{}
+	st.Calling.Status = fbgraph.CallingStatusEnabled
+	if err := c.UpdateWhatsappSettings(ctx, "phone1", st); err != nil {
+		t.Fatalf("update settings: %v", err)
+	}
+	got, err := c.GetWhatsappSettings(ctx, "phone1")
+	if err != nil || got.Calling.Status != fbgraph.CallingStatusEnabled {
+		t.Fatalf("settings = %+v, %v", got, err)
+	}
+}
+
+func TestMigrationFlow(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	srv.AddWABA(fbgraph.WABAInfo{ID: "waba1", Currency: "USD", Name: "Acme"})
+	srv.AddPhoneNumber("waba1", fbgraph.WABAPhoneNumber{ID: "phone1"})
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	in, err := c.SetPaymentMethodMigrationIntent(ctx, "waba1", fbgraph.MigrationIntentRequest{Currency: "BRL"})
+	if err != nil {
+		t.Fatalf("intent: %v", err)
+	}
+	if _, err := c.ResumeMigration(ctx, in.MigrationID); err == nil {
+		t.Fatal("resume before READY_TO_COMPLETE must fail")
+	}
+	srv.SetMigrationStatus(in.MigrationID, fbgraph.MigrationStatusReadyToComplete, &fbgraph.MigrationDestinationWABA{ID: "waba2", Currency: "BRL"})
+	st, err := c.GetMigrationStatus(ctx, in.MigrationID)
+	if err != nil || st.DestinationWABA == nil || st.DestinationWABA.ID != "waba2" {
+		t.Fatalf("status = %+v, %v", st, err)
+	}
+	done, err := c.ResumeMigration(ctx, in.MigrationID)
+	if err != nil || done.Status != fbgraph.MigrationStatusCompleted {
+		t.Fatalf("resume = %+v, %v", done, err)
+	}
+
+	info, err := c.GetWABAInfo(ctx, "waba1")
+	if err != nil || info.Currency != "USD" {
+		t.Fatalf("info = %+v, %v", info, err)
+	}
+	phones, err := c.GetWABAPhoneNumbers(ctx, "waba1")
+	if err != nil || len(phones) != 1 {
+		t.Fatalf("phones = %+v, %v", phones, err)
+	}
+	if err := c.PostSubscribedApps(ctx, "waba1"); err != nil || !srv.SubscribedApps("waba1") {
+		t.Fatalf("subscribed apps: %v", err)
+	}
+}
+
+func TestConversions(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	ds, err := c.CreateDataset(ctx, "waba1")
+	if err != nil {
+		t.Fatalf("create dataset: %v", err)
+	}
+	if got, err := c.GetDatasetID(ctx, "waba1"); err != nil || got != ds {
+		t.Fatalf("dataset = %q, %v", got, err)
+	}
+	ev := fbgraph.ConversionEvent{EventName: "Lead", EventTime: time.Now().Unix(), ActionSource: "business_messaging", MessagingChannel: "whatsapp"}
+	if n, err := c.SendConversionEvents(ctx, ds, []fbgraph.ConversionEvent{ev}); err != nil || n != 1 {
+		t.Fatalf("events = %d, %v", n, err)
+	}
+	ev.EventTime = time.Now().Add(-8 * 24 * time.Hour).Unix()
+	if _, err := c.SendConversionEvents(ctx, ds, []fbgraph.ConversionEvent{ev}); err == nil {
+		t.Fatal("an event older than 7 days must reject the batch")
+	}
+	if got := len(srv.ConversionEvents(ds)); got != 1 {
+		t.Fatalf("stored %d events, want 1", got)
+	}
+}
+
+func TestBareServerError(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	srv.Fail(fbgraphtest.Failure{Status: http.StatusBadGateway, Body: "<html>502</html>"})
+	c := srv.Client("tok")
+
+	_, err := c.GetMigrationStatus(context.Background(), "x")
+	ge, ok := fbgraph.AsGraphError(err)
+	if !ok || ge.HTTPStatusCode != http.StatusBadGateway {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+package fbgraphtest
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+type storedTemplate struct {
+	fbgraph.MessageTemplate
+	edits []time.Time
+}
+
+// Approved templates may be edited once per 24 hours and 10 times per 30
+// days. Rejected and paused ones have no limit.
+const (
+	templateEditsPerDay   = 1
+	templateEditsPerMonth = 10
+)
+
+// AddTemplate stores a template on a WABA as-is (status included) and
+// returns its ID.
+func (s *Server) AddTemplate(wabaID string, t fbgraph.MessageTemplate) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.ID == "" {
+		t.ID = s.nextID()
+	}
+	s.templates[wabaID] = append(s.templates[wabaID], &storedTemplate{MessageTemplate: t})
+	return t.ID
+}
+
+// Templates returns the templates of a WABA.
+func (s *Server) Templates(wabaID string) []fbgraph.MessageTemplate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]fbgraph.MessageTemplate, 0, len(s.templates[wabaID]))
+	for _, t := range s.templates[wabaID] {
+		out = append(out, t.MessageTemplate)
+	}
+	return out
+}
+
+// SetTemplateStatus changes the review status of a template, e.g. to
+// "APPROVED", "REJECTED" or "ARCHIVED".
+func (s *Server) SetTemplateStatus(templateID, status string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.findTemplate(templateID)
+	if t == nil {
+		return false
+	}
+	t.Status = status
+	return true
+}
+
+func (s *Server) findTemplate(id string) *storedTemplate {
+	for _, list := range s.templates {
+		for _, t := range list {
+			if t.ID == id {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) findTemplateByName(wabaID, name, language string) *storedTemplate {
+	for _, t := range s.templates[wabaID] {
+		if t.Name == name && t.Language == language {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *Server) reviewStatus() string {
+	if s.AutoApproveTemplates {
+		return "APPROVED"
+	}
+	return "PENDING"
+}
+
+func (s *Server) handleGetTemplate(w http.ResponseWriter, _ *http.Request, id string) {
+	s.writeJSON(w, http.StatusOK, s.findTemplate(id).MessageTemplate)
+}
+
+func (s *Server) handleListTemplates(w http.ResponseWriter, r *http.Request, wabaID string) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 25
+	}
+	start := 0
+	if after := q.Get("after"); after != "" {
+		start = decodeCursor(after)
+	}
+
+	filtered := make([]fbgraph.MessageTemplate, 0)
+	for _, t := range s.templates[wabaID] {
+		if st := q.Get("status"); st != "" && t.Status != st {
+			continue
+		}
+		if cat := q.Get("category"); cat != "" && string(t.Category) != cat {
+			continue
+		}
+		if lang := q.Get("language"); lang != "" && t.Language != lang {
+			continue
+		}
+		filtered = append(filtered, t.MessageTemplate)
+	}
+
+	resp := fbgraph.GetMessageTemplatesResponse{Data: []fbgraph.MessageTemplate{}}
+	if start < len(filtered) {
+		end := min(start+limit, len(filtered))
+		resp.Data = filtered[start:end]
+		resp.Paging.Cursors.Before = encodeCursor(start)
+		resp.Paging.Cursors.After = encodeCursor(end)
+		if end < len(filtered) {
+			nq := r.URL.Query()
+			nq.Set("after", resp.Paging.Cursors.After)
+			resp.Paging.Next = s.URL + r.URL.Path + "?" + nq.Encode()
+		}
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func encodeCursor(i int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(i)))
+}
+
+func decodeCursor(c string) int {
+	b, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return 0
+	}
+	i, _ := strconv.Atoi(string(b))
+	return i
+}
+
+func (s *Server) handleCreateTemplate(w http.ResponseWriter, r *http.Request, wabaID string) {
+	var nt fbgraph.NewMessageTemplate
+	if err := decodeBody(r, &nt); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	switch {
+	case nt.Name == "":
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Param name is required."))
+		return
+	case nt.Language == "":
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Param language is required."))
+		return
+	case nt.Category == "":
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Param category is required."))
+		return
+	}
+	if s.findTemplateByName(wabaID, nt.Name, nt.Language) != nil {
+		ge := invalidParameter("")
+		ge.ErrorSubcode = 2388024
+		ge.ErrorUserTitle = "Content in this language already exists"
+		ge.ErrorUserMsg = "Content for " + nt.Language + " already exists for template " + nt.Name + "."
+		s.writeError(w, http.StatusBadRequest, ge)
+		return
+	}
+
+	t := nt.MessageTemplate
+	t.ID = s.nextID()
+	t.Status = s.reviewStatus()
+	t.QualityScore = nil
+	t.RejectedReason = ""
+	s.templates[wabaID] = append(s.templates[wabaID], &storedTemplate{MessageTemplate: t})
+	s.writeJSON(w, http.StatusOK, map[string]any{"id": t.ID, "status": t.Status, "category": t.Category})
+}
+
+func (s *Server) handleUpdateTemplate(w http.ResponseWriter, r *http.Request, id string) {
+	var body struct {
+		Category   fbgraph.MessageTemplateCategory    `json:"category"`
+		Components []fbgraph.MessageTemplateComponent `json:"components"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	t := s.findTemplate(id)
+
+	if body.Components != nil {
+		if t.Status == "PENDING" {
+			s.writeError(w, http.StatusBadRequest, invalidParameter("A template can't be edited while it is in review."))
+			return
+		}
+		if t.Status == "APPROVED" && !s.canEditTemplate(t) {
+			ge := invalidParameter("Approved templates can be edited once per day and 10 times per month.")
+			ge.ErrorUserTitle = "Template edit limit reached"
+			s.writeError(w, http.StatusBadRequest, ge)
+			return
+		}
+		t.Components = body.Components
+		t.edits = append(t.edits, s.Now())
+		t.Status = s.reviewStatus()
+	}
+	if body.Category != "" {
+		t.Category = body.Category
+	}
+	s.writeSuccess(w)
+}
+
+func (s *Server) canEditTemplate(t *storedTemplate) bool {
+	now := s.Now()
+	day, month := 0, 0
+	for _, e := range t.edits {
+		if now.Sub(e) < 24*time.Hour {
+			day++
+		}
+		if now.Sub(e) < 30*24*time.Hour {
+			month++
+		}
+	}
+	return day < templateEditsPerDay && month < templateEditsPerMonth
+}
+
+func (s *Server) handleDeleteTemplate(w http.ResponseWriter, r *http.Request, wabaID string) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Param name is required."))
+		return
+	}
+	kept := s.templates[wabaID][:0]
+	deleted := false
+	for _, t := range s.templates[wabaID] {
+		if t.Name == name {
+			deleted = true
+			continue
+		}
+		kept = append(kept, t)
+	}
+	if !deleted {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("Message template \""+name+"\" not found."))
+		return
+	}
+	s.templates[wabaID] = kept
+	s.writeSuccess(w)
+}
+
+func (s *Server) handleUnarchive(w http.ResponseWriter, r *http.Request, _ string) {
+	var body struct {
+		HSMIDs []string `json:"hsm_ids"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	unarchived := make([]string, 0, len(body.HSMIDs))
+	failed := make(map[string]string)
+	for _, id := range body.HSMIDs {
+		t := s.findTemplate(id)
+		switch {
+		case t == nil:
+			failed[id] = "Template not found"
+		case t.Status != fbgraph.MessageTemplateStatusArchived:
+			failed[id] = "Template is not archived"
+		default:
+			t.Status = "APPROVED"
+			unarchived = append(unarchived, id)
+		}
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{"unarchived_templates": unarchived, "failed_templates": failed})
+}
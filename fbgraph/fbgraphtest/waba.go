@@ -0,0 +1,196 @@
+package fbgraphtest
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+type wabaState struct {
+	info               fbgraph.WABAInfo
+	mmLiteStatus       fbgraph.MMLiteOnboardingStatus
+	phoneNumbers       []fbgraph.WABAPhoneNumber
+	messagingLimitTier fbgraph.MessageLimitingTier
+}
+
+// AddWABA registers a WhatsApp Business Account.
+func (s *Server) AddWABA(info fbgraph.WABAInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.waba(info.ID).info = info
+}
+
+// SetMMLiteOnboardingStatus sets what GetMMLiteOnboardingStatus reads for a
+// WABA.
+func (s *Server) SetMMLiteOnboardingStatus(wabaID string, status fbgraph.MMLiteOnboardingStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.waba(wabaID).mmLiteStatus = status
+}
+
+// AddPhoneNumber attaches a phone number to a WABA. Template sends from that
+// phone are then checked against the WABA's approved templates.
+func (s *Server) AddPhoneNumber(wabaID string, p fbgraph.WABAPhoneNumber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := s.waba(wabaID)
+	w.phoneNumbers = append(w.phoneNumbers, p)
+	s.phones[p.ID] = wabaID
+}
+
+// SubscribedApps reports whether subscribed_apps was called for a WABA.
+func (s *Server) SubscribedApps(wabaID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.subscribedApps[wabaID]
+}
+
+// SetMigrationStatus moves a migration to status. destination is attached
+// when not nil.
+func (s *Server) SetMigrationStatus(migrationID, status string, destination *fbgraph.MigrationDestinationWABA) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.migrations[migrationID]
+	if !ok {
+		return false
+	}
+	m.Status = status
+	if destination != nil {
+		m.DestinationWABA = destination
+	}
+	return true
+}
+
+func (s *Server) waba(id string) *wabaState {
+	w, ok := s.wabas[id]
+	if !ok {
+		w = &wabaState{info: fbgraph.WABAInfo{ID: id}}
+		s.wabas[id] = w
+	}
+	return w
+}
+
+func (s *Server) handleSubscribedApps(w http.ResponseWriter, _ *http.Request, wabaID string) {
+	s.subscribedApps[wabaID] = true
+	s.writeSuccess(w)
+}
+
+func requestedFields(r *http.Request) []string {
+	f := r.URL.Query().Get("fields")
+	if f == "" {
+		return nil
+	}
+	return strings.Split(f, ",")
+}
+
+func (s *Server) handleGetWABA(w http.ResponseWriter, r *http.Request, id string) {
+	ws := s.wabas[id]
+	fields := requestedFields(r)
+	if slices.Contains(fields, "marketing_messages_onboarding_status") {
+		out := map[string]any{"id": id}
+		if ws.mmLiteStatus != "" {
+			out["marketing_messages_onboarding_status"] = ws.mmLiteStatus
+		}
+		s.writeJSON(w, http.StatusOK, out)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, ws.info)
+}
+
+func (s *Server) handleGetPhone(w http.ResponseWriter, _ *http.Request, id string) {
+	ws := s.wabas[s.phones[id]]
+	tier := ws.messagingLimitTier
+	if tier == "" {
+		tier = fbgraph.Tier1K
+	}
+	for _, p := range ws.phoneNumbers {
+		if p.ID == id {
+			st := s.phoneState(id)
+			s.writeJSON(w, http.StatusOK, map[string]any{
+				"id":                   p.ID,
+				"display_phone_number": p.DisplayPhoneNumber,
+				"verified_name":        p.VerifiedName,
+				"quality_rating":       p.QualityRating,
+				"whatsapp_business_manager_messaging_limit": tier,
+				"code_verification_status":                  st.codeVerificationStatus(),
+				"status":                                    st.status(),
+				"name_status":                               fbgraph.NameStatusApproved,
+				"new_name_status":                           st.NewNameStatus,
+				"data_localization_region":                  st.DataLocalizationRegion,
+			})
+			return
+		}
+	}
+	s.writeError(w, http.StatusBadRequest, unknownObject(id))
+}
+
+func (s *Server) handleListPhoneNumbers(w http.ResponseWriter, r *http.Request, wabaID string) {
+	ws, ok := s.wabas[wabaID]
+	if !ok {
+		s.writeError(w, http.StatusBadRequest, unknownObject(wabaID))
+		return
+	}
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 25
+	}
+	start := 0
+	if after := q.Get("after"); after != "" {
+		start = decodeCursor(after)
+	}
+
+	out := map[string]any{"data": []fbgraph.WABAPhoneNumber{}}
+	if start < len(ws.phoneNumbers) {
+		end := min(start+limit, len(ws.phoneNumbers))
+		paging := map[string]any{"cursors": map[string]string{"before": encodeCursor(start), "after": encodeCursor(end)}}
+		if end < len(ws.phoneNumbers) {
+			nq := r.URL.Query()
+			nq.Set("after", encodeCursor(end))
+			paging["next"] = s.URL + r.URL.Path + "?" + nq.Encode()
+		}
+		out["data"] = ws.phoneNumbers[start:end]
+		out["paging"] = paging
+	}
+	s.writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleMigrationIntent(w http.ResponseWriter, r *http.Request, wabaID string) {
+	var req fbgraph.MigrationIntentRequest
+	if err := decodeBody(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, invalidParameter(err.Error()))
+		return
+	}
+	if req.Currency == "" {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The parameter currency is required."))
+		return
+	}
+	if ws, ok := s.wabas[wabaID]; ok && strings.EqualFold(ws.info.Currency, req.Currency) {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The target currency matches the current currency of the WhatsApp Business Account."))
+		return
+	}
+	id := s.nextID()
+	s.migrations[id] = &fbgraph.MigrationStatusResponse{ID: id, Status: fbgraph.MigrationStatusInitiated}
+	s.writeJSON(w, http.StatusOK, fbgraph.MigrationIntentResponse{MigrationID: id, MigrationStatus: fbgraph.MigrationStatusInitiated})
+}
+
+func (s *Server) handleGetMigration(w http.ResponseWriter, _ *http.Request, id string) {
+	s.writeJSON(w, http.StatusOK, s.migrations[id])
+}
+
+func (s *Server) handleResumeMigration(w http.ResponseWriter, _ *http.Request, id string) {
+	m, ok := s.migrations[id]
+	if !ok {
+		s.writeError(w, http.StatusBadRequest, unknownObject(id))
+		return
+	}
+	if m.Status != fbgraph.MigrationStatusReadyToComplete {
+		s.writeError(w, http.StatusBadRequest, invalidParameter("The migration is not ready to complete."))
+		return
+	}
+	m.Status = fbgraph.MigrationStatusCompleted
+	s.writeJSON(w, http.StatusOK, m)
+}
@@ -5,8 +5,119 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
+// Middleware decorates the RoundTripper a Client sends its requests through.
+// Use it to add headers, record traffic or reroute requests without replacing
+// Client.HTTPClient.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper, which is the usual
+// way to write a Middleware.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func (c *Client) baseURL() string {
+	if c.BaseURL != "" {
+		return strings.TrimSuffix(c.BaseURL, "/")
+	}
+	return DefaultBaseURL
+}
+
+func (c *Client) unversionedBaseURL() string {
+	if c.BaseURL != "" {
+		return strings.TrimSuffix(c.BaseURL, "/")
+	}
+	return DefaultUnversionedBaseURL
+}
+
+// mediaURL reroutes a media URL returned by Meta to BaseURL, if one is set.
+func (c *Client) mediaURL(raw string) string {
+	if c.BaseURL == "" {
+		return raw
+	}
+	mu, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	bu, err := url.Parse(c.baseURL())
+	if err != nil {
+		return raw
+	}
+	mu.Scheme = bu.Scheme
+	mu.Host = bu.Host
+	if bu.Path != "" && !strings.HasPrefix(mu.Path, bu.Path+"/") {
+		mu.Path = bu.Path + mu.Path
+	}
+	return mu.String()
+}
+
+// do sends req through HTTPClient, wrapped by the client's Middleware, and
+// retries it as the client's RetryPolicy allows. Every attempt is recorded in
+// the request context's AttemptLog.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	hc := c.httpClient()
+	log := attemptLogFrom(req.Context())
+
+	for n := 1; ; n++ {
+		r := req
+		if n > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+		resp, err := hc.Do(r)
+
+		a := Attempt{Number: n, Method: req.Method, URL: req.URL.String(), Err: err}
+		if resp != nil {
+			a.StatusCode = resp.StatusCode
+			if resp.StatusCode >= 400 {
+				a.GraphError = peekGraphError(resp)
+			}
+		}
+		if !c.Retry.shouldRetry(req, a) {
+			log.add(a)
+			return resp, err
+		}
+		a.Delay = c.Retry.delay(n, resp)
+		log.add(a)
+		if !sleepContext(req.Context(), a.Delay) {
+			// the body was buffered by peekGraphError, so the last response
+			// can still be reported
+			return resp, err
+		}
+	}
+}
+
+func (c *Client) httpClient() *http.Client {
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = DefaultHTTPClient
+	}
+	if len(c.Middleware) == 0 {
+		return hc
+	}
+
+	rt := hc.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		rt = c.Middleware[i](rt)
+	}
+	wrapped := *hc
+	wrapped.Transport = rt
+	return &wrapped
+}
+
 func NewRequest(method string, url string, body io.Reader) (*http.Request, error) {
 	request, err := http.NewRequest(method, url, body)
 
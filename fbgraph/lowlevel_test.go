@@ -1,6 +1,14 @@
 package fbgraph
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
 
 func TestCompareGraphAPIVersions(t *testing.T) {
 	v1 := "v15.0"
@@ -69,3 +77,86 @@ func TestCompareGraphAPIVersions(t *testing.T) {
 		t.Errorf("Expected %s < %s, got %d", v1, v2, result)
 	}
 }
+
+func TestClientBaseURLIsHonoured(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/oauth/access_token"):
+			_, _ = io.WriteString(w, `{"access_token":"perm"}`)
+		case strings.HasSuffix(r.URL.Path, "/unarchive"):
+			_, _ = io.WriteString(w, `{"unarchived_templates":["1"]}`)
+		case strings.HasPrefix(r.URL.Path, "/emu/media-bin/"):
+			_, _ = io.WriteString(w, "bytes")
+		default:
+			_, _ = io.WriteString(w, `{"messages":[{"id":"wamid.1"}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient("tok")
+	c.BaseURL = srv.URL + "/emu/"
+	c.GraphAPIVersion = "v99.0"
+	ctx := context.Background()
+
+	if _, err := c.SendMessageWithContext(ctx, "123", &MessageObject{To: "5511999999999", Type: "text"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if _, err := c.NewPermanentAccessToken(ctx, "app", "secret", "tmp"); err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	if _, _, err := c.UnarchiveMessageTemplates(ctx, "waba", []string{"1"}); err != nil {
+		t.Fatalf("unarchive: %v", err)
+	}
+	buf := new(bytes.Buffer)
+	mr := &GetMediaResult{URL: "https://lookaside.fbsbx.com/media-bin/abc?ext=1"}
+	if _, err := c.DownloadMediaWithContext(ctx, mr, buf); err != nil {
+		t.Fatalf("download: %v", err)
+	}
+
+	want := []string{
+		"/emu/v99.0/123/messages",
+		"/emu/v99.0/oauth/access_token",
+		"/emu/waba/message_templates/unarchive",
+		"/emu/media-bin/abc",
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("path[%d] = %q, want %q", i, paths[i], want[i])
+		}
+	}
+	if buf.String() != "bytes" {
+		t.Errorf("downloaded %q", buf.String())
+	}
+}
+
+func TestClientMiddlewareOrder(t *testing.T) {
+	cl, rt := newTestClient(t, http.StatusOK, `{}`)
+
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				r.Header.Add("X-Trace", name)
+				return next.RoundTrip(r)
+			})
+		}
+	}
+	cl.Middleware = []Middleware{tag("outer"), tag("inner")}
+
+	if _, err := cl.GetMediaWithContext(context.Background(), "m1"); err != nil {
+		t.Fatalf("get media: %v", err)
+	}
+	if got := strings.Join(order, ","); got != "outer,inner" {
+		t.Errorf("order = %s", got)
+	}
+	if got := strings.Join(rt.gotReq.Header.Values("X-Trace"), ","); got != "outer,inner" {
+		t.Errorf("transport saw X-Trace = %q", got)
+	}
+}
@@ -0,0 +1,378 @@
+package fbgraph
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MediaIDLifetime is how long an uploaded media ID can be used in sends.
+const MediaIDLifetime = 30 * 24 * time.Hour
+
+// MediaCacheEntry is a media ID remembered by a MediaCache.
+type MediaCacheEntry struct {
+	MediaID   string    `json:"media_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// MediaCacheStore keeps the entries of a MediaCache. Keys are opaque strings
+// made of the phone number ID, the MIME type and the content's sha256. Implementations must
+// be safe for concurrent use.
+type MediaCacheStore interface {
+	Get(key string) (MediaCacheEntry, bool, error)
+	Put(key string, e MediaCacheEntry) error
+	Delete(key string) error
+}
+
+// MediaCache reuses media IDs for content already uploaded from a phone
+// number with the same MIME type. Content is identified by its sha256, the
+// hash Meta reports in GetMediaResult.Sha256, and an ID is reused until it
+// expires.
+//
+//	cache := &fbgraph.MediaCache{Client: c, Store: fbgraph.NewMemoryMediaStore(10000)}
+//	id, err := cache.Upload(ctx, phoneID, "application/pdf", f, "boleto.pdf")
+type MediaCache struct {
+	Client *Client
+	Store  MediaCacheStore
+	// TTL is how long a media ID is reused. Defaults to MediaIDLifetime less a
+	// day, so an ID is never handed out moments before it expires.
+	TTL time.Duration
+	// IsExpired reports whether a send failed because of its media ID.
+	// Defaults to IsMediaIDError.
+	IsExpired func(err error) bool
+	// Now defaults to time.Now.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	inflight map[string]*mediaUpload
+}
+
+type mediaUpload struct {
+	done chan struct{}
+	id   string
+	err  error
+}
+
+// IsMediaIDError reports whether err is Meta refusing a media ID, as it does
+// for IDs past their 30 days.
+func IsMediaIDError(err error) bool {
+	if errors.Is(err, ErrMediaUpload) {
+		return true
+	}
+	ge, ok := AsGraphError(err)
+	if !ok || ge.Code != ErrInvalidParameter.Code {
+		return false
+	}
+	return strings.Contains(strings.ToLower(ge.Message+" "+ge.ErrorData.Details), "media")
+}
+
+// MediaCacheKey is the store key of content with the given sha256 (hex)
+// uploaded from phoneID as mimeType. The same bytes uploaded as another MIME
+// type are another media, so they get another key.
+func MediaCacheKey(phoneID, mimeType, sha256Hex string) string {
+	return phoneID + ":" + strings.ToLower(mimeType) + ":" + strings.ToLower(sha256Hex)
+}
+
+// Upload returns the media ID of r's content for phoneID, uploading it with
+// UploadMedia unless an unexpired ID is cached. r is read once to hash it and
+// rewound for the upload, and is left where it started, so the same reader
+// can be uploaded again. Concurrent uploads of the same content share one
+// request; if that request is cancelled by its caller's ctx, the others
+// upload again with their own.
+func (m *MediaCache) Upload(ctx context.Context, phoneID, mimeType string, r io.ReadSeeker, filename string) (id string, err error) {
+	sum, size, err := hashReadSeeker(r)
+	if err != nil {
+		return "", err
+	}
+	key := MediaCacheKey(phoneID, mimeType, sum)
+
+	e, ok, err := m.Store.Get(key)
+	if err != nil {
+		return "", fmt.Errorf("media cache get: %w", err)
+	}
+	if ok && m.now().Before(e.ExpiresAt) {
+		return e.MediaID, nil
+	}
+
+	m.mu.Lock()
+	for up, ok := m.inflight[key]; ok; up, ok = m.inflight[key] {
+		m.mu.Unlock()
+		select {
+		case <-up.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		canceled := errors.Is(up.err, context.Canceled) || errors.Is(up.err, context.DeadlineExceeded)
+		if !canceled || ctx.Err() != nil {
+			return up.id, up.err
+		}
+		// the leader gave up, not the upload: take over with our ctx
+		m.mu.Lock()
+	}
+	if m.inflight == nil {
+		m.inflight = make(map[string]*mediaUpload)
+	}
+	up := &mediaUpload{done: make(chan struct{})}
+	m.inflight[key] = up
+	m.mu.Unlock()
+
+	defer func() {
+		up.id, up.err = id, err
+		close(up.done)
+		m.mu.Lock()
+		delete(m.inflight, key)
+		m.mu.Unlock()
+	}()
+
+	expiresAt := m.now().Add(m.ttl())
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", fmt.Errorf("seek: %w", err)
+	}
+	id, err = m.Client.UploadMediaWithContext(ctx, phoneID, mimeType, r, size, filename)
+	if _, serr := r.Seek(start, io.SeekStart); serr != nil && err == nil {
+		err = fmt.Errorf("seek: %w", serr)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := m.Store.Put(key, MediaCacheEntry{MediaID: id, ExpiresAt: expiresAt}); err != nil {
+		return id, fmt.Errorf("media cache put: %w", err)
+	}
+	return id, nil
+}
+
+// Send uploads r as Upload does and calls send with the media ID. When send
+// fails because the cached ID is no longer valid (see IsExpired), the entry is
+// dropped and send is retried once with a fresh upload.
+func (m *MediaCache) Send(ctx context.Context, phoneID, mimeType string, r io.ReadSeeker, filename string, send func(mediaID string) error) error {
+	for attempt := 1; ; attempt++ {
+		id, err := m.Upload(ctx, phoneID, mimeType, r, filename)
+		if err != nil {
+			return err
+		}
+		err = send(id)
+		if err == nil || attempt > 1 || !m.isExpired(err) {
+			return err
+		}
+		if err := m.invalidateReader(phoneID, mimeType, r); err != nil {
+			return err
+		}
+	}
+}
+
+// Invalidate drops the cached ID of the content with the given sha256 (hex)
+// and MIME type, e.g. after a webhook reported a failed send of that media.
+func (m *MediaCache) Invalidate(phoneID, mimeType, sha256Hex string) error {
+	if err := m.Store.Delete(MediaCacheKey(phoneID, mimeType, sha256Hex)); err != nil {
+		return fmt.Errorf("media cache delete: %w", err)
+	}
+	return nil
+}
+
+func (m *MediaCache) invalidateReader(phoneID, mimeType string, r io.ReadSeeker) error {
+	sum, _, err := hashReadSeeker(r)
+	if err != nil {
+		return err
+	}
+	return m.Invalidate(phoneID, mimeType, sum)
+}
+
+func (m *MediaCache) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+func (m *MediaCache) ttl() time.Duration {
+	if m.TTL > 0 {
+		return m.TTL
+	}
+	return MediaIDLifetime - 24*time.Hour
+}
+
+func (m *MediaCache) isExpired(err error) bool {
+	if m.IsExpired != nil {
+		return m.IsExpired(err)
+	}
+	return IsMediaIDError(err)
+}
+
+// hashReadSeeker returns the sha256 (hex) and size of r's content from its
+// current position, and rewinds r.
+func hashReadSeeker(r io.ReadSeeker) (string, int64, error) {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, fmt.Errorf("seek: %w", err)
+	}
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return "", 0, fmt.Errorf("hash content: %w", err)
+	}
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return "", 0, fmt.Errorf("seek: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// MemoryMediaStore is an in-memory MediaCacheStore that evicts the least
+// recently used entries beyond its capacity.
+type MemoryMediaStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front is most recently used
+	items    map[string]*list.Element
+}
+
+type memoryMediaItem struct {
+	key   string
+	entry MediaCacheEntry
+}
+
+// NewMemoryMediaStore returns a MemoryMediaStore holding up to capacity
+// entries; 0 means no limit.
+func NewMemoryMediaStore(capacity int) *MemoryMediaStore {
+	return &MemoryMediaStore{capacity: capacity, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (s *MemoryMediaStore) Get(key string) (MediaCacheEntry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[key]
+	if !ok {
+		return MediaCacheEntry{}, false, nil
+	}
+	s.order.MoveToFront(el)
+	return el.Value.(*memoryMediaItem).entry, true, nil
+}
+
+func (s *MemoryMediaStore) Put(key string, e MediaCacheEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		el.Value.(*memoryMediaItem).entry = e
+		s.order.MoveToFront(el)
+		return nil
+	}
+	s.items[key] = s.order.PushFront(&memoryMediaItem{key: key, entry: e})
+	for s.capacity > 0 && s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*memoryMediaItem).key)
+	}
+	return nil
+}
+
+func (s *MemoryMediaStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		s.order.Remove(el)
+		delete(s.items, key)
+	}
+	return nil
+}
+
+// Len returns the number of entries.
+func (s *MemoryMediaStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// FileMediaStore is a MediaCacheStore kept in a JSON file, so media IDs
+// survive restarts. The file is rewritten on every change; expired entries
+// are dropped when it is.
+type FileMediaStore struct {
+	path string
+	// Now defaults to time.Now.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]MediaCacheEntry
+}
+
+// OpenFileMediaStore loads the store at path, which need not exist yet.
+func OpenFileMediaStore(path string) (*FileMediaStore, error) {
+	s := &FileMediaStore{path: path, entries: make(map[string]MediaCacheEntry)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read media store: %w", err)
+	}
+	if err := json.Unmarshal(b, &s.entries); err != nil {
+		return nil, fmt.Errorf("decode media store: %w", err)
+	}
+	return s, nil
+}
+
+func (s *FileMediaStore) Get(key string) (MediaCacheEntry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	return e, ok, nil
+}
+
+func (s *FileMediaStore) Put(key string, e MediaCacheEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = e
+	return s.save()
+}
+
+func (s *FileMediaStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[key]; !ok {
+		return nil
+	}
+	delete(s.entries, key)
+	return s.save()
+}
+
+// save writes the entries to a temporary file and renames it over the store,
+// so a crash never leaves a truncated file behind.
+func (s *FileMediaStore) save() error {
+	now := time.Now()
+	if s.Now != nil {
+		now = s.Now()
+	}
+	for k, e := range s.entries {
+		if !now.Before(e.ExpiresAt) {
+			delete(s.entries, k)
+		}
+	}
+	b, err := json.Marshal(s.entries)
+	if err != nil {
+		return fmt.Errorf("encode media store: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("write media store: %w", err)
+	}
+	_, werr := tmp.Write(b)
+	cerr := tmp.Close()
+	if err := errors.Join(werr, cerr); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write media store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write media store: %w", err)
+	}
+	return nil
+}
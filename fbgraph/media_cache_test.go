@@ -0,0 +1,212 @@
+package fbgraph_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+	"github.com/pedidopago/wabaman-contrib/fbgraph/fbgraphtest"
+)
+
+func TestMediaCacheReusesIDs(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := &fbgraph.MediaCache{Client: srv.Client("tok"), Store: fbgraph.NewMemoryMediaStore(0), Now: func() time.Time { return now }}
+	ctx := context.Background()
+	pdf := []byte("%PDF-1.4 boleto")
+
+	id1, err := cache.Upload(ctx, "phone1", "application/pdf", bytes.NewReader(pdf), "a.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id2, err := cache.Upload(ctx, "phone1", "application/pdf", bytes.NewReader(pdf), "b.pdf")
+	if err != nil || id2 != id1 {
+		t.Fatalf("second upload = %q, %v; want cached %q", id2, err, id1)
+	}
+	if n := srv.CountRequests(http.MethodPost, "/media"); n != 1 {
+		t.Errorf("media uploads = %d, want 1", n)
+	}
+
+	// other phone numbers can't use the ID
+	if id, _ := cache.Upload(ctx, "phone2", "application/pdf", bytes.NewReader(pdf), "a.pdf"); id == id1 {
+		t.Error("media ID shared across phone numbers")
+	}
+	// nor can anyone once it expires
+	now = now.Add(fbgraph.MediaIDLifetime)
+	if id, _ := cache.Upload(ctx, "phone1", "application/pdf", bytes.NewReader(pdf), "a.pdf"); id == id1 {
+		t.Error("expired media ID reused")
+	}
+	if n := srv.CountRequests(http.MethodPost, "/media"); n != 3 {
+		t.Errorf("media uploads = %d, want 3", n)
+	}
+
+	// the same bytes uploaded as another MIME type are another media
+	csv := []byte("sku;qtd\ndipirona;2\n")
+	txt, err := cache.Upload(ctx, "phone1", "text/plain", bytes.NewReader(csv), "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xls, err := cache.Upload(ctx, "phone1", "application/vnd.ms-excel", bytes.NewReader(csv), "a.csv"); err != nil || xls == txt {
+		t.Errorf("upload as another type = %q, %v; want a new media ID", xls, err)
+	}
+}
+
+func TestMediaCacheSendReuploadsExpiredID(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	cache := &fbgraph.MediaCache{Client: srv.Client("tok"), Store: fbgraph.NewMemoryMediaStore(0)}
+	ctx := context.Background()
+	img := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	stale, err := cache.Upload(ctx, "phone1", "image/png", bytes.NewReader(img), "a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent []string
+	err = cache.Send(ctx, "phone1", "image/png", bytes.NewReader(img), "a.png", func(id string) error {
+		sent = append(sent, id)
+		if id == stale {
+			return &fbgraph.GraphError{Code: 131053, Message: "Media upload error"}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 || sent[1] == stale {
+		t.Errorf("sent with %v", sent)
+	}
+
+	sum := sha256.Sum256(img)
+	if err := cache.Invalidate("phone1", "image/png", hex.EncodeToString(sum[:])); err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := cache.Upload(ctx, "phone1", "image/png", bytes.NewReader(img), "a.png"); id == sent[1] {
+		t.Error("invalidated media ID reused")
+	}
+}
+
+func TestMediaCacheSendMissThenExpired(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	cache := &fbgraph.MediaCache{Client: srv.Client("tok"), Store: fbgraph.NewMemoryMediaStore(0)}
+	img := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	// the first upload is a miss, so the reader is at EOF when the send fails
+	var sent []string
+	err := cache.Send(context.Background(), "phone1", "image/png", bytes.NewReader(img), "a.png", func(id string) error {
+		sent = append(sent, id)
+		if len(sent) == 1 {
+			return &fbgraph.GraphError{Code: 131053, Message: "Media upload error"}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 2 || sent[0] == sent[1] {
+		t.Fatalf("sent with %v", sent)
+	}
+	if f, ok := srv.Media(sent[1]); !ok || !bytes.Equal(f.Data, img) {
+		t.Errorf("re-uploaded media has %d bytes, want %d", len(f.Data), len(img))
+	}
+	sum := sha256.Sum256(img)
+	if e, ok, _ := cache.Store.Get(fbgraph.MediaCacheKey("phone1", "image/png", hex.EncodeToString(sum[:]))); !ok || e.MediaID != sent[1] {
+		t.Errorf("cached entry = %+v, %v; want %s", e, ok, sent[1])
+	}
+}
+
+func TestMediaCacheWaiterOutlivesCanceledUpload(t *testing.T) {
+	started := make(chan struct{})
+	var uploads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		if uploads.Add(1) == 1 {
+			close(started)
+			<-r.Context().Done()
+			return
+		}
+		_, _ = io.WriteString(w, `{"id":"media2"}`)
+	}))
+	defer srv.Close()
+	c := fbgraph.NewClient("tok")
+	c.BaseURL = srv.URL
+	cache := &fbgraph.MediaCache{Client: c, Store: fbgraph.NewMemoryMediaStore(0)}
+	pdf := []byte("%PDF-1.4 boleto")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, err := cache.Upload(ctx, "phone1", "application/pdf", bytes.NewReader(pdf), "a.pdf")
+		leader <- err
+	}()
+	<-started
+	waiter := make(chan error, 1)
+	var id string
+	go func() {
+		var err error
+		id, err = cache.Upload(context.Background(), "phone1", "application/pdf", bytes.NewReader(pdf), "a.pdf")
+		waiter <- err
+	}()
+	time.Sleep(50 * time.Millisecond) // let the waiter join the upload
+	cancel()
+
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Errorf("leader = %v, want context.Canceled", err)
+	}
+	if err := <-waiter; err != nil || id != "media2" {
+		t.Errorf("waiter = %q, %v; want media2", id, err)
+	}
+	if n := uploads.Load(); n != 2 {
+		t.Errorf("uploads = %d, want 2", n)
+	}
+}
+
+func TestMemoryMediaStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	s := fbgraph.NewMemoryMediaStore(2)
+	_ = s.Put("a", fbgraph.MediaCacheEntry{MediaID: "1"})
+	_ = s.Put("b", fbgraph.MediaCacheEntry{MediaID: "2"})
+	_, _, _ = s.Get("a")
+	_ = s.Put("c", fbgraph.MediaCacheEntry{MediaID: "3"})
+	if _, ok, _ := s.Get("b"); ok {
+		t.Error("b was not evicted")
+	}
+	if _, ok, _ := s.Get("a"); !ok || s.Len() != 2 {
+		t.Errorf("a evicted, len = %d", s.Len())
+	}
+}
+
+func TestFileMediaStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "media.json")
+	s, err := fbgraph.OpenFileMediaStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := s.Put("k", fbgraph.MediaCacheEntry{MediaID: "m1", ExpiresAt: exp}); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Put("old", fbgraph.MediaCacheEntry{MediaID: "m0", ExpiresAt: time.Now().Add(-time.Hour)})
+
+	s, err = fbgraph.OpenFileMediaStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, ok, _ := s.Get("k"); !ok || e.MediaID != "m1" || !e.ExpiresAt.Equal(exp) {
+		t.Errorf("k = %+v, %v", e, ok)
+	}
+	if _, ok, _ := s.Get("old"); ok {
+		t.Error("expired entry was kept")
+	}
+}
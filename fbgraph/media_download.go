@@ -0,0 +1,213 @@
+package fbgraph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrMediaChecksumMismatch is matched by a *MediaIntegrityError when the
+	// downloaded bytes do not hash to GetMediaResult.Sha256.
+	ErrMediaChecksumMismatch = errors.New("media checksum mismatch")
+	// ErrMediaSizeMismatch is matched by a *MediaIntegrityError when the
+	// download is not GetMediaResult.FileSize bytes long.
+	ErrMediaSizeMismatch = errors.New("media size mismatch")
+)
+
+// MediaIntegrityError is returned by the verified downloads when the content
+// differs from what GetMedia announced. It unwraps to ErrMediaSizeMismatch or
+// ErrMediaChecksumMismatch.
+type MediaIntegrityError struct {
+	MediaID  string
+	WantSize int64
+	GotSize  int64
+	Want     string // sha256, hex
+	Got      string // sha256, hex
+	Err      error
+}
+
+func (e *MediaIntegrityError) Error() string {
+	if e.Err == ErrMediaSizeMismatch {
+		return fmt.Sprintf("media %s: %s: want %d bytes, got %d", e.MediaID, e.Err, e.WantSize, e.GotSize)
+	}
+	return fmt.Sprintf("media %s: %s: want sha256 %s, got %s", e.MediaID, e.Err, e.Want, e.Got)
+}
+
+func (e *MediaIntegrityError) Unwrap() error {
+	return e.Err
+}
+
+// DownloadMediaVerified is DownloadMedia that hashes the content as it streams
+// to out and checks its size and sha256 against mr. Media URLs expire a few
+// minutes after GetMedia; when the URL has expired, mr is refreshed with
+// GetMedia and the download retried. A *MediaIntegrityError means out got
+// bytes that must be discarded.
+func (c *Client) DownloadMediaVerified(ctx context.Context, mr *GetMediaResult, out io.Writer) (nwritten int64, err error) {
+	resp, err := c.openMedia(ctx, mr, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	h := sha256.New()
+	nwritten, err = io.Copy(io.MultiWriter(out, h), resp.Body)
+	if err != nil {
+		return nwritten, fmt.Errorf("download media: %w", err)
+	}
+	return nwritten, verifyMedia(mr, nwritten, h)
+}
+
+// DownloadMediaToFile downloads a media by ID into path, verified as
+// DownloadMediaVerified does. The file is written under a temporary name and
+// renamed into place, so path never holds partial or corrupt content.
+func (c *Client) DownloadMediaToFile(ctx context.Context, mediaID, path string) (*GetMediaResult, error) {
+	mr, err := c.GetMediaWithContext(ctx, mediaID)
+	if err != nil {
+		return nil, fmt.Errorf("get media: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.part")
+	if err != nil {
+		return nil, fmt.Errorf("create file: %w", err)
+	}
+	_, err = c.DownloadMediaVerified(ctx, mr, tmp)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, err
+	}
+	return mr, nil
+}
+
+// DownloadMediaAt downloads the media into w, resuming after the first offset
+// bytes, which w already holds, with an HTTP range request. It returns the
+// total size written. Resuming large videos this way keeps a dropped
+// connection from starting the download over.
+//
+// The size is always checked. The sha256 is checked too when w is also an
+// io.ReaderAt (an *os.File is), so the bytes already held can be hashed.
+func (c *Client) DownloadMediaAt(ctx context.Context, mr *GetMediaResult, w io.WriterAt, offset int64) (size int64, err error) {
+	if offset < 0 || offset > int64(mr.FileSize) {
+		offset = 0
+	}
+	if offset > 0 && offset == int64(mr.FileSize) {
+		// already complete: a Range request would only get a 416
+		h, err := hashHeld(w, offset)
+		if err != nil || h == nil {
+			return offset, err
+		}
+		return offset, verifyMedia(mr, offset, h)
+	}
+	resp, err := c.openMedia(ctx, mr, offset)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusPartialContent {
+		// the server sent the whole file
+		offset = 0
+	}
+
+	h, err := hashHeld(w, offset)
+	if err != nil {
+		return 0, err
+	}
+	var dst io.Writer = io.NewOffsetWriter(w, offset)
+	if h != nil {
+		dst = io.MultiWriter(dst, h)
+	}
+	n, err := io.Copy(dst, resp.Body)
+	size = offset + n
+	if err != nil {
+		return size, fmt.Errorf("download media: %w", err)
+	}
+	if h == nil {
+		return size, verifyMediaSize(mr, size)
+	}
+	return size, verifyMedia(mr, size, h)
+}
+
+// hashHeld returns a sha256 fed with the first n bytes of w, or nil when w
+// cannot be read back.
+func hashHeld(w io.WriterAt, n int64) (hash.Hash, error) {
+	ra, ok := w.(io.ReaderAt)
+	if !ok {
+		return nil, nil
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(ra, 0, n)); err != nil {
+		return nil, fmt.Errorf("hash downloaded part: %w", err)
+	}
+	return h, nil
+}
+
+// openMedia requests the media content from offset on, refreshing mr's URL
+// once if it has expired.
+func (c *Client) openMedia(ctx context.Context, mr *GetMediaResult, offset int64) (*http.Response, error) {
+	for refreshed := false; ; refreshed = true {
+		c.resetLastError()
+
+		req, err := NewRequestWithContext(ctx, http.MethodGet, c.mediaURL(mr.URL), nil)
+		if err != nil {
+			return nil, fmt.Errorf("new request: %w", err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
+		if offset > 0 {
+			req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		}
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+		expired := resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusNotFound
+		if !expired || refreshed || mr.ID == "" {
+			defer func() { _ = resp.Body.Close() }()
+			return nil, c.errorFromResponse(resp)
+		}
+		_ = resp.Body.Close()
+
+		fresh, err := c.GetMediaWithContext(ctx, mr.ID)
+		if err != nil {
+			return nil, fmt.Errorf("refresh media url: %w", err)
+		}
+		*mr = *fresh
+	}
+}
+
+func verifyMediaSize(mr *GetMediaResult, size int64) error {
+	if mr.FileSize > 0 && size != int64(mr.FileSize) {
+		return &MediaIntegrityError{MediaID: mr.ID, WantSize: int64(mr.FileSize), GotSize: size, Err: ErrMediaSizeMismatch}
+	}
+	return nil
+}
+
+func verifyMedia(mr *GetMediaResult, size int64, h hash.Hash) error {
+	if err := verifyMediaSize(mr, size); err != nil {
+		return err
+	}
+	got := hex.EncodeToString(h.Sum(nil))
+	if mr.Sha256 != "" && !strings.EqualFold(got, mr.Sha256) {
+		return &MediaIntegrityError{MediaID: mr.ID, Want: mr.Sha256, Got: got, Err: ErrMediaChecksumMismatch}
+	}
+	return nil
+}
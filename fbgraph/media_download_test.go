@@ -0,0 +1,158 @@
+package fbgraph_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+	"github.com/pedidopago/wabaman-contrib/fbgraph/fbgraphtest"
+)
+
+func TestDownloadMediaToFile(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+	data := bytes.Repeat([]byte("video"), 1000)
+	id := srv.AddMedia("video/mp4", data)
+
+	path := filepath.Join(t.TempDir(), "v.mp4")
+	mr, err := c.DownloadMediaToFile(context.Background(), id, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(path)
+	if !bytes.Equal(got, data) || mr.MimeType != "video/mp4" {
+		t.Errorf("file has %d bytes, media = %+v", len(got), mr)
+	}
+}
+
+func TestDownloadMediaRefreshesExpiredURL(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	now := time.Now()
+	srv.Now = func() time.Time { return now }
+	srv.MediaURLTTL = 5 * time.Minute
+	c := srv.Client("tok")
+	ctx := context.Background()
+	id := srv.AddMedia("image/jpeg", []byte("jpeg bytes"))
+
+	mr, err := c.GetMediaWithContext(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	staleURL := mr.URL
+	now = now.Add(10 * time.Minute)
+
+	buf := new(bytes.Buffer)
+	if _, err := c.DownloadMediaVerified(ctx, mr, buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "jpeg bytes" || mr.URL == staleURL {
+		t.Errorf("downloaded %q, url %s", buf, mr.URL)
+	}
+	// two GetMedia calls and two downloads
+	if n := srv.CountRequests(http.MethodGet, "/"+id); n != 4 {
+		t.Errorf("requests = %v", srv.Requests())
+	}
+}
+
+func TestDownloadMediaDetectsCorruption(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+	// flip the content of every media download
+	c.Middleware = []fbgraph.Middleware{func(next http.RoundTripper) http.RoundTripper {
+		return fbgraph.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(r)
+			if err == nil && strings.Contains(r.URL.Path, "/media-bin/") {
+				b, _ := io.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+				resp.Body = io.NopCloser(bytes.NewReader(bytes.ToUpper(b)))
+			}
+			return resp, err
+		})
+	}}
+	id := srv.AddMedia("application/pdf", []byte("%pdf-1.4 boleto"))
+
+	path := filepath.Join(t.TempDir(), "boleto.pdf")
+	_, err := c.DownloadMediaToFile(context.Background(), id, path)
+	var ie *fbgraph.MediaIntegrityError
+	if !errors.Is(err, fbgraph.ErrMediaChecksumMismatch) || !errors.As(err, &ie) || ie.MediaID != id {
+		t.Fatalf("err = %v, want a checksum mismatch", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 0 {
+		t.Errorf("left files behind: %v", entries)
+	}
+}
+
+func TestDownloadMediaAtResumes(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+	ctx := context.Background()
+	data := bytes.Repeat([]byte("0123456789"), 500)
+	id := srv.AddMedia("video/mp4", data)
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "v.mp4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Write(data[:1234]); err != nil {
+		t.Fatal(err)
+	}
+
+	var ranges []string
+	c.Middleware = []fbgraph.Middleware{func(next http.RoundTripper) http.RoundTripper {
+		return fbgraph.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if rg := r.Header.Get("Range"); rg != "" {
+				ranges = append(ranges, rg)
+			}
+			return next.RoundTrip(r)
+		})
+	}}
+	mr, err := c.GetMediaWithContext(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, err := c.DownloadMediaAt(ctx, mr, f, 1234)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(f.Name())
+	if size != int64(len(data)) || !bytes.Equal(got, data) {
+		t.Errorf("size = %d, file has %d bytes", size, len(got))
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=1234-" {
+		t.Errorf("ranges = %v", ranges)
+	}
+
+	// a corrupt first part is caught, since *os.File can be read back
+	if _, err := f.WriteAt([]byte("x"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DownloadMediaAt(ctx, mr, f, 1234); !errors.Is(err, fbgraph.ErrMediaChecksumMismatch) {
+		t.Errorf("err = %v, want a checksum mismatch", err)
+	}
+
+	// a complete file is only verified, without a request
+	if _, err := f.WriteAt(data[:1], 0); err != nil {
+		t.Fatal(err)
+	}
+	ranges = nil
+	if size, err := c.DownloadMediaAt(ctx, mr, f, int64(len(data))); err != nil || size != int64(len(data)) {
+		t.Errorf("complete file: size = %d, err = %v", size, err)
+	}
+	if len(ranges) != 0 {
+		t.Errorf("ranges = %v for a complete file", ranges)
+	}
+}
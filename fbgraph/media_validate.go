@@ -0,0 +1,149 @@
+package fbgraph
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Media size limits of the Cloud API, in bytes.
+//
+// See https://developers.facebook.com/docs/whatsapp/cloud-api/reference/media#supported-media-types
+const (
+	MaxImageSize           = 5 << 20
+	MaxVideoSize           = 16 << 20
+	MaxAudioSize           = 16 << 20
+	MaxDocumentSize        = 100 << 20
+	MaxStickerSize         = 100 << 10
+	MaxAnimatedStickerSize = 500 << 10
+)
+
+var (
+	// ErrUnsupportedMediaType is matched by a *MediaValidationError for a
+	// MIME type WhatsApp does not accept.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrMediaTooLarge is matched by a *MediaValidationError for a file over
+	// the limit of its type.
+	ErrMediaTooLarge = errors.New("media too large")
+	// ErrMediaTypeMismatch is matched by a *MediaValidationError for content
+	// that is not of the MIME type it was declared as.
+	ErrMediaTypeMismatch = errors.New("media content does not match its type")
+)
+
+// MediaValidationError is returned by ValidateMedia and UploadMedia for media
+// WhatsApp would reject. It unwraps to ErrUnsupportedMediaType,
+// ErrMediaTooLarge or ErrMediaTypeMismatch.
+type MediaValidationError struct {
+	MimeType string
+	// Sniffed is the content type detected from the first bytes.
+	Sniffed string
+	Size    int64
+	MaxSize int64
+	Err     error
+}
+
+func (e *MediaValidationError) Error() string {
+	switch e.Err {
+	case ErrMediaTooLarge:
+		return fmt.Sprintf("%s: %s is %d bytes, the limit is %d", e.Err, e.MimeType, e.Size, e.MaxSize)
+	case ErrMediaTypeMismatch:
+		return fmt.Sprintf("%s: declared %s, content is %s", e.Err, e.MimeType, e.Sniffed)
+	}
+	return fmt.Sprintf("%s: %q", e.Err, e.MimeType)
+}
+
+func (e *MediaValidationError) Unwrap() error {
+	return e.Err
+}
+
+// mediaSizeLimits are the accepted MIME types and their size limits. Stickers
+// (image/webp) are handled apart, their limit depends on animation.
+var mediaSizeLimits = map[string]int64{
+	"audio/aac":  MaxAudioSize,
+	"audio/amr":  MaxAudioSize,
+	"audio/mpeg": MaxAudioSize,
+	"audio/mp4":  MaxAudioSize,
+	"audio/ogg":  MaxAudioSize,
+
+	"text/plain":                    MaxDocumentSize,
+	"application/pdf":               MaxDocumentSize,
+	"application/msword":            MaxDocumentSize,
+	"application/vnd.ms-excel":      MaxDocumentSize,
+	"application/vnd.ms-powerpoint": MaxDocumentSize,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   MaxDocumentSize,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         MaxDocumentSize,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": MaxDocumentSize,
+
+	"image/jpeg": MaxImageSize,
+	"image/png":  MaxImageSize,
+
+	"video/3gpp": MaxVideoSize,
+	"video/mp4":  MaxVideoSize,
+}
+
+// sniffedAs maps the content types http.DetectContentType recognizes to the
+// MIME types such content may be declared as. A nil list means WhatsApp takes
+// no such content. Types missing here (application/octet-stream, zip
+// containers, plain text) prove nothing and are not checked.
+var sniffedAs = map[string][]string{
+	"image/jpeg":      {"image/jpeg"},
+	"image/png":       {"image/png"},
+	"image/webp":      {"image/webp"},
+	"image/gif":       nil,
+	"image/bmp":       nil,
+	"application/pdf": {"application/pdf"},
+	"audio/mpeg":      {"audio/mpeg"},
+	"application/ogg": {"audio/ogg"},
+	"video/mp4":       {"video/mp4", "video/3gpp", "audio/mp4", "audio/aac"},
+	"video/webm":      nil,
+	"audio/wave":      nil,
+}
+
+// ValidateMedia checks media against the types and size limits of the Cloud
+// API. head is the start of the content (512 bytes are enough), used to detect
+// its real type. An empty mimeType is filled in from the detected type. It
+// returns the MIME type to upload the media as.
+func ValidateMedia(mimeType string, head []byte, size int64) (string, error) {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	declared := sniffed
+	if mimeType != "" {
+		declared, _, _ = mime.ParseMediaType(mimeType)
+	}
+	declared = strings.ToLower(declared)
+
+	if allowed, known := sniffedAs[sniffed]; known && declared != sniffed && !slices.Contains(allowed, declared) {
+		return "", &MediaValidationError{MimeType: declared, Sniffed: sniffed, Err: ErrMediaTypeMismatch}
+	}
+
+	limit, ok := mediaSizeLimits[declared]
+	if declared == "image/webp" {
+		limit, ok = MaxStickerSize, true
+		if isAnimatedWebP(head) {
+			limit = MaxAnimatedStickerSize
+		}
+	}
+	if !ok {
+		return "", &MediaValidationError{MimeType: declared, Sniffed: sniffed, Err: ErrUnsupportedMediaType}
+	}
+	if size > limit {
+		return "", &MediaValidationError{MimeType: declared, Sniffed: sniffed, Size: size, MaxSize: limit, Err: ErrMediaTooLarge}
+	}
+	if mimeType == "" {
+		return declared, nil
+	}
+	return mimeType, nil
+}
+
+// isAnimatedWebP reports whether a WebP file has the animation flag of the
+// extended (VP8X) format set.
+func isAnimatedWebP(head []byte) bool {
+	return len(head) > 20 &&
+		bytes.Equal(head[0:4], []byte("RIFF")) &&
+		bytes.Equal(head[8:12], []byte("WEBP")) &&
+		bytes.Equal(head[12:16], []byte("VP8X")) &&
+		head[20]&0x02 != 0
+}
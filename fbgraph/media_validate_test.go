@@ -0,0 +1,169 @@
+package fbgraph
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+var (
+	jpegHead = []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	pngHead  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	webpHead = []byte("RIFF\x00\x00\x00\x00WEBPVP8 \x00\x00\x00\x00")
+	// extended WebP with the animation flag set
+	animatedWebPHead = []byte("RIFF\x00\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00\x02\x00\x00\x00")
+)
+
+func TestValidateMedia(t *testing.T) {
+	tests := []struct {
+		name     string
+		mimeType string
+		head     []byte
+		size     int64
+		want     string
+		err      error
+	}{
+		{"jpeg", "image/jpeg", jpegHead, MaxImageSize, "image/jpeg", nil},
+		{"detected", "", pngHead, 10, "image/png", nil},
+		{"large image", "image/jpeg", jpegHead, MaxImageSize + 1, "", ErrMediaTooLarge},
+		{"png declared as jpeg", "image/jpeg", pngHead, 10, "", ErrMediaTypeMismatch},
+		{"gif", "image/gif", []byte("GIF89a"), 10, "", ErrUnsupportedMediaType},
+		{"undetectable", "", []byte{0, 1, 2, 3}, 10, "", ErrUnsupportedMediaType},
+		{"video", "video/mp4", []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), MaxVideoSize, "video/mp4", nil},
+		{"document", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", []byte("PK\x03\x04"), MaxDocumentSize, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", nil},
+		{"large document", "application/pdf", []byte("%PDF-1.7"), MaxDocumentSize + 1, "", ErrMediaTooLarge},
+		{"audio with params", "audio/ogg; codecs=opus", []byte("OggS\x00"), MaxAudioSize, "audio/ogg; codecs=opus", nil},
+		{"sticker", "image/webp", webpHead, MaxStickerSize, "image/webp", nil},
+		{"large sticker", "image/webp", webpHead, MaxStickerSize + 1, "", ErrMediaTooLarge},
+		{"animated sticker", "image/webp", animatedWebPHead, MaxAnimatedStickerSize, "image/webp", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateMedia(tt.mimeType, tt.head, tt.size)
+			if !errors.Is(err, tt.err) || got != tt.want {
+				t.Fatalf("ValidateMedia = %q, %v; want %q, %v", got, err, tt.want, tt.err)
+			}
+			var ve *MediaValidationError
+			if tt.err != nil && !errors.As(err, &ve) {
+				t.Fatalf("err = %T, want *MediaValidationError", err)
+			}
+		})
+	}
+}
+
+func TestUploadMediaSendsExactLength(t *testing.T) {
+	data := append(bytes.Clone(jpegHead), bytes.Repeat([]byte{0xaa}, 2000)...)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength <= 0 || len(r.TransferEncoding) > 0 {
+			t.Errorf("content length = %d, transfer encoding = %v", r.ContentLength, r.TransferEncoding)
+		}
+		f, fh, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(f)
+		if !bytes.Equal(got, data) || fh.Header.Get("Content-Type") != "image/jpeg" || r.FormValue("messaging_product") != "whatsapp" {
+			t.Errorf("file = %d bytes, header = %v", len(got), fh.Header)
+		}
+		_, _ = io.WriteString(w, `{"id":"m1"}`)
+	}))
+	defer srv.Close()
+	c := NewClient("tok")
+	c.BaseURL = srv.URL
+
+	// no declared type nor size: both come from the reader
+	id, err := c.UploadMedia("phone1", "", bytes.NewReader(data), 0, "a.jpg")
+	if err != nil || id != "m1" {
+		t.Fatalf("UploadMedia = %q, %v", id, err)
+	}
+}
+
+func TestUploadMediaRejectsBeforeSending(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = io.WriteString(w, `{"id":"m1"}`)
+	}))
+	defer srv.Close()
+	c := NewClient("tok")
+	c.BaseURL = srv.URL
+
+	_, err := c.UploadMedia("phone1", "image/png", bytes.NewReader(jpegHead), MaxImageSize+1, "a.png")
+	if !errors.Is(err, ErrMediaTypeMismatch) {
+		t.Errorf("err = %v, want ErrMediaTypeMismatch", err)
+	}
+	_, err = c.UploadMedia("phone1", "image/jpeg", bytes.NewReader(jpegHead), MaxImageSize+1, "a.jpg")
+	if !errors.Is(err, ErrMediaTooLarge) {
+		t.Errorf("err = %v, want ErrMediaTooLarge", err)
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("server got %d request(s)", n)
+	}
+}
+
+func TestUploadMediaSmallAndUnsizedFiles(t *testing.T) {
+	var got [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		b, _ := io.ReadAll(f)
+		got = append(got, b)
+		_, _ = io.WriteString(w, `{"id":"m1"}`)
+	}))
+	defer srv.Close()
+	c := NewClient("tok")
+	c.BaseURL = srv.URL
+
+	// a 382 byte file declared with its size, read from a longer stream
+	small := append(bytes.Clone(jpegHead), bytes.Repeat([]byte{0xaa}, 382-len(jpegHead))...)
+	stream := io.MultiReader(bytes.NewReader(small), bytes.NewReader(bytes.Repeat([]byte{0xbb}, 492)))
+	if _, err := c.UploadMedia("phone1", "image/jpeg", stream, int64(len(small)), "a.jpg"); err != nil {
+		t.Fatalf("small file: %v", err)
+	}
+	// no size and no Len or Stat: buffered
+	if _, err := c.UploadMedia("phone1", "text/plain", io.NopCloser(strings.NewReader("hi")), 0, "a.txt"); err != nil {
+		t.Fatalf("unsized reader: %v", err)
+	}
+	// a file already partly read: only the rest is sent
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("skip:rest"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(5, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UploadMedia("phone1", "text/plain", f, 0, "a.txt"); err != nil {
+		t.Fatalf("partly read file: %v", err)
+	}
+	// a pipe, which stats as 0 bytes: buffered
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_, _ = pw.WriteString("piped")
+		_ = pw.Close()
+	}()
+	if _, err := c.UploadMedia("phone1", "text/plain", pr, 0, "a.txt"); err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	_ = pr.Close()
+	if len(got) != 4 || !bytes.Equal(got[0], small) || string(got[1]) != "hi" || string(got[2]) != "rest" || string(got[3]) != "piped" {
+		t.Errorf("uploaded %q", got)
+	}
+}
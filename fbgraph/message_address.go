@@ -0,0 +1,68 @@
+package fbgraph
+
+import "fmt"
+
+// Names of an NfmReply.
+const (
+	NfmReplyFlow    = "flow"
+	NfmReplyAddress = "address_message"
+)
+
+// AddressValues are the fields of an address message form. Which fields the
+// form shows depends on its country: InPinCode is for India, SgPostCode for
+// Singapore.
+type AddressValues struct {
+	Name         string `json:"name,omitempty"`
+	PhoneNumber  string `json:"phone_number,omitempty"`
+	InPinCode    string `json:"in_pin_code,omitempty"`
+	SgPostCode   string `json:"sg_post_code,omitempty"`
+	HouseNumber  string `json:"house_number,omitempty"`
+	FloorNumber  string `json:"floor_number,omitempty"`
+	TowerNumber  string `json:"tower_number,omitempty"`
+	BuildingName string `json:"building_name,omitempty"`
+	Address      string `json:"address,omitempty"`
+	LandmarkArea string `json:"landmark_area,omitempty"`
+	UnitNumber   string `json:"unit_number,omitempty"`
+	City         string `json:"city,omitempty"`
+	State        string `json:"state,omitempty"`
+}
+
+// SavedAddress is an address the user can pick instead of filling the form.
+type SavedAddress struct {
+	ID    string        `json:"id"`
+	Value AddressValues `json:"value"`
+}
+
+// AddressReply is the response_json of an address message reply.
+type AddressReply struct {
+	// SavedAddressID is set when the user picked a saved address.
+	SavedAddressID string        `json:"saved_address_id,omitempty"`
+	Values         AddressValues `json:"values"`
+}
+
+// NewAddressAction returns the action of an address message (interactive type
+// address_message), which asks the user for a delivery address. Address
+// messages are only available for India (IN) and Singapore (SG). values may
+// be nil.
+func NewAddressAction(country string, values *AddressValues, saved ...SavedAddress) *InteractiveMessageAction {
+	return &InteractiveMessageAction{
+		Name: "address_message",
+		Parameters: &InteractiveActionParameters{
+			Country:        country,
+			Values:         values,
+			SavedAddresses: saved,
+		},
+	}
+}
+
+// Address decodes the reply to an address message.
+func (r *NfmReply) Address() (*AddressReply, error) {
+	if r.Name != NfmReplyAddress {
+		return nil, fmt.Errorf("nfm_reply is a %q reply, not %q", r.Name, NfmReplyAddress)
+	}
+	out := &AddressReply{}
+	if err := r.Decode(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
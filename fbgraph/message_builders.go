@@ -0,0 +1,185 @@
+package fbgraph
+
+// The New* builders return a MessageObject with messaging_product,
+// recipient_type and type filled in, so only the content is left to set.
+// The With* methods chain on the result:
+//
+//	msg := fbgraph.NewButtons(to, "Confirma a entrega amanhã?",
+//		fbgraph.ReplyButton("sim", "Sim"),
+//		fbgraph.ReplyButton("nao", "Não"),
+//	).WithFooter("Farmácia Central").ReplyTo(wamid)
+//	if err := msg.Validate(); err != nil {
+//		return err
+//	}
+
+func newMessage(to, typ string) *MessageObject {
+	return &MessageObject{
+		MessagingProduct: "whatsapp",
+		RecipientType:    "individual",
+		To:               to,
+		Type:             typ,
+	}
+}
+
+// NewText returns a text message. Links in body get a preview when
+// previewURL is set.
+func NewText(to, body string, previewURL bool) *MessageObject {
+	m := newMessage(to, "text")
+	m.Text = &TextObject{Body: body, PreviewURL: previewURL}
+	return m
+}
+
+// NewImage returns an image message. media holds an uploaded media ID or a
+// link, and an optional caption.
+func NewImage(to string, media MediaObject) *MessageObject {
+	m := newMessage(to, "image")
+	m.Image = &media
+	return m
+}
+
+// NewVideo returns a video message.
+func NewVideo(to string, media MediaObject) *MessageObject {
+	m := newMessage(to, "video")
+	m.Video = &media
+	return m
+}
+
+// NewAudio returns an audio message. Audio takes no caption.
+func NewAudio(to string, media MediaObject) *MessageObject {
+	m := newMessage(to, "audio")
+	m.Audio = &media
+	return m
+}
+
+// NewDocument returns a document message. media.Filename sets the name, and
+// the format, the document is shown with.
+func NewDocument(to string, media MediaObject) *MessageObject {
+	m := newMessage(to, "document")
+	m.Document = &media
+	return m
+}
+
+// NewSticker returns a sticker message.
+func NewSticker(to string, media MediaObject) *MessageObject {
+	m := newMessage(to, "sticker")
+	m.Sticker = &media
+	return m
+}
+
+// NewLocation returns a location message.
+func NewLocation(to string, loc LocationObject) *MessageObject {
+	m := newMessage(to, "location")
+	m.Location = &loc
+	return m
+}
+
+// NewContacts returns a contacts message.
+func NewContacts(to string, contacts ...ContactObject) *MessageObject {
+	m := newMessage(to, "contacts")
+	m.Contacts = contacts
+	return m
+}
+
+// NewReaction returns a reaction to messageID. An empty emoji removes the
+// reaction.
+func NewReaction(to, messageID, emoji string) *MessageObject {
+	m := newMessage(to, "reaction")
+	m.Reaction = &ReactionObject{MessageID: messageID, Emoji: emoji}
+	return m
+}
+
+// NewTemplate returns a template message, e.g. with a template built by
+// TemplateSend.
+func NewTemplate(to string, tpl *TemplateObject) *MessageObject {
+	m := newMessage(to, "template")
+	m.Template = tpl
+	return m
+}
+
+// NewInteractive returns an interactive message of type t with body and
+// action. The New*Action helpers build the action of flow, cta_url, location
+// request, address, voice_call, order_details and order_status messages.
+func NewInteractive(to string, t InteractiveMessageType, body string, action *InteractiveMessageAction) *MessageObject {
+	m := newMessage(to, "interactive")
+	m.Interactive = &InteractiveMessageObject{Type: t, Action: action}
+	if body != "" {
+		m.Interactive.Body = &InteractiveTextObject{Text: body}
+	}
+	return m
+}
+
+// NewButtons returns a reply buttons message, with up to 3 buttons.
+func NewButtons(to, body string, buttons ...InteractiveButton) *MessageObject {
+	return NewInteractive(to, InteractiveMessageButton, body, &InteractiveMessageAction{Buttons: buttons})
+}
+
+// ReplyButton returns a reply button for NewButtons. id comes back in the
+// webhook when the button is tapped.
+func ReplyButton(id, title string) InteractiveButton {
+	return InteractiveButton{Type: "reply", Reply: &InteractiveReplyButton{ID: id, Title: title}}
+}
+
+// NewList returns a list message. button is the text of the button that
+// opens the list.
+func NewList(to, body, button string, sections ...InteractiveMessageSection) *MessageObject {
+	return NewInteractive(to, InteractiveMessageList, body, &InteractiveMessageAction{Button: button, Sections: sections})
+}
+
+// ListSection returns a section for NewList. title may be empty when the list
+// has a single section.
+func ListSection(title string, rows ...InteractiveMessageSectionRow) InteractiveMessageSection {
+	return InteractiveMessageSection{Title: title, Rows: rows}
+}
+
+// NewProduct returns a single product message. It takes no header.
+func NewProduct(to, body, catalogID, productRetailerID string) *MessageObject {
+	return NewInteractive(to, InteractiveMessageProduct, body, &InteractiveMessageAction{
+		CatalogID:         catalogID,
+		ProductRetailerID: productRetailerID,
+	})
+}
+
+// NewProductList returns a multi-product message. It needs a text header,
+// see WithHeaderText.
+func NewProductList(to, body, catalogID string, sections ...InteractiveMessageSection) *MessageObject {
+	return NewInteractive(to, InteractiveMessageProductList, body, &InteractiveMessageAction{
+		CatalogID: catalogID,
+		Sections:  sections,
+	})
+}
+
+// ProductSection returns a section for NewProductList.
+func ProductSection(title string, productRetailerIDs ...string) InteractiveMessageSection {
+	s := InteractiveMessageSection{Title: title}
+	for _, id := range productRetailerIDs {
+		s.ProductItems = append(s.ProductItems, InteractiveProductItem{ProductRetailerID: id})
+	}
+	return s
+}
+
+// ReplyTo quotes messageID in the message.
+func (m *MessageObject) ReplyTo(messageID string) *MessageObject {
+	m.Context = &MessageContext{MessageID: messageID}
+	return m
+}
+
+// WithHeader sets the header of an interactive message.
+func (m *MessageObject) WithHeader(h *InteractiveHeaderObject) *MessageObject {
+	if m.Interactive != nil {
+		m.Interactive.Header = h
+	}
+	return m
+}
+
+// WithHeaderText sets a text header on an interactive message.
+func (m *MessageObject) WithHeaderText(text string) *MessageObject {
+	return m.WithHeader(&InteractiveHeaderObject{Type: "text", Text: text})
+}
+
+// WithFooter sets the footer of an interactive message.
+func (m *MessageObject) WithFooter(text string) *MessageObject {
+	if m.Interactive != nil {
+		m.Interactive.Footer = &InteractiveTextObject{Text: text}
+	}
+	return m
+}
@@ -0,0 +1,89 @@
+package fbgraph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// FlowAction is what a flow message does when the user taps its button.
+type FlowAction string
+
+const (
+	// FlowActionNavigate opens the flow on FlowActionPayload.Screen.
+	FlowActionNavigate FlowAction = "navigate"
+	// FlowActionDataExchange asks the flow's endpoint for the first screen.
+	FlowActionDataExchange FlowAction = "data_exchange"
+)
+
+// FlowMode is whether a flow message opens the draft or the published version
+// of the flow.
+type FlowMode string
+
+const (
+	FlowModeDraft     FlowMode = "draft"
+	FlowModePublished FlowMode = "published"
+)
+
+// FlowMessageVersion is the flow_message_version sent by NewFlowAction.
+const FlowMessageVersion = "3"
+
+// FlowActionPayload is the first screen of a navigate flow action, and the
+// data it is opened with.
+type FlowActionPayload struct {
+	Screen string         `json:"screen"`
+	Data   map[string]any `json:"data,omitempty"`
+}
+
+// NewFlowAction returns the action of a flow message (interactive type flow),
+// with flow_message_version set:
+//
+//	msg.Interactive = &fbgraph.InteractiveMessageObject{
+//		Type:   fbgraph.InteractiveMessageFlow,
+//		Body:   &fbgraph.InteractiveTextObject{Text: "Agende sua entrega"},
+//		Action: fbgraph.NewFlowAction(fbgraph.InteractiveActionParameters{
+//			FlowID:    "1234567890",
+//			FlowToken: orderID,
+//			FlowCTA:   "Agendar",
+//			FlowActionPayload: &fbgraph.FlowActionPayload{Screen: "DELIVERY"},
+//		}),
+//	}
+func NewFlowAction(params InteractiveActionParameters) *InteractiveMessageAction {
+	params.FlowMessageVersion = FlowMessageVersion
+	return &InteractiveMessageAction{Name: "flow", Parameters: &params}
+}
+
+// NfmReply is the reply to a flow or address message (interactive type
+// nfm_reply), sent when the user completes the flow or the address form.
+type NfmReply struct {
+	// NfmReplyFlow or NfmReplyAddress.
+	Name string `json:"name"`
+	// The text shown in the chat, e.g. "Sent".
+	Body string `json:"body"`
+	// ResponseJSON is the JSON object the flow completed with: the data of its
+	// final screen, plus flow_token.
+	ResponseJSON string `json:"response_json"`
+}
+
+// Decode unmarshals the flow's response into v.
+func (r *NfmReply) Decode(v any) error {
+	if r.ResponseJSON == "" {
+		return errors.New("nfm_reply has no response_json")
+	}
+	if err := json.Unmarshal([]byte(r.ResponseJSON), v); err != nil {
+		return fmt.Errorf("decode flow response: %w", err)
+	}
+	return nil
+}
+
+// FlowToken returns the flow_token of the response, which matches the reply
+// to the flow message it answers.
+func (r *NfmReply) FlowToken() (string, error) {
+	var resp struct {
+		FlowToken string `json:"flow_token"`
+	}
+	if err := r.Decode(&resp); err != nil {
+		return "", err
+	}
+	return resp.FlowToken, nil
+}
@@ -0,0 +1,81 @@
+package fbgraph
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFlowMessageJSON(t *testing.T) {
+	msg := MessageObject{
+		MessagingProduct: "whatsapp",
+		To:               "5511987654321",
+		Type:             "interactive",
+		Interactive: &InteractiveMessageObject{
+			Type: InteractiveMessageFlow,
+			Body: &InteractiveTextObject{Text: "Agende sua entrega"},
+			Action: NewFlowAction(InteractiveActionParameters{
+				FlowID:            "1234567890",
+				FlowToken:         "order-42",
+				FlowCTA:           "Agendar",
+				FlowAction:        FlowActionNavigate,
+				FlowActionPayload: &FlowActionPayload{Screen: "DELIVERY", Data: map[string]any{"order": "42"}},
+			}),
+		},
+	}
+	b, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Interactive struct {
+			Type   string `json:"type"`
+			Action struct {
+				Name       string         `json:"name"`
+				Parameters map[string]any `json:"parameters"`
+			} `json:"action"`
+		} `json:"interactive"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	a := got.Interactive.Action
+	if got.Interactive.Type != "flow" || a.Name != "flow" {
+		t.Errorf("interactive = %s", b)
+	}
+	want := map[string]any{
+		"flow_message_version": "3",
+		"flow_token":           "order-42",
+		"flow_id":              "1234567890",
+		"flow_cta":             "Agendar",
+		"flow_action":          "navigate",
+	}
+	for k, v := range want {
+		if a.Parameters[k] != v {
+			t.Errorf("parameters[%s] = %v, want %v", k, a.Parameters[k], v)
+		}
+	}
+	if _, ok := a.Parameters["flow_name"]; ok {
+		t.Error("empty flow_name was sent")
+	}
+	payload, _ := a.Parameters["flow_action_payload"].(map[string]any)
+	if payload["screen"] != "DELIVERY" {
+		t.Errorf("flow_action_payload = %v", payload)
+	}
+}
+
+func TestNfmReplyDecode(t *testing.T) {
+	r := &NfmReply{Name: "flow", Body: "Sent", ResponseJSON: `{"flow_token":"order-42","rating":5}`}
+	token, err := r.FlowToken()
+	if err != nil || token != "order-42" {
+		t.Errorf("FlowToken() = %q, %v", token, err)
+	}
+	var resp struct {
+		Rating int `json:"rating"`
+	}
+	if err := r.Decode(&resp); err != nil || resp.Rating != 5 {
+		t.Errorf("Decode() = %+v, %v", resp, err)
+	}
+	if err := (&NfmReply{}).Decode(&resp); err == nil {
+		t.Error("empty response_json decoded")
+	}
+}
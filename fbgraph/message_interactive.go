@@ -8,6 +8,13 @@ const (
 	InteractiveMessageProduct               InteractiveMessageType = "product"
 	InteractiveMessageProductList           InteractiveMessageType = "product_list"
 	InteractiveMessageCallPermissionRequest InteractiveMessageType = "call_permission_request"
+	InteractiveMessageFlow                  InteractiveMessageType = "flow"
+	InteractiveMessageCTAURL                InteractiveMessageType = "cta_url"
+	InteractiveMessageLocationRequest       InteractiveMessageType = "location_request_message"
+	InteractiveMessageAddress               InteractiveMessageType = "address_message"
+	InteractiveMessageVoiceCall             InteractiveMessageType = "voice_call"
+	InteractiveMessageOrderDetails          InteractiveMessageType = "order_details"
+	InteractiveMessageOrderStatus           InteractiveMessageType = "order_status"
 )
 
 type InteractiveMessageObject struct {
@@ -98,6 +105,10 @@ type InteractiveMessageAction struct {
 	CatalogID string `json:"catalog_id,omitempty"`
 	// Required for action-name based interactives such as call_permission_request.
 	Name string `json:"name,omitempty"`
+	// Required for action-name based interactives that take parameters:
+	// flow, cta_url, address_message, voice_call, order_details and
+	// order_status. See the New*Action helpers.
+	Parameters *InteractiveActionParameters `json:"parameters,omitempty"`
 	// Required for Single Product Messages and Multi-Product Messages.
 	// Unique identifier of the product in a catalog.
 	//
@@ -112,6 +123,89 @@ type InteractiveMessageAction struct {
 	Sections []InteractiveMessageSection `json:"sections,omitempty"`
 }
 
+// InteractiveActionParameters are the parameters of an action-name based
+// interactive message. Only the fields of the action's kind are set.
+type InteractiveActionParameters struct {
+	// flow
+
+	// Always FlowMessageVersion.
+	FlowMessageVersion string `json:"flow_message_version,omitempty"`
+	// FlowToken identifies the flow session; it comes back in the reply.
+	FlowToken string `json:"flow_token,omitempty"`
+	// Either FlowID or FlowName is required.
+	FlowID   string `json:"flow_id,omitempty"`
+	FlowName string `json:"flow_name,omitempty"`
+	// FlowCTA is the text of the button. Maximum length: 30 characters.
+	FlowCTA string `json:"flow_cta,omitempty"`
+	// Defaults to navigate.
+	FlowAction        FlowAction         `json:"flow_action,omitempty"`
+	FlowActionPayload *FlowActionPayload `json:"flow_action_payload,omitempty"`
+	// Defaults to published.
+	Mode FlowMode `json:"mode,omitempty"`
+
+	// cta_url and voice_call
+
+	// DisplayText is the text of the button. Maximum length: 20 characters.
+	DisplayText string `json:"display_text,omitempty"`
+	// URL is opened by a cta_url button.
+	URL string `json:"url,omitempty"`
+	// TTLMinutes is how long a voice_call button can be tapped, up to 43200
+	// (30 days). 0 leaves the default, 10080 (7 days).
+	TTLMinutes int `json:"ttl_minutes,omitempty"`
+	// Payload of a voice_call button, sent back in the calls webhook of the
+	// call it starts. Maximum length: 512 characters.
+	Payload string `json:"payload,omitempty"`
+
+	// address_message
+
+	// Country is the ISO 3166 alpha-2 code of the address form, IN or SG.
+	Country string `json:"country,omitempty"`
+	// Values prefill the address form.
+	Values *AddressValues `json:"values,omitempty"`
+	// SavedAddresses are offered instead of a blank form.
+	SavedAddresses []SavedAddress `json:"saved_addresses,omitempty"`
+	// ValidationErrors, by address field, are shown on the form when
+	// resending it for a rejected address.
+	ValidationErrors map[string]string `json:"validation_errors,omitempty"`
+
+	// order_details and order_status; see NewOrderDetailsAction
+
+	ReferenceID     string                `json:"reference_id,omitempty"`
+	Type            OrderGoodsType        `json:"type,omitempty"`
+	PaymentType     string                `json:"payment_type,omitempty"`
+	PaymentSettings []OrderPaymentSetting `json:"payment_settings,omitempty"`
+	Currency        string                `json:"currency,omitempty"`
+	TotalAmount     *OrderAmount          `json:"total_amount,omitempty"`
+	Order           *OrderObject          `json:"order,omitempty"`
+}
+
+// NewCTAURLAction returns the action of a call-to-action URL button message
+// (interactive type cta_url), which opens url without showing it in the chat.
+func NewCTAURLAction(displayText, url string) *InteractiveMessageAction {
+	return &InteractiveMessageAction{
+		Name:       "cta_url",
+		Parameters: &InteractiveActionParameters{DisplayText: displayText, URL: url},
+	}
+}
+
+// NewLocationRequestAction returns the action of a location request message
+// (interactive type location_request_message). The user answers with a
+// regular location message, whose context points to the request.
+func NewLocationRequestAction() *InteractiveMessageAction {
+	return &InteractiveMessageAction{Name: "send_location"}
+}
+
+// NewVoiceCallAction returns the action of a call button message (interactive
+// type voice_call), which starts a WhatsApp call to the business. ttlMinutes
+// may be 0 for the default. The call is reported by the calls webhook, not as
+// a message reply.
+func NewVoiceCallAction(displayText string, ttlMinutes int, payload string) *InteractiveMessageAction {
+	return &InteractiveMessageAction{
+		Name:       "voice_call",
+		Parameters: &InteractiveActionParameters{DisplayText: displayText, TTLMinutes: ttlMinutes, Payload: payload},
+	}
+}
+
 type InteractiveButton struct {
 	Type     string                     `json:"type"` // type: [WABA] only supported type is reply (for Reply Button); Use pp_action for PP Action Button.
 	Reply    *InteractiveReplyButton    `json:"reply,omitempty"`
@@ -142,12 +236,19 @@ type InteractiveMessageSection struct {
 	// Maximum length: 24 characters.
 	Title string `json:"title,omitempty"`
 
-	//TODO: product_items at https://developers.facebook.com/docs/whatsapp/cloud-api/reference/messages
+	// Required for Multi-Product Messages. Maximum of 30 items across all
+	// sections.
+	ProductItems []InteractiveProductItem `json:"product_items,omitempty"`
 
 	// Required for List Messages.
 	Rows []InteractiveMessageSectionRow `json:"rows,omitempty"`
 }
 
+// InteractiveProductItem is a product of a Multi-Product Message section.
+type InteractiveProductItem struct {
+	ProductRetailerID string `json:"product_retailer_id"`
+}
+
 // Each row must have a title (Maximum length: 24 characters) and an ID
 // (Maximum length: 200 characters). You can add a description (Maximum
 // length: 72 characters), but it is optional.
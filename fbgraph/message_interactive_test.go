@@ -0,0 +1,65 @@
+package fbgraph
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInteractiveActionsJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		action *InteractiveMessageAction
+		want   string
+	}{
+		{
+			name:   "cta_url",
+			action: NewCTAURLAction("Ver pedido", "https://example.com/pedidos/42"),
+			want:   `{"name":"cta_url","parameters":{"display_text":"Ver pedido","url":"https://example.com/pedidos/42"}}`,
+		},
+		{
+			name:   "location_request_message",
+			action: NewLocationRequestAction(),
+			want:   `{"name":"send_location"}`,
+		},
+		{
+			name:   "voice_call",
+			action: NewVoiceCallAction("Ligar", 60, "pedido-42"),
+			want:   `{"name":"voice_call","parameters":{"display_text":"Ligar","ttl_minutes":60,"payload":"pedido-42"}}`,
+		},
+		{
+			name: "address_message",
+			action: NewAddressAction("IN", &AddressValues{Name: "Asha", InPinCode: "400063"},
+				SavedAddress{ID: "home", Value: AddressValues{City: "Mumbai"}}),
+			want: `{"name":"address_message","parameters":{"country":"IN","values":{"name":"Asha","in_pin_code":"400063"},"saved_addresses":[{"id":"home","value":{"city":"Mumbai"}}]}}`,
+		},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.action)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, b, tt.want)
+		}
+	}
+}
+
+func TestNfmReplyAddress(t *testing.T) {
+	r := &NfmReply{
+		Name:         NfmReplyAddress,
+		Body:         "Address sent",
+		ResponseJSON: `{"saved_address_id":"home","values":{"name":"Asha","in_pin_code":"400063","city":"Mumbai"}}`,
+	}
+	a, err := r.Address()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.SavedAddressID != "home" || a.Values.InPinCode != "400063" || a.Values.City != "Mumbai" {
+		t.Errorf("Address() = %+v", a)
+	}
+
+	r.Name = NfmReplyFlow
+	if _, err := r.Address(); err == nil {
+		t.Error("a flow reply decoded as an address")
+	}
+}
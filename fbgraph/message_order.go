@@ -0,0 +1,335 @@
+package fbgraph
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// OrderGoodsType is the kind of goods of an order_details message.
+type OrderGoodsType string
+
+const (
+	OrderDigitalGoods  OrderGoodsType = "digital-goods"
+	OrderPhysicalGoods OrderGoodsType = "physical-goods"
+)
+
+// OrderStatusValue is the status of an order, set by order_details (always
+// pending) and order_status messages.
+type OrderStatusValue string
+
+const (
+	OrderStatusPending          OrderStatusValue = "pending"
+	OrderStatusProcessing       OrderStatusValue = "processing"
+	OrderStatusPartiallyShipped OrderStatusValue = "partially_shipped"
+	OrderStatusShipped          OrderStatusValue = "shipped"
+	OrderStatusCompleted        OrderStatusValue = "completed"
+	OrderStatusCanceled         OrderStatusValue = "canceled"
+)
+
+// PaymentSettingType is the kind of an OrderPaymentSetting.
+type PaymentSettingType string
+
+const (
+	PaymentSettingPIXDynamicCode PaymentSettingType = "pix_dynamic_code"
+	PaymentSettingBoleto         PaymentSettingType = "boleto"
+	PaymentSettingPaymentLink    PaymentSettingType = "payment_link"
+)
+
+// PIXKeyType is the type of a PIX key.
+type PIXKeyType string
+
+const (
+	PIXKeyCPF   PIXKeyType = "CPF"
+	PIXKeyCNPJ  PIXKeyType = "CNPJ"
+	PIXKeyEmail PIXKeyType = "EMAIL"
+	PIXKeyPhone PIXKeyType = "PHONE"
+	PIXKeyEVP   PIXKeyType = "EVP"
+)
+
+// OrderAmount is an amount of money: Value / Offset. For BRL, Offset is 100
+// and Value is in centavos (R$ 12,34 is {1234, 100}).
+type OrderAmount struct {
+	Value  int64 `json:"value"`
+	Offset int   `json:"offset"`
+	// Description is shown for tax, shipping and discount. Optional.
+	Description string `json:"description,omitempty"`
+}
+
+// BRL returns an amount of centavos.
+func BRL(centavos int64) *OrderAmount {
+	return &OrderAmount{Value: centavos, Offset: 100}
+}
+
+// OrderItem is a line of an order.
+type OrderItem struct {
+	RetailerID string       `json:"retailer_id"`
+	Name       string       `json:"name"`
+	Amount     *OrderAmount `json:"amount"`
+	Quantity   int          `json:"quantity"`
+	// SaleAmount is the discounted price of one unit. Optional.
+	SaleAmount *OrderAmount `json:"sale_amount,omitempty"`
+}
+
+// OrderObject is the order of an order_details message, or the status change
+// of an order_status message.
+type OrderObject struct {
+	Status OrderStatusValue `json:"status"`
+	// Description is the reason of an order_status update, e.g. the tracking
+	// code. Maximum length: 120 characters.
+	Description string `json:"description,omitempty"`
+	// CatalogID is set when the items come from the business catalog.
+	CatalogID string       `json:"catalog_id,omitempty"`
+	Items     []OrderItem  `json:"items,omitempty"`
+	Subtotal  *OrderAmount `json:"subtotal,omitempty"`
+	Tax       *OrderAmount `json:"tax,omitempty"`
+	Shipping  *OrderAmount `json:"shipping,omitempty"`
+	Discount  *OrderAmount `json:"discount,omitempty"`
+}
+
+// OrderPaymentSetting is a way to pay an order_details message. Set the field
+// of Type.
+type OrderPaymentSetting struct {
+	Type           PaymentSettingType `json:"type"`
+	PIXDynamicCode *PIXDynamicCode    `json:"pix_dynamic_code,omitempty"`
+	Boleto         *Boleto            `json:"boleto,omitempty"`
+	PaymentLink    *PaymentLink       `json:"payment_link,omitempty"`
+}
+
+// PIXDynamicCode is a PIX copy-and-paste ("copia e cola") code.
+type PIXDynamicCode struct {
+	// Code is the full BR Code (EMV) of the charge.
+	Code         string     `json:"code"`
+	MerchantName string     `json:"merchant_name"`
+	Key          string     `json:"key"`
+	KeyType      PIXKeyType `json:"key_type"`
+}
+
+// Boleto is a boleto bancário.
+type Boleto struct {
+	// DigitableLine is the 47 or 48 digit "linha digitável", digits only.
+	DigitableLine string `json:"digitable_line"`
+}
+
+// PaymentLink is a checkout page of a payment provider.
+type PaymentLink struct {
+	URI string `json:"uri"`
+}
+
+func (ps OrderPaymentSetting) validate() error {
+	switch ps.Type {
+	case PaymentSettingPIXDynamicCode:
+		p := ps.PIXDynamicCode
+		if p == nil {
+			return errors.New("pix_dynamic_code is required")
+		}
+		if p.Code == "" || p.Key == "" || p.MerchantName == "" {
+			return errors.New("pix_dynamic_code needs a code, a key and a merchant name")
+		}
+		switch p.KeyType {
+		case PIXKeyCPF, PIXKeyCNPJ, PIXKeyEmail, PIXKeyPhone, PIXKeyEVP:
+		default:
+			return fmt.Errorf("pix key type %q is not CPF, CNPJ, EMAIL, PHONE or EVP", p.KeyType)
+		}
+	case PaymentSettingBoleto:
+		if ps.Boleto == nil {
+			return errors.New("boleto is required")
+		}
+		if !isDigitableLine(ps.Boleto.DigitableLine) {
+			return fmt.Errorf("boleto digitable line %q is not 47 or 48 digits", ps.Boleto.DigitableLine)
+		}
+	case PaymentSettingPaymentLink:
+		if ps.PaymentLink == nil || ps.PaymentLink.URI == "" {
+			return errors.New("payment_link needs a uri")
+		}
+	default:
+		return fmt.Errorf("unknown payment setting type %q", ps.Type)
+	}
+	return nil
+}
+
+func isDigitableLine(s string) bool {
+	if len(s) != 47 && len(s) != 48 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// PIXPayment returns a PIX dynamic code payment setting.
+func PIXPayment(code, merchantName, key string, keyType PIXKeyType) OrderPaymentSetting {
+	return OrderPaymentSetting{
+		Type:           PaymentSettingPIXDynamicCode,
+		PIXDynamicCode: &PIXDynamicCode{Code: code, MerchantName: merchantName, Key: key, KeyType: keyType},
+	}
+}
+
+// BoletoPayment returns a boleto payment setting.
+func BoletoPayment(digitableLine string) OrderPaymentSetting {
+	return OrderPaymentSetting{Type: PaymentSettingBoleto, Boleto: &Boleto{DigitableLine: digitableLine}}
+}
+
+// PaymentLinkPayment returns a payment link setting.
+func PaymentLinkPayment(uri string) OrderPaymentSetting {
+	return OrderPaymentSetting{Type: PaymentSettingPaymentLink, PaymentLink: &PaymentLink{URI: uri}}
+}
+
+// OrderDetails are the parameters of an order_details message.
+type OrderDetails struct {
+	// ReferenceID identifies the order in order_status messages and payment
+	// webhooks. Unique per business; maximum length: 35 characters.
+	ReferenceID     string                `json:"reference_id"`
+	Type            OrderGoodsType        `json:"type"`
+	PaymentSettings []OrderPaymentSetting `json:"payment_settings"`
+	// Currency defaults to BRL.
+	Currency string `json:"currency"`
+	// TotalAmount is checked against the order by Validate:
+	// subtotal + tax + shipping - discount.
+	TotalAmount *OrderAmount `json:"total_amount"`
+	// Order.Status is always pending.
+	Order OrderObject `json:"order"`
+}
+
+// Validate checks the order as Meta does on send: the goods type, a BRL
+// currency, the payment settings, and that every amount has the offset of
+// TotalAmount and the amounts add up, as Meta rejects an order whose total or
+// subtotal differ from its items.
+func (d *OrderDetails) Validate() error {
+	v := &messageValidator{}
+	d.validate(v, "")
+	if len(v.issues) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(v.issues))
+	for i, vi := range v.issues {
+		msgs[i] = vi.Error()
+	}
+	return errors.New("order_details: " + strings.Join(msgs, "; "))
+}
+
+// validate adds the issues of d to v, under path.
+func (d *OrderDetails) validate(v *messageValidator, path string) {
+	field := func(name string) string {
+		if path == "" {
+			return name
+		}
+		return path + "." + name
+	}
+	n := len(v.issues)
+	if d.ReferenceID == "" || len(d.ReferenceID) > 35 {
+		v.add(field("reference_id"), "must be 1 to 35 characters")
+	}
+	if d.Type != OrderDigitalGoods && d.Type != OrderPhysicalGoods {
+		v.add(field("type"), "must be %q or %q", OrderDigitalGoods, OrderPhysicalGoods)
+	}
+	if d.Currency != "" && d.Currency != "BRL" {
+		v.add(field("currency"), "must be BRL for payment_type br")
+	}
+	if len(d.PaymentSettings) == 0 {
+		v.add(field("payment_settings"), "must have at least one setting")
+	}
+	for i, ps := range d.PaymentSettings {
+		if err := ps.validate(); err != nil {
+			v.add(fmt.Sprintf("%s[%d]", field("payment_settings"), i), "%v", err)
+		}
+	}
+	if len(d.Order.Items) == 0 {
+		v.add(field("order.items"), "must have at least one item")
+	}
+	if d.TotalAmount == nil {
+		v.add(field("total_amount"), "is required")
+	}
+	if d.Order.Subtotal == nil {
+		v.add(field("order.subtotal"), "is required")
+	}
+	if len(v.issues) > n {
+		return
+	}
+
+	// amounts of different offsets cannot be added up
+	offset := d.TotalAmount.Offset
+	checkOffset := func(name string, a *OrderAmount) {
+		if a != nil && a.Offset != offset {
+			v.add(field(name)+".offset", "is %d, total_amount offset is %d", a.Offset, offset)
+		}
+	}
+	var subtotal int64
+	for i, it := range d.Order.Items {
+		ipath := fmt.Sprintf("order.items[%d]", i)
+		price := it.Amount
+		if it.SaleAmount != nil {
+			price = it.SaleAmount
+		}
+		if price == nil || it.Quantity <= 0 {
+			v.add(field(ipath), "needs an amount and a positive quantity")
+			continue
+		}
+		checkOffset(ipath+".amount", it.Amount)
+		checkOffset(ipath+".sale_amount", it.SaleAmount)
+		subtotal += price.Value * int64(it.Quantity)
+	}
+	checkOffset("order.subtotal", d.Order.Subtotal)
+	checkOffset("order.tax", d.Order.Tax)
+	checkOffset("order.shipping", d.Order.Shipping)
+	checkOffset("order.discount", d.Order.Discount)
+	if len(v.issues) > n {
+		return
+	}
+
+	if subtotal != d.Order.Subtotal.Value {
+		v.add(field("order.subtotal"), "is %d, the items add up to %d", d.Order.Subtotal.Value, subtotal)
+		return
+	}
+	total := subtotal
+	for _, a := range []*OrderAmount{d.Order.Tax, d.Order.Shipping} {
+		if a != nil {
+			total += a.Value
+		}
+	}
+	if d.Order.Discount != nil {
+		total -= d.Order.Discount.Value
+	}
+	if total != d.TotalAmount.Value {
+		v.add(field("total_amount"), "is %d, the order adds up to %d", d.TotalAmount.Value, total)
+	}
+}
+
+// NewOrderDetailsAction returns the action of an order_details message, the
+// review-and-pay card of an order. Brazilian orders (payment_type br) are
+// paid outside WhatsApp, with PIX, boleto or a payment link; follow them up
+// with order_status messages.
+func NewOrderDetailsAction(d OrderDetails) *InteractiveMessageAction {
+	if d.Currency == "" {
+		d.Currency = "BRL"
+	}
+	d.Order.Status = OrderStatusPending
+	return &InteractiveMessageAction{
+		Name: "review_and_pay",
+		Parameters: &InteractiveActionParameters{
+			ReferenceID:     d.ReferenceID,
+			Type:            d.Type,
+			PaymentType:     "br",
+			PaymentSettings: d.PaymentSettings,
+			Currency:        d.Currency,
+			TotalAmount:     d.TotalAmount,
+			Order:           &d.Order,
+		},
+	}
+}
+
+// NewOrderStatusAction returns the action of an order_status message, which
+// updates the order of an earlier order_details message. description is
+// optional.
+func NewOrderStatusAction(referenceID string, status OrderStatusValue, description string) *InteractiveMessageAction {
+	return &InteractiveMessageAction{
+		Name: "review_order",
+		Parameters: &InteractiveActionParameters{
+			ReferenceID: referenceID,
+			Order:       &OrderObject{Status: status, Description: description},
+		},
+	}
+}
@@ -0,0 +1,159 @@
+package fbgraph
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testOrderDetails() OrderDetails {
+	return OrderDetails{
+		ReferenceID: "pedido-42",
+		Type:        OrderPhysicalGoods,
+		PaymentSettings: []OrderPaymentSetting{
+			PIXPayment("00020101021226...6304ABCD", "Farmácia Central", "12345678000199", PIXKeyCNPJ),
+			BoletoPayment("23793381286000000000300000000400184340000010000"),
+		},
+		TotalAmount: BRL(2490),
+		Order: OrderObject{
+			Items: []OrderItem{
+				{RetailerID: "dipirona", Name: "Dipirona 500mg", Amount: BRL(1200), Quantity: 2},
+			},
+			Subtotal: BRL(2400),
+			Shipping: BRL(590),
+			Discount: BRL(500),
+		},
+	}
+}
+
+func TestOrderDetailsAction(t *testing.T) {
+	d := testOrderDetails()
+	if err := d.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(NewOrderDetailsAction(d))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"review_and_pay","parameters":{"reference_id":"pedido-42","type":"physical-goods","payment_type":"br",` +
+		`"payment_settings":[{"type":"pix_dynamic_code","pix_dynamic_code":{"code":"00020101021226...6304ABCD","merchant_name":"Farmácia Central","key":"12345678000199","key_type":"CNPJ"}},` +
+		`{"type":"boleto","boleto":{"digitable_line":"23793381286000000000300000000400184340000010000"}}],` +
+		`"currency":"BRL","total_amount":{"value":2490,"offset":100},` +
+		`"order":{"status":"pending","items":[{"retailer_id":"dipirona","name":"Dipirona 500mg","amount":{"value":1200,"offset":100},"quantity":2}],` +
+		`"subtotal":{"value":2400,"offset":100},"shipping":{"value":590,"offset":100},"discount":{"value":500,"offset":100}}}}`
+	if string(b) != want {
+		t.Errorf("\n got %s\nwant %s", b, want)
+	}
+
+	msg := NewInteractive("5511999999999", InteractiveMessageOrderDetails, "Seu pedido", NewOrderDetailsAction(d))
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestOrderDetailsValidate(t *testing.T) {
+	d := testOrderDetails()
+	d.TotalAmount = BRL(2990)
+	if err := d.Validate(); err == nil || !strings.Contains(err.Error(), "adds up to 2490") {
+		t.Errorf("mismatched total: %v", err)
+	}
+
+	d = testOrderDetails()
+	d.Order.Items[0].SaleAmount = BRL(1000)
+	if err := d.Validate(); err == nil || !strings.Contains(err.Error(), "items add up to 2000") {
+		t.Errorf("sale amount: %v", err)
+	}
+
+	msg := NewInteractive("5511999999999", InteractiveMessageOrderDetails, "Seu pedido", NewOrderDetailsAction(d))
+	if err := msg.Validate(); err == nil {
+		t.Error("message with a bad order validated")
+	}
+
+	orders := []struct {
+		name, want string
+		edit       func(*OrderDetails)
+	}{
+		{"no goods type", "type", func(d *OrderDetails) { d.Type = "" }},
+		{"shipping offset", "order.shipping.offset", func(d *OrderDetails) { d.Order.Shipping = &OrderAmount{Value: 5900, Offset: 1000} }},
+		{"subtotal offset", "order.subtotal.offset", func(d *OrderDetails) { d.Order.Subtotal.Offset = 1000 }},
+		{"sale amount offset", "order.items[0].sale_amount.offset", func(d *OrderDetails) { d.Order.Items[0].SaleAmount = &OrderAmount{Value: 12000, Offset: 1000} }},
+		{"usd", "currency", func(d *OrderDetails) { d.Currency = "USD" }},
+	}
+	for _, tt := range orders {
+		d := testOrderDetails()
+		tt.edit(&d)
+		if err := d.Validate(); err == nil || !strings.Contains(err.Error(), tt.want+": ") {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+
+	params := []struct {
+		name, want string
+		edit       func(*InteractiveActionParameters)
+	}{
+		{"no goods type", "parameters.type", func(p *InteractiveActionParameters) { p.Type = "" }},
+		{"usd", "parameters.currency", func(p *InteractiveActionParameters) { p.Currency = "USD" }},
+		{"no payment type", "parameters.payment_type", func(p *InteractiveActionParameters) { p.PaymentType = "" }},
+		{"upi payment type", "parameters.payment_type", func(p *InteractiveActionParameters) { p.PaymentType = "upi" }},
+		{"shipped order", "parameters.order.status", func(p *InteractiveActionParameters) { p.Order.Status = OrderStatusShipped }},
+		{"no order", "parameters.order", func(p *InteractiveActionParameters) { p.Order = nil }},
+		{"shipping offset", "parameters.order.shipping.offset", func(p *InteractiveActionParameters) { p.Order.Shipping = &OrderAmount{Value: 5900, Offset: 1000} }},
+	}
+	for _, tt := range params {
+		a := NewOrderDetailsAction(testOrderDetails())
+		tt.edit(a.Parameters)
+		msg := NewInteractive("5511999999999", InteractiveMessageOrderDetails, "Seu pedido", a)
+		var verr *MessageValidationError
+		if !errors.As(msg.Validate(), &verr) || !slices.ContainsFunc(verr.Issues, func(vi ValidationIssue) bool {
+			return vi.Path == "interactive.action."+tt.want
+		}) {
+			t.Errorf("%s: %v", tt.name, verr)
+		}
+	}
+
+	settings := []struct {
+		name string
+		ps   OrderPaymentSetting
+	}{
+		{"pix without its field", OrderPaymentSetting{Type: PaymentSettingPIXDynamicCode}},
+		{"pix without a code", PIXPayment("", "Farmácia Central", "12345678000199", PIXKeyCNPJ)},
+		{"pix without a key", PIXPayment("000201...", "Farmácia Central", "", PIXKeyCNPJ)},
+		{"boleto barcode", BoletoPayment("34191790010104351004791020150008291070026000")},
+		{"formatted boleto", BoletoPayment("23793.38128 60000.000003 00000.000400 1 84340000010000")},
+		{"boleto without its field", OrderPaymentSetting{Type: PaymentSettingBoleto}},
+		{"empty link", PaymentLinkPayment("")},
+		{"unknown type", OrderPaymentSetting{Type: "card"}},
+	}
+	for _, tt := range settings {
+		d := testOrderDetails()
+		d.PaymentSettings = []OrderPaymentSetting{tt.ps}
+		if err := d.Validate(); err == nil || !strings.Contains(err.Error(), "payment_settings[0]") {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+}
+
+func TestOrderStatusAction(t *testing.T) {
+	a := NewOrderStatusAction("pedido-42", OrderStatusShipped, "Rastreio BR123456789")
+	b, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"review_order","parameters":{"reference_id":"pedido-42","order":{"status":"shipped","description":"Rastreio BR123456789"}}}`
+	if string(b) != want {
+		t.Errorf("\n got %s\nwant %s", b, want)
+	}
+
+	msg := NewInteractive("5511999999999", InteractiveMessageOrderStatus, "Pedido enviado", a)
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	for _, status := range []OrderStatusValue{OrderStatusPending, "delivered", ""} {
+		msg.Interactive.Action = NewOrderStatusAction("pedido-42", status, "")
+		if err := msg.Validate(); err == nil {
+			t.Errorf("order_status %q validated", status)
+		}
+	}
+}
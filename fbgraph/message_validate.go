@@ -0,0 +1,448 @@
+package fbgraph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Message limits enforced by Meta on send.
+const (
+	MaxTextBodyLength               = 4096
+	MaxMediaCaptionLength           = 1024
+	MaxInteractiveBodyLength        = 1024
+	MaxInteractiveHeaderLength      = 60
+	MaxInteractiveFooterLength      = 60
+	MaxReplyButtons                 = 3
+	MaxReplyButtonTitleLength       = 20
+	MaxReplyButtonIDLength          = 256
+	MaxListButtonLength             = 20
+	MaxListSections                 = 10
+	MaxListRows                     = 10
+	MaxListSectionTitleLength       = 24
+	MaxListRowTitleLength           = 24
+	MaxListRowIDLength              = 200
+	MaxListRowDescriptionLength     = 72
+	MaxProductListItems             = 30
+	MaxFlowCTALength                = 30
+	MaxActionDisplayTextLength      = 20
+	MaxVoiceCallTTLMinutes          = 43200
+	MaxVoiceCallPayloadLength       = 512
+	MaxOrderStatusDescriptionLength = 120
+)
+
+// MessageValidationError is returned by (*MessageObject).Validate with every
+// problem found in the message.
+type MessageValidationError struct {
+	Issues []ValidationIssue
+}
+
+func (e *MessageValidationError) Error() string {
+	msgs := make([]string, len(e.Issues))
+	for i, vi := range e.Issues {
+		msgs[i] = vi.Error()
+	}
+	return "invalid message: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks the message against the rules Meta applies on send, without
+// calling the API: Type must match the one content field set, and text,
+// media, buttons, lists and the other interactive types must be within their
+// documented limits. It returns a *MessageValidationError, or nil.
+//
+// Clients with ValidateMessages set call it before every send.
+func (m *MessageObject) Validate() error {
+	if m == nil {
+		return &MessageValidationError{Issues: []ValidationIssue{{Message: "message is nil"}}}
+	}
+	v := &messageValidator{}
+	if m.MessagingProduct != "whatsapp" {
+		v.add("messaging_product", "must be %q", "whatsapp")
+	}
+	if m.To == "" && m.Recipient == "" {
+		v.add("to", "is required")
+	}
+	if m.RecipientType != "" && m.RecipientType != "individual" {
+		v.add("recipient_type", "must be individual")
+	}
+	if m.Context != nil && m.Context.MessageID == "" {
+		v.add("context.message_id", "is required")
+	}
+
+	fields := map[string]bool{
+		"text":        m.Text != nil,
+		"template":    m.Template != nil,
+		"interactive": m.Interactive != nil,
+		"image":       m.Image != nil,
+		"audio":       m.Audio != nil,
+		"document":    m.Document != nil,
+		"video":       m.Video != nil,
+		"sticker":     m.Sticker != nil,
+		"contacts":    m.Contacts != nil,
+		"location":    m.Location != nil,
+		"reaction":    m.Reaction != nil,
+	}
+	if m.Type == "" {
+		v.add("type", "is required")
+	} else if _, ok := fields[m.Type]; !ok {
+		v.add("type", "%q is not a message type", m.Type)
+	} else if !fields[m.Type] {
+		v.add(m.Type, "is required for type %s", m.Type)
+	}
+	for _, name := range sortedKeys(fields) {
+		if fields[name] && name != m.Type {
+			v.add(name, "is set but type is %q", m.Type)
+		}
+	}
+
+	switch {
+	case m.Type == "text" && m.Text != nil:
+		v.text("text.body", m.Text.Body, MaxTextBodyLength, true)
+	case m.Type == "template" && m.Template != nil:
+		if m.Template.Name == "" {
+			v.add("template.name", "is required")
+		}
+		if m.Template.Language == nil || m.Template.Language.Code == "" {
+			v.add("template.language.code", "is required")
+		}
+	case m.Type == "interactive" && m.Interactive != nil:
+		v.interactive("interactive", m.Interactive)
+	case m.Type == "image":
+		v.media("image", m.Image, true, false)
+	case m.Type == "video":
+		v.media("video", m.Video, true, false)
+	case m.Type == "document":
+		v.media("document", m.Document, true, true)
+	case m.Type == "audio":
+		v.media("audio", m.Audio, false, false)
+	case m.Type == "sticker":
+		v.media("sticker", m.Sticker, false, false)
+	case m.Type == "contacts":
+		if len(m.Contacts) == 0 {
+			v.add("contacts", "must have at least one contact")
+		}
+		for i, c := range m.Contacts {
+			if c.Name.FormattedName == "" {
+				v.add(fmt.Sprintf("contacts[%d].name.formatted_name", i), "is required")
+			}
+		}
+	case m.Type == "location" && m.Location != nil:
+		if m.Location.Latitude == "" || m.Location.Longitude == "" {
+			v.add("location", "latitude and longitude are required")
+		}
+	case m.Type == "reaction" && m.Reaction != nil:
+		if m.Reaction.MessageID == "" {
+			v.add("reaction.message_id", "is required")
+		}
+	}
+
+	if len(v.issues) > 0 {
+		return &MessageValidationError{Issues: v.issues}
+	}
+	return nil
+}
+
+type messageValidator struct {
+	issues []ValidationIssue
+}
+
+func (v *messageValidator) add(path, format string, args ...any) {
+	v.issues = append(v.issues, ValidationIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *messageValidator) text(path, s string, maxLen int, required bool) {
+	if required && strings.TrimSpace(s) == "" {
+		v.add(path, "is required")
+	}
+	if n := utf8.RuneCountInString(s); n > maxLen {
+		v.add(path, "is %d characters long, the limit is %d", n, maxLen)
+	}
+}
+
+func (v *messageValidator) media(path string, mo *MediaObject, caption, filename bool) {
+	if mo == nil {
+		return
+	}
+	if mo.ID == "" && mo.Link == "" {
+		v.add(path, "id or link is required")
+	}
+	if mo.Caption != "" && !caption {
+		v.add(path+".caption", "is not allowed on %s", path)
+	}
+	v.text(path+".caption", mo.Caption, MaxMediaCaptionLength, false)
+	if mo.Filename != "" && !filename {
+		v.add(path+".filename", "is only allowed on documents")
+	}
+}
+
+func (v *messageValidator) interactive(path string, im *InteractiveMessageObject) {
+	body := im.Body != nil && im.Body.Text != ""
+	if im.Body != nil {
+		v.text(path+".body.text", im.Body.Text, MaxInteractiveBodyLength, false)
+	}
+	if im.Footer != nil {
+		v.text(path+".footer.text", im.Footer.Text, MaxInteractiveFooterLength, false)
+	}
+	if im.Header != nil {
+		v.header(path+".header", im.Type, im.Header)
+	}
+	if !body && im.Type != InteractiveMessageProduct && im.Type != InteractiveMessageCallPermissionRequest {
+		v.add(path+".body", "is required for type %s", im.Type)
+	}
+
+	a := im.Action
+	if a == nil {
+		v.add(path+".action", "is required")
+		return
+	}
+	apath := path + ".action"
+	switch im.Type {
+	case InteractiveMessageButton:
+		v.buttons(apath+".buttons", a.Buttons)
+	case InteractiveMessageList:
+		v.list(apath, a)
+	case InteractiveMessageProduct:
+		if im.Header != nil {
+			v.add(path+".header", "is not allowed on type product")
+		}
+		if a.CatalogID == "" {
+			v.add(apath+".catalog_id", "is required")
+		}
+		if a.ProductRetailerID == "" {
+			v.add(apath+".product_retailer_id", "is required")
+		}
+	case InteractiveMessageProductList:
+		if im.Header == nil {
+			v.add(path+".header", "is required for type product_list")
+		}
+		if a.CatalogID == "" {
+			v.add(apath+".catalog_id", "is required")
+		}
+		v.productList(apath, a)
+	case InteractiveMessageCallPermissionRequest:
+		v.actionName(apath, a, "call_permission_request")
+	case InteractiveMessageFlow:
+		v.actionName(apath, a, "flow")
+		v.flow(apath+".parameters", a.Parameters)
+	case InteractiveMessageCTAURL:
+		v.actionName(apath, a, "cta_url")
+		if p := v.parameters(apath, a); p != nil {
+			v.text(apath+".parameters.display_text", p.DisplayText, MaxActionDisplayTextLength, true)
+			if !strings.HasPrefix(p.URL, "https://") && !strings.HasPrefix(p.URL, "http://") {
+				v.add(apath+".parameters.url", "must be an http or https URL")
+			}
+		}
+	case InteractiveMessageLocationRequest:
+		v.actionName(apath, a, "send_location")
+	case InteractiveMessageAddress:
+		v.actionName(apath, a, "address_message")
+		if p := v.parameters(apath, a); p != nil && p.Country != "IN" && p.Country != "SG" {
+			v.add(apath+".parameters.country", "must be IN or SG")
+		}
+	case InteractiveMessageVoiceCall:
+		v.actionName(apath, a, "voice_call")
+		if p := v.parameters(apath, a); p != nil {
+			v.text(apath+".parameters.display_text", p.DisplayText, MaxActionDisplayTextLength, true)
+			if p.TTLMinutes < 0 || p.TTLMinutes > MaxVoiceCallTTLMinutes {
+				v.add(apath+".parameters.ttl_minutes", "must be 0 (default) to %d", MaxVoiceCallTTLMinutes)
+			}
+			v.text(apath+".parameters.payload", p.Payload, MaxVoiceCallPayloadLength, false)
+		}
+	case InteractiveMessageOrderDetails:
+		v.actionName(apath, a, "review_and_pay")
+		if p := v.parameters(apath, a); p != nil {
+			ppath := apath + ".parameters"
+			if p.PaymentType != "br" {
+				v.add(ppath+".payment_type", "must be br")
+			}
+			d := OrderDetails{
+				ReferenceID:     p.ReferenceID,
+				Type:            p.Type,
+				PaymentSettings: p.PaymentSettings,
+				Currency:        p.Currency,
+				TotalAmount:     p.TotalAmount,
+			}
+			if p.Order == nil {
+				v.add(ppath+".order", "is required")
+			} else {
+				if p.Order.Status != OrderStatusPending {
+					v.add(ppath+".order.status", "must be %q", OrderStatusPending)
+				}
+				d.Order = *p.Order
+			}
+			d.validate(v, ppath)
+		}
+	case InteractiveMessageOrderStatus:
+		v.actionName(apath, a, "review_order")
+		if p := v.parameters(apath, a); p != nil {
+			if p.ReferenceID == "" {
+				v.add(apath+".parameters.reference_id", "is required")
+			}
+			if p.Order == nil {
+				v.add(apath+".parameters.order", "is required")
+			} else if !slices.Contains(orderStatusUpdates, p.Order.Status) {
+				v.add(apath+".parameters.order.status", "%q is not one of %v", p.Order.Status, orderStatusUpdates)
+			} else {
+				v.text(apath+".parameters.order.description", p.Order.Description, MaxOrderStatusDescriptionLength, false)
+			}
+		}
+	case "":
+		v.add(path+".type", "is required")
+	default:
+		v.add(path+".type", "%q is not an interactive type", im.Type)
+	}
+}
+
+func (v *messageValidator) header(path string, t InteractiveMessageType, h *InteractiveHeaderObject) {
+	set := map[string]bool{
+		"text":     h.Text != "",
+		"image":    h.Image != nil,
+		"video":    h.Video != nil,
+		"document": h.Document != nil,
+	}
+	if !set[h.Type] {
+		v.add(path, "type %q must have its content set", h.Type)
+	}
+	if h.Type != "text" && (t == InteractiveMessageList || t == InteractiveMessageProductList) {
+		v.add(path+".type", "must be text for type %s", t)
+	}
+	v.text(path+".text", h.Text, MaxInteractiveHeaderLength, false)
+}
+
+func (v *messageValidator) buttons(path string, buttons []InteractiveButton) {
+	if len(buttons) == 0 || len(buttons) > MaxReplyButtons {
+		v.add(path, "must have 1 to %d buttons", MaxReplyButtons)
+	}
+	var titles, ids []string
+	for i, b := range buttons {
+		bpath := fmt.Sprintf("%s[%d]", path, i)
+		if b.Type == "pp_action" {
+			continue
+		}
+		if b.Type != "reply" {
+			v.add(bpath+".type", "must be reply")
+		}
+		if b.Reply == nil {
+			v.add(bpath+".reply", "is required")
+			continue
+		}
+		v.text(bpath+".reply.title", b.Reply.Title, MaxReplyButtonTitleLength, true)
+		v.text(bpath+".reply.id", b.Reply.ID, MaxReplyButtonIDLength, true)
+		if strings.TrimSpace(b.Reply.ID) != b.Reply.ID {
+			v.add(bpath+".reply.id", "must not have leading or trailing spaces")
+		}
+		if slices.Contains(titles, b.Reply.Title) {
+			v.add(bpath+".reply.title", "%q is used by another button", b.Reply.Title)
+		}
+		if slices.Contains(ids, b.Reply.ID) {
+			v.add(bpath+".reply.id", "%q is used by another button", b.Reply.ID)
+		}
+		titles = append(titles, b.Reply.Title)
+		ids = append(ids, b.Reply.ID)
+	}
+}
+
+func (v *messageValidator) list(path string, a *InteractiveMessageAction) {
+	v.text(path+".button", a.Button, MaxListButtonLength, true)
+	if len(a.Sections) == 0 || len(a.Sections) > MaxListSections {
+		v.add(path+".sections", "must have 1 to %d sections", MaxListSections)
+	}
+	rows := 0
+	var ids []string
+	for i, s := range a.Sections {
+		spath := fmt.Sprintf("%s.sections[%d]", path, i)
+		v.text(spath+".title", s.Title, MaxListSectionTitleLength, len(a.Sections) > 1)
+		if len(s.Rows) == 0 {
+			v.add(spath+".rows", "must have at least one row")
+		}
+		for j, r := range s.Rows {
+			rpath := fmt.Sprintf("%s.rows[%d]", spath, j)
+			v.text(rpath+".title", r.Title, MaxListRowTitleLength, true)
+			v.text(rpath+".id", r.ID, MaxListRowIDLength, true)
+			v.text(rpath+".description", r.Description, MaxListRowDescriptionLength, false)
+			if slices.Contains(ids, r.ID) {
+				v.add(rpath+".id", "%q is used by another row", r.ID)
+			}
+			ids = append(ids, r.ID)
+		}
+		rows += len(s.Rows)
+	}
+	if rows > MaxListRows {
+		v.add(path+".sections", "have %d rows, the limit is %d", rows, MaxListRows)
+	}
+}
+
+// orderStatusUpdates are the statuses an order_status message can set.
+var orderStatusUpdates = []OrderStatusValue{
+	OrderStatusProcessing,
+	OrderStatusPartiallyShipped,
+	OrderStatusShipped,
+	OrderStatusCompleted,
+	OrderStatusCanceled,
+}
+
+func (v *messageValidator) productList(path string, a *InteractiveMessageAction) {
+	if len(a.Sections) == 0 || len(a.Sections) > MaxListSections {
+		v.add(path+".sections", "must have 1 to %d sections", MaxListSections)
+	}
+	items := 0
+	for i, s := range a.Sections {
+		spath := fmt.Sprintf("%s.sections[%d]", path, i)
+		v.text(spath+".title", s.Title, MaxListSectionTitleLength, len(a.Sections) > 1)
+		if len(s.ProductItems) == 0 {
+			v.add(spath+".product_items", "must have at least one item")
+		}
+		for j, it := range s.ProductItems {
+			if it.ProductRetailerID == "" {
+				v.add(fmt.Sprintf("%s.product_items[%d].product_retailer_id", spath, j), "is required")
+			}
+		}
+		items += len(s.ProductItems)
+	}
+	if items > MaxProductListItems {
+		v.add(path+".sections", "have %d items, the limit is %d", items, MaxProductListItems)
+	}
+}
+
+func (v *messageValidator) flow(path string, p *InteractiveActionParameters) {
+	if p == nil {
+		v.add(path, "is required")
+		return
+	}
+	if p.FlowMessageVersion == "" {
+		v.add(path+".flow_message_version", "is required")
+	}
+	if (p.FlowID == "") == (p.FlowName == "") {
+		v.add(path, "exactly one of flow_id and flow_name is required")
+	}
+	v.text(path+".flow_cta", p.FlowCTA, MaxFlowCTALength, true)
+	switch p.FlowAction {
+	case "", FlowActionNavigate:
+		if p.FlowActionPayload != nil && p.FlowActionPayload.Screen == "" {
+			v.add(path+".flow_action_payload.screen", "is required")
+		}
+	case FlowActionDataExchange:
+		if p.FlowActionPayload != nil {
+			v.add(path+".flow_action_payload", "is not allowed with data_exchange")
+		}
+	default:
+		v.add(path+".flow_action", "must be navigate or data_exchange")
+	}
+	if p.Mode != "" && p.Mode != FlowModeDraft && p.Mode != FlowModePublished {
+		v.add(path+".mode", "must be draft or published")
+	}
+}
+
+func (v *messageValidator) actionName(path string, a *InteractiveMessageAction, name string) {
+	if a.Name != name {
+		v.add(path+".name", "must be %q", name)
+	}
+}
+
+func (v *messageValidator) parameters(path string, a *InteractiveMessageAction) *InteractiveActionParameters {
+	if a.Parameters == nil {
+		v.add(path+".parameters", "is required")
+	}
+	return a.Parameters
+}
@@ -0,0 +1,134 @@
+package fbgraph
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBuildersValidate(t *testing.T) {
+	to := "5511987654321"
+	msgs := map[string]*MessageObject{
+		"text":     NewText(to, "Seu pedido saiu para entrega", false),
+		"image":    NewImage(to, MediaObject{ID: "123", Caption: "Receita"}),
+		"document": NewDocument(to, MediaObject{Link: "https://example.com/nf.pdf", Filename: "nf.pdf"}),
+		"audio":    NewAudio(to, MediaObject{ID: "123"}),
+		"location": NewLocation(to, LocationObject{Latitude: "-23.56", Longitude: "-46.65"}),
+		"contacts": NewContacts(to, ContactObject{Name: ContactName{FormattedName: "Farmácia Central"}}),
+		"reaction": NewReaction(to, "wamid.1", "👍").ReplyTo("wamid.1"),
+		"template": NewTemplate(to, &TemplateObject{Name: "pedido_enviado", Language: &LanguageObject{Code: "pt_BR"}}),
+		"buttons": NewButtons(to, "Confirma a entrega amanhã?",
+			ReplyButton("sim", "Sim"), ReplyButton("nao", "Não")).WithHeaderText("Entrega").WithFooter("Farmácia Central"),
+		"list": NewList(to, "Escolha a loja", "Lojas",
+			ListSection("Centro", InteractiveMessageSectionRow{ID: "1", Title: "Loja 1", Description: "Rua A"}),
+			ListSection("Zona Sul", InteractiveMessageSectionRow{ID: "2", Title: "Loja 2"})),
+		"product": NewProduct(to, "", "cat1", "sku1"),
+		"cta_url": NewInteractive(to, InteractiveMessageCTAURL, "Acompanhe seu pedido",
+			NewCTAURLAction("Ver pedido", "https://example.com/42")),
+		"flow": NewInteractive(to, InteractiveMessageFlow, "Agende sua entrega",
+			NewFlowAction(InteractiveActionParameters{FlowID: "1", FlowToken: "t", FlowCTA: "Agendar"})),
+		"voice_call": NewInteractive(to, InteractiveMessageVoiceCall, "Fale com a farmacêutica",
+			NewVoiceCallAction("Ligar", 0, "")),
+		"location_request": NewInteractive(to, InteractiveMessageLocationRequest, "Onde você está?", NewLocationRequestAction()),
+	}
+	for name, m := range msgs {
+		if err := m.Validate(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestValidateIssues(t *testing.T) {
+	to := "5511987654321"
+	tests := []struct {
+		name string
+		msg  *MessageObject
+		path string
+	}{
+		{"no type", &MessageObject{MessagingProduct: "whatsapp", To: to}, "type"},
+		{"type mismatch", func() *MessageObject {
+			m := NewText(to, "oi", false)
+			m.Type = "image"
+			return m
+		}(), "text"},
+		{"empty text", NewText(to, " ", false), "text.body"},
+		{"long text", NewText(to, strings.Repeat("a", MaxTextBodyLength+1), false), "text.body"},
+		{"no recipient", NewText("", "oi", false), "to"},
+		{"audio caption", NewAudio(to, MediaObject{ID: "1", Caption: "x"}), "audio.caption"},
+		{"no media", NewImage(to, MediaObject{}), "image"},
+		{"four buttons", NewButtons(to, "b", ReplyButton("1", "a"), ReplyButton("2", "b"), ReplyButton("3", "c"), ReplyButton("4", "d")),
+			"interactive.action.buttons"},
+		{"long button title", NewButtons(to, "b", ReplyButton("1", strings.Repeat("a", 21))), "interactive.action.buttons[0].reply.title"},
+		{"duplicate button id", NewButtons(to, "b", ReplyButton("1", "a"), ReplyButton("1", "b")), "interactive.action.buttons[1].reply.id"},
+		{"no body", NewButtons(to, "", ReplyButton("1", "a")), "interactive.body"},
+		{"long row title", NewList(to, "b", "Lojas", ListSection("", InteractiveMessageSectionRow{ID: "1", Title: strings.Repeat("a", 25)})),
+			"interactive.action.sections[0].rows[0].title"},
+		{"long row description", NewList(to, "b", "Lojas",
+			ListSection("", InteractiveMessageSectionRow{ID: "1", Title: "a", Description: strings.Repeat("a", 73)})),
+			"interactive.action.sections[0].rows[0].description"},
+		{"eleven rows", NewList(to, "b", "Lojas", ListSection("", func() []InteractiveMessageSectionRow {
+			rows := make([]InteractiveMessageSectionRow, 11)
+			for i := range rows {
+				rows[i] = InteractiveMessageSectionRow{ID: string(rune('a' + i)), Title: "t"}
+			}
+			return rows
+		}()...)), "interactive.action.sections"},
+		{"untitled sections", NewList(to, "b", "Lojas",
+			ListSection("", InteractiveMessageSectionRow{ID: "1", Title: "a"}),
+			ListSection("", InteractiveMessageSectionRow{ID: "2", Title: "b"})), "interactive.action.sections[0].title"},
+		{"product header", NewProduct(to, "", "cat1", "sku1").WithHeaderText("x"), "interactive.header"},
+		{"flow id and name", NewInteractive(to, InteractiveMessageFlow, "b",
+			NewFlowAction(InteractiveActionParameters{FlowID: "1", FlowName: "n", FlowCTA: "Abrir"})), "interactive.action.parameters"},
+		{"cta url scheme", NewInteractive(to, InteractiveMessageCTAURL, "b", NewCTAURLAction("Ver", "example.com")),
+			"interactive.action.parameters.url"},
+		{"address country", NewInteractive(to, InteractiveMessageAddress, "b", NewAddressAction("BR", nil)),
+			"interactive.action.parameters.country"},
+		{"voice call ttl", NewInteractive(to, InteractiveMessageVoiceCall, "b", NewVoiceCallAction("Ligar", -1, "")),
+			"interactive.action.parameters.ttl_minutes"},
+		{"empty product section", NewProductList(to, "b", "cat1", ProductSection("Ofertas")).WithHeaderText("h"),
+			"interactive.action.sections[0].product_items"},
+		{"thirty one products", NewProductList(to, "b", "cat1",
+			ProductSection("A", strings.Split(strings.Repeat("sku,", 16), ",")[:16]...),
+			ProductSection("B", strings.Split(strings.Repeat("sku,", 15), ",")[:15]...)).WithHeaderText("h"),
+			"interactive.action.sections"},
+	}
+	for _, tt := range tests {
+		err := tt.msg.Validate()
+		var ve *MessageValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("%s: Validate() = %v", tt.name, err)
+			continue
+		}
+		found := false
+		for _, vi := range ve.Issues {
+			found = found || vi.Path == tt.path
+		}
+		if !found {
+			t.Errorf("%s: no issue at %s in %v", tt.name, tt.path, err)
+		}
+	}
+}
+
+func TestClientValidateMessages(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"messages":[{"id":"wamid.1"}]}`))
+	}))
+	defer srv.Close()
+	c := NewClient("tok")
+	c.BaseURL = srv.URL
+	c.ValidateMessages = true
+
+	_, err := c.SendMessageWithContext(context.Background(), "123", NewText("5511987654321", "", false))
+	var ve *MessageValidationError
+	if !errors.As(err, &ve) || calls != 0 {
+		t.Fatalf("err = %v, calls = %d", err, calls)
+	}
+	if _, err := c.SendMessageWithContext(context.Background(), "123", NewText("5511987654321", "oi", false)); err != nil || calls != 1 {
+		t.Fatalf("err = %v, calls = %d", err, calls)
+	}
+}
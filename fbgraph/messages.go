@@ -1,6 +1,7 @@
 package fbgraph
 
 import (
+	"errors"
 	"strconv"
 	"strings"
 
@@ -170,14 +171,17 @@ func (t *TemplateCardComponent) GetComponents() []TemplateComponent {
 }
 
 type TemplateComponentParameter struct {
-	Type     string              `json:"type"`
-	Image    *ImageParameters    `json:"image,omitempty"`
-	Payload  string              `json:"payload,omitempty"`
-	Text     string              `json:"text,omitempty"`
-	Currency *CurrencyParameters `json:"currency,omitempty"`
-	DateTime *DateTimeParameters `json:"date_time,omitempty"`
-	Video    *VideoParameters    `json:"video,omitempty"`
-	Document *MediaObject        `json:"document,omitempty"`
+	Type string `json:"type"`
+	// ParameterName is the variable a parameter fills in templates created
+	// with ParameterFormatNamed, e.g. "first_name" for {{first_name}}.
+	ParameterName string              `json:"parameter_name,omitempty"`
+	Image         *ImageParameters    `json:"image,omitempty"`
+	Payload       string              `json:"payload,omitempty"`
+	Text          string              `json:"text,omitempty"`
+	Currency      *CurrencyParameters `json:"currency,omitempty"`
+	DateTime      *DateTimeParameters `json:"date_time,omitempty"`
+	Video         *VideoParameters    `json:"video,omitempty"`
+	Document      *MediaObject        `json:"document,omitempty"`
 }
 
 // ImageParameters is present when type = "image"
@@ -231,6 +235,8 @@ type GraphError struct {
 	ErrorData      ErrorData `json:"error_data"`
 	FBTraceID      string    `json:"fbtrace_id"`
 	HTTPStatusCode int       `json:"http_status_code"` // this is not originally in the response
+	// RawBody is the unparsed response body the error was read from.
+	RawBody string `json:"-"`
 }
 
 // type AutoGenerated struct {
@@ -262,7 +268,8 @@ func AsGraphError(err error) (*GraphError, bool) {
 	if err == nil {
 		return nil, false
 	}
-	if e, ok := err.(*GraphError); ok {
+	var e *GraphError
+	if errors.As(err, &e) {
 		return e, true
 	}
 	return nil, false
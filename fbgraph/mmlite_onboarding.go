@@ -70,14 +70,13 @@ func (c *Client) GetMMLiteOnboardingStatus(ctx context.Context, id string) (MMLi
 	// Reset first, as every other method on Client does: without it a reused
 	// client that succeeds here still reports the previous call's error from
 	// LastGraphError().
-	c.lastGraphError = nil
-	c.lastErrorRawBody = ""
+	c.resetLastError()
 
 	q := make(url.Values)
 	q.Set("fields", mmLiteOnboardingStatusField)
 
-	u := fmt.Sprintf("https://graph.facebook.com/%s/%s?%s",
-		c.graphVersion(), url.PathEscape(id), q.Encode())
+	u := fmt.Sprintf("%s/%s/%s?%s",
+		c.baseURL(), c.graphVersion(), url.PathEscape(id), q.Encode())
 
 	req, err := NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
@@ -85,7 +84,7 @@ func (c *Client) GetMMLiteOnboardingStatus(ctx context.Context, id string) (MMLi
 	}
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
 	}
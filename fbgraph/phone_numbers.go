@@ -0,0 +1,218 @@
+package fbgraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// VerificationCodeMethod is how Meta delivers a phone number verification
+// code.
+type VerificationCodeMethod string
+
+const (
+	CodeMethodSMS   VerificationCodeMethod = "SMS"
+	CodeMethodVoice VerificationCodeMethod = "VOICE"
+)
+
+// DisplayNameStatus is the review status of a phone number's display name.
+type DisplayNameStatus string
+
+const (
+	NameStatusApproved               DisplayNameStatus = "APPROVED"
+	NameStatusAvailableWithoutReview DisplayNameStatus = "AVAILABLE_WITHOUT_REVIEW"
+	NameStatusDeclined               DisplayNameStatus = "DECLINED"
+	NameStatusExpired                DisplayNameStatus = "EXPIRED"
+	NameStatusPendingReview          DisplayNameStatus = "PENDING_REVIEW"
+	NameStatusNone                   DisplayNameStatus = "NONE"
+)
+
+// PhoneNumberStatus is the registration state of a phone number.
+type PhoneNumberStatus struct {
+	ID                     string `json:"id"`
+	DisplayPhoneNumber     string `json:"display_phone_number"`
+	VerifiedName           string `json:"verified_name"`
+	CodeVerificationStatus string `json:"code_verification_status"` // VERIFIED, NOT_VERIFIED, EXPIRED
+	// Status is e.g. CONNECTED once registered, PENDING before.
+	Status     string            `json:"status"`
+	NameStatus DisplayNameStatus `json:"name_status"`
+	// NewNameStatus is the review of a display name change, if one was
+	// requested.
+	NewNameStatus DisplayNameStatus `json:"new_name_status"`
+	// DataLocalizationRegion is set when the number stores data at rest in a
+	// region (Local Storage).
+	DataLocalizationRegion string `json:"data_localization_region"`
+}
+
+// RegisterPhoneNumberParams are the parameters of RegisterPhoneNumber.
+type RegisterPhoneNumberParams struct {
+	// PIN is the 6-digit two-step verification PIN. For a number that has
+	// none yet, it becomes its PIN.
+	PIN string `json:"pin"`
+	// DataLocalizationRegion, e.g. "BR", enables Local Storage: message data
+	// at rest is kept in that country. Optional.
+	DataLocalizationRegion string `json:"data_localization_region,omitempty"`
+}
+
+// PhoneNumberError is the error of a phone number operation. It unwraps to the
+// Graph error, so catalog errors such as ErrRegisterRateLimit (133016) or
+// ErrTwoStepPINMismatch (133005) match it with errors.Is.
+type PhoneNumberError struct {
+	PhoneID string
+	// Op is the operation: "request_code", "verify_code", "register",
+	// "deregister", "set_pin" or "display_name".
+	Op  string
+	Err error
+}
+
+func (e *PhoneNumberError) Error() string {
+	return fmt.Sprintf("phone number %s: %s: %v", e.PhoneID, e.Op, e.Err)
+}
+
+func (e *PhoneNumberError) Unwrap() error {
+	return e.Err
+}
+
+var pinRegexp = regexp.MustCompile(`^[0-9]{6}$`)
+
+// RequestVerificationCode has Meta send a verification code to the phone
+// number, by SMS or voice call, in language (e.g. "pt_BR").
+//
+// POST /{PHONE_NUMBER_ID}/request_code
+func (c *Client) RequestVerificationCode(ctx context.Context, phoneID string, method VerificationCodeMethod, language string) error {
+	body := map[string]string{"code_method": string(method), "language": language}
+	return c.phoneNumberAction(ctx, "request_code", phoneID, "request_code", nil, body)
+}
+
+// VerifyCode submits the code received after RequestVerificationCode.
+//
+// POST /{PHONE_NUMBER_ID}/verify_code
+func (c *Client) VerifyCode(ctx context.Context, phoneID, code string) error {
+	return c.phoneNumberAction(ctx, "verify_code", phoneID, "verify_code", nil, map[string]string{"code": code})
+}
+
+// RegisterPhoneNumber registers a verified phone number on the Cloud API, so
+// it can send and receive messages.
+//
+// POST /{PHONE_NUMBER_ID}/register
+func (c *Client) RegisterPhoneNumber(ctx context.Context, phoneID string, params RegisterPhoneNumberParams) error {
+	if !pinRegexp.MatchString(params.PIN) {
+		return &PhoneNumberError{PhoneID: phoneID, Op: "register", Err: fmt.Errorf("pin must be 6 digits")}
+	}
+	body := struct {
+		MessagingProduct string `json:"messaging_product"`
+		RegisterPhoneNumberParams
+	}{"whatsapp", params}
+	return c.phoneNumberAction(ctx, "register", phoneID, "register", nil, body)
+}
+
+// DeregisterPhoneNumber removes a phone number from the Cloud API, e.g. to
+// move it to the WhatsApp Business app. Registering and deregistering share
+// a limit of 10 requests per 72 hours (error 133016).
+//
+// POST /{PHONE_NUMBER_ID}/deregister
+func (c *Client) DeregisterPhoneNumber(ctx context.Context, phoneID string) error {
+	return c.phoneNumberAction(ctx, "deregister", phoneID, "deregister", nil, struct{}{})
+}
+
+// SetTwoStepVerificationPIN sets or changes the 6-digit two-step verification
+// PIN of a registered phone number.
+//
+// POST /{PHONE_NUMBER_ID}
+func (c *Client) SetTwoStepVerificationPIN(ctx context.Context, phoneID, pin string) error {
+	if !pinRegexp.MatchString(pin) {
+		return &PhoneNumberError{PhoneID: phoneID, Op: "set_pin", Err: fmt.Errorf("pin must be 6 digits")}
+	}
+	return c.phoneNumberAction(ctx, "set_pin", phoneID, "", nil, map[string]string{"pin": pin})
+}
+
+// RequestDisplayNameChange submits a new display name for review. Follow the
+// review through GetPhoneNumberStatus's NewNameStatus.
+//
+// POST /{PHONE_NUMBER_ID}?new_display_name=...
+func (c *Client) RequestDisplayNameChange(ctx context.Context, phoneID, newName string) error {
+	q := url.Values{"new_display_name": {newName}}
+	return c.phoneNumberAction(ctx, "display_name", phoneID, "", q, nil)
+}
+
+// GetPhoneNumberStatus reads the registration and display name state of a
+// phone number.
+//
+// GET /{PHONE_NUMBER_ID}
+func (c *Client) GetPhoneNumberStatus(ctx context.Context, phoneID string) (*PhoneNumberStatus, error) {
+	c.resetLastError()
+
+	q := url.Values{"fields": {"id,display_phone_number,verified_name,code_verification_status,status,name_status,new_name_status,data_localization_region"}}
+	u := fmt.Sprintf("%s/%s/%s?%s", c.baseURL(), c.graphVersion(), url.PathEscape(phoneID), q.Encode())
+	req, err := NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.httpError(resp)
+	}
+	out := &PhoneNumberStatus{}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return out, nil
+}
+
+// phoneNumberAction posts body (JSON, when not nil) to the phone number node,
+// or to one of its edges, and expects {"success": true}.
+func (c *Client) phoneNumberAction(ctx context.Context, op, phoneID, edge string, q url.Values, body any) error {
+	c.resetLastError()
+
+	u := fmt.Sprintf("%s/%s/%s", c.baseURL(), c.graphVersion(), url.PathEscape(phoneID))
+	if edge != "" {
+		u += "/" + edge
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	var rbody io.Reader
+	if body != nil {
+		jd, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		rbody = bytes.NewReader(jd)
+	}
+	req, err := NewRequestWithContext(ctx, http.MethodPost, u, rbody)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	resp, err := c.do(req)
+	if err != nil {
+		return &PhoneNumberError{PhoneID: phoneID, Op: op, Err: fmt.Errorf("request failed: %w", err)}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return &PhoneNumberError{PhoneID: phoneID, Op: op, Err: c.httpError(resp)}
+	}
+	out := struct {
+		Success bool `json:"success"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if !out.Success {
+		return &PhoneNumberError{PhoneID: phoneID, Op: op, Err: fmt.Errorf("graph api did not report success")}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package fbgraph_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+	"github.com/pedidopago/wabaman-contrib/fbgraph/fbgraphtest"
+)
+
+func TestPhoneNumberLifecycle(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	srv.AddPhoneNumber("waba1", fbgraph.WABAPhoneNumber{ID: "phone1", DisplayPhoneNumber: "+55 11 3271-0305"})
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	if err := c.RequestVerificationCode(ctx, "phone1", fbgraph.CodeMethodSMS, "pt_BR"); err != nil {
+		t.Fatal(err)
+	}
+	st, _ := srv.PhoneNumberState("phone1")
+	if err := c.VerifyCode(ctx, "phone1", "000000"); err == nil {
+		t.Error("wrong code was accepted")
+	}
+	if err := c.VerifyCode(ctx, "phone1", st.VerificationCode); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RegisterPhoneNumber(ctx, "phone1", fbgraph.RegisterPhoneNumberParams{PIN: "123456", DataLocalizationRegion: "BR"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RequestDisplayNameChange(ctx, "phone1", "Pedido Pago"); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := c.GetPhoneNumberStatus(ctx, "phone1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != "CONNECTED" || status.CodeVerificationStatus != "VERIFIED" || status.DataLocalizationRegion != "BR" ||
+		status.NewNameStatus != fbgraph.NameStatusPendingReview {
+		t.Errorf("status = %+v", status)
+	}
+
+	if err := c.SetTwoStepVerificationPIN(ctx, "phone1", "654321"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeregisterPhoneNumber(ctx, "phone1"); err != nil {
+		t.Fatal(err)
+	}
+	err = c.RegisterPhoneNumber(ctx, "phone1", fbgraph.RegisterPhoneNumberParams{PIN: "123456"})
+	var pe *fbgraph.PhoneNumberError
+	if !errors.Is(err, fbgraph.ErrTwoStepPINMismatch) || !errors.As(err, &pe) || pe.Op != "register" {
+		t.Fatalf("err = %v, want a PIN mismatch", err)
+	}
+}
+
+func TestRegisterPhoneNumberRateLimit(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	srv.AddPhoneNumber("waba1", fbgraph.WABAPhoneNumber{ID: "phone1"})
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	var err error
+	for range 11 {
+		if err = c.RegisterPhoneNumber(ctx, "phone1", fbgraph.RegisterPhoneNumberParams{PIN: "123456"}); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, fbgraph.ErrRegisterRateLimit) || fbgraph.ClassifyError(err) != fbgraph.CategoryRetryable {
+		t.Fatalf("err = %v, want 133016", err)
+	}
+	if err := c.RegisterPhoneNumber(ctx, "phone1", fbgraph.RegisterPhoneNumberParams{PIN: "12345"}); err == nil {
+		t.Error("short PIN was accepted")
+	}
+}
@@ -0,0 +1,257 @@
+package fbgraph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxPrefilledMessageLength is the longest prefilled message of a QR code, in
+// characters.
+const MaxPrefilledMessageLength = 140
+
+// QRImageFormat is the image format of a QR code.
+type QRImageFormat string
+
+const (
+	QRImagePNG QRImageFormat = "PNG"
+	QRImageSVG QRImageFormat = "SVG"
+)
+
+// QRCode is a QR code and short link that open a chat with the phone number,
+// with PrefilledMessage typed in.
+type QRCode struct {
+	Code             string `json:"code"`
+	PrefilledMessage string `json:"prefilled_message"`
+	// DeepLinkURL is the short link, e.g. https://wa.me/message/4O4YGZEG3RIVE1.
+	DeepLinkURL string `json:"deep_link_url"`
+	// QRImageURL is only set when an image format was asked for.
+	QRImageURL string `json:"qr_image_url,omitempty"`
+}
+
+type qrCodesResponse struct {
+	Data   []QRCode `json:"data"`
+	Paging struct {
+		Cursors struct {
+			After string `json:"after"`
+		} `json:"cursors"`
+		Next string `json:"next"`
+	} `json:"paging"`
+}
+
+// CreateQRCode creates a QR code for a phone number. format may be empty when
+// only the short link is needed.
+//
+// POST /{PHONE_NUMBER_ID}/message_qrdls
+func (c *Client) CreateQRCode(ctx context.Context, phoneID, prefilledMessage string, format QRImageFormat) (*QRCode, error) {
+	if err := validatePrefilledMessage(prefilledMessage); err != nil {
+		return nil, err
+	}
+	q := url.Values{"prefilled_message": {prefilledMessage}}
+	if format != "" {
+		q.Set("generate_qr_image", string(format))
+	}
+	out := &QRCode{}
+	if err := c.qrCodesCall(ctx, http.MethodPost, phoneID, "", q, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateQRCode changes the prefilled message of a QR code. The code, short
+// link and image stay the same, so printed codes keep working.
+//
+// POST /{PHONE_NUMBER_ID}/message_qrdls?code=...
+func (c *Client) UpdateQRCode(ctx context.Context, phoneID, code, prefilledMessage string) (*QRCode, error) {
+	if err := validatePrefilledMessage(prefilledMessage); err != nil {
+		return nil, err
+	}
+	q := url.Values{"code": {code}, "prefilled_message": {prefilledMessage}}
+	out := &QRCode{}
+	if err := c.qrCodesCall(ctx, http.MethodPost, phoneID, "", q, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetQRCode reads a QR code. When format is not empty, QRImageURL is set to
+// an image in that format.
+//
+// GET /{PHONE_NUMBER_ID}/message_qrdls/{CODE}
+func (c *Client) GetQRCode(ctx context.Context, phoneID, code string, format QRImageFormat) (*QRCode, error) {
+	fields := "code,prefilled_message,deep_link_url"
+	if format != "" {
+		fields += ",qr_image_url.format(" + string(format) + ")"
+	}
+	out := &qrCodesResponse{}
+	if err := c.qrCodesCall(ctx, http.MethodGet, phoneID, code, url.Values{"fields": {fields}}, out); err != nil {
+		return nil, err
+	}
+	if len(out.Data) == 0 {
+		return nil, fmt.Errorf("qr code %s not found", code)
+	}
+	return &out.Data[0], nil
+}
+
+// maxQRCodePages bounds ListQRCodes, so a rotating cursor cannot spin
+// forever; at 100 per page it is far beyond the codes a phone number has.
+const maxQRCodePages = 50
+
+// ListQRCodes lists every QR code of a phone number, without images. It fails
+// rather than return a partial list when the codes do not fit in
+// maxQRCodePages pages.
+//
+// GET /{PHONE_NUMBER_ID}/message_qrdls
+func (c *Client) ListQRCodes(ctx context.Context, phoneID string) ([]QRCode, error) {
+	var all []QRCode
+	after := ""
+	for range maxQRCodePages {
+		q := url.Values{"fields": {"code,prefilled_message,deep_link_url"}, "limit": {"100"}}
+		if after != "" {
+			q.Set("after", after)
+		}
+		out := &qrCodesResponse{}
+		if err := c.qrCodesCall(ctx, http.MethodGet, phoneID, "", q, out); err != nil {
+			return nil, err
+		}
+		all = append(all, out.Data...)
+		if out.Paging.Next == "" || out.Paging.Cursors.After == "" {
+			return all, nil
+		}
+		if out.Paging.Cursors.After == after {
+			return nil, fmt.Errorf("list qr codes: paging cursor %q did not advance", after)
+		}
+		after = out.Paging.Cursors.After
+	}
+	return nil, fmt.Errorf("list qr codes: more than %d pages", maxQRCodePages)
+}
+
+// DeleteQRCode deletes a QR code. Printed copies stop opening the chat.
+//
+// DELETE /{PHONE_NUMBER_ID}/message_qrdls/{CODE}
+func (c *Client) DeleteQRCode(ctx context.Context, phoneID, code string) error {
+	out := struct {
+		Success bool `json:"success"`
+	}{}
+	if err := c.qrCodesCall(ctx, http.MethodDelete, phoneID, code, nil, &out); err != nil {
+		return err
+	}
+	if !out.Success {
+		return fmt.Errorf("delete qr code %s: graph api did not report success", code)
+	}
+	return nil
+}
+
+// GetQRCodeImage downloads the image of a QR code in format, ready to print.
+func (c *Client) GetQRCodeImage(ctx context.Context, phoneID, code string, format QRImageFormat) ([]byte, error) {
+	if format == "" {
+		return nil, errors.New("qr image format is required")
+	}
+	qr, err := c.GetQRCode(ctx, phoneID, code, format)
+	if err != nil {
+		return nil, err
+	}
+	if qr.QRImageURL == "" {
+		return nil, fmt.Errorf("qr code %s has no image url", code)
+	}
+
+	c.resetLastError()
+	req, err := NewRequestWithContext(ctx, http.MethodGet, c.mediaURL(qr.QRImageURL), nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.errorFromResponse(resp)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("download qr image: %w", err)
+	}
+	return b, nil
+}
+
+// qrCodesCall sends a request to the message_qrdls edge, or to one of its
+// codes, and decodes the response into out.
+func (c *Client) qrCodesCall(ctx context.Context, method, phoneID, code string, q url.Values, out any) error {
+	c.resetLastError()
+
+	u := fmt.Sprintf("%s/%s/%s/message_qrdls", c.baseURL(), c.graphVersion(), url.PathEscape(phoneID))
+	if code != "" {
+		u += "/" + url.PathEscape(code)
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return c.errorFromResponse(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+func validatePrefilledMessage(msg string) error {
+	if strings.TrimSpace(msg) == "" {
+		return errors.New("prefilled message is empty")
+	}
+	if n := utf8.RuneCountInString(msg); n > MaxPrefilledMessageLength {
+		return fmt.Errorf("prefilled message is %d characters long, the limit is %d", n, MaxPrefilledMessageLength)
+	}
+	return nil
+}
+
+// WaMeLink builds a https://wa.me link that opens a chat with phone, with
+// text typed in. It needs no API call, nor a QR code.
+//
+// phone is an international number; spaces, dashes, dots, parentheses and a
+// leading "+" are dropped, so "+55 (11) 98765-4321" gives
+// https://wa.me/5511987654321. text may be empty.
+func WaMeLink(phone, text string) (string, error) {
+	digits := make([]byte, 0, len(phone))
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, byte(r))
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", fmt.Errorf("phone number %q has an invalid character %q", phone, r)
+		}
+	}
+	// E.164: a country code, which never starts with 0, and at most 15 digits
+	if len(digits) < 8 || len(digits) > 15 {
+		return "", fmt.Errorf("phone number %q must have 8 to 15 digits, country code included", phone)
+	}
+	if digits[0] == '0' {
+		return "", fmt.Errorf("phone number %q must start with the country code, not 0", phone)
+	}
+	link := "https://wa.me/" + string(digits)
+	if text != "" {
+		// wa.me wants %20 for spaces; QueryEscape already encodes a literal
+		// "+" as %2B, so the replace is safe
+		link += "?text=" + strings.ReplaceAll(url.QueryEscape(text), "+", "%20")
+	}
+	return link, nil
+}
@@ -0,0 +1,100 @@
+package fbgraph_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+	"github.com/pedidopago/wabaman-contrib/fbgraph/fbgraphtest"
+)
+
+func TestQRCodes(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	srv.AddPhoneNumber("waba1", fbgraph.WABAPhoneNumber{ID: "phone1"})
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	qr, err := c.CreateQRCode(ctx, "phone1", "Quero acompanhar meu pedido", fbgraph.QRImagePNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if qr.Code == "" || qr.DeepLinkURL == "" || qr.QRImageURL == "" {
+		t.Fatalf("created %+v", qr)
+	}
+	if _, err := c.CreateQRCode(ctx, "phone1", "Quero falar com a farmacêutica", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := c.UpdateQRCode(ctx, "phone1", qr.Code, "Quero rastrear meu pedido")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Code != qr.Code || updated.DeepLinkURL != qr.DeepLinkURL {
+		t.Errorf("update changed the code: %+v", updated)
+	}
+
+	img, err := c.GetQRCodeImage(ctx, "phone1", qr.Code, fbgraph.QRImageSVG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(img, []byte("<svg")) {
+		t.Errorf("image = %q", img)
+	}
+
+	if err := c.DeleteQRCode(ctx, "phone1", qr.Code); err != nil {
+		t.Fatal(err)
+	}
+	list, err := c.ListQRCodes(ctx, "phone1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].PrefilledMessage != "Quero falar com a farmacêutica" {
+		t.Errorf("list = %+v", list)
+	}
+	if _, err := c.GetQRCode(ctx, "phone1", qr.Code, ""); err == nil {
+		t.Error("deleted code was found")
+	}
+}
+
+func TestListQRCodesEndlessPaging(t *testing.T) {
+	page := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page++
+		_, _ = fmt.Fprintf(w, `{"data":[{"code":"C%d"}],"paging":{"cursors":{"after":"p%d"},"next":"https://graph.facebook.com/next"}}`, page, page)
+	}))
+	defer srv.Close()
+	c := fbgraph.NewClient("tok")
+	c.BaseURL = srv.URL
+
+	codes, err := c.ListQRCodes(context.Background(), "phone1")
+	if err == nil || !strings.Contains(err.Error(), "pages") || codes != nil {
+		t.Errorf("ListQRCodes = %d codes, %v; want an error", len(codes), err)
+	}
+}
+
+func TestWaMeLink(t *testing.T) {
+	tests := []struct {
+		phone, text string
+		want        string
+		wantErr     bool
+	}{
+		{phone: "+55 (11) 98765-4321", want: "https://wa.me/5511987654321"},
+		{phone: "5511987654321", text: "Olá! Pedido #42 & cia + 1", want: "https://wa.me/5511987654321?text=Ol%C3%A1%21%20Pedido%20%2342%20%26%20cia%20%2B%201"},
+		{phone: "011987654321", wantErr: true},
+		{phone: "1234567", wantErr: true},
+		{phone: "55 11 9876x4321", wantErr: true},
+		{phone: "55+11987654321", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := fbgraph.WaMeLink(tt.phone, tt.text)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("WaMeLink(%q, %q) = %q, %v", tt.phone, tt.text, got, err)
+		}
+	}
+}
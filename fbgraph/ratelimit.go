@@ -0,0 +1,250 @@
+package fbgraph
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SendLimitMode tells a SendLimiter what to do with a send that is over budget.
+type SendLimitMode int
+
+const (
+	// SendLimitQueue delays the send until it fits the budget.
+	SendLimitQueue SendLimitMode = iota
+	// SendLimitReject fails the send right away with a *SendRateLimitError.
+	SendLimitReject
+)
+
+// DefaultPairInterval is a conservative spacing between messages from one phone
+// number to the same recipient, below which Meta answers with error 131056
+// (pair rate limit hit).
+const DefaultPairInterval = 6 * time.Second
+
+// SendLimiter paces message sends per business phone number, and per
+// (phone number, recipient) pair. Wrap a send function with Wrap:
+//
+//	lim := &fbgraph.SendLimiter{PerPhoneRate: 80, PairInterval: fbgraph.DefaultPairInterval}
+//	send := lim.Wrap(client.SendMessageWithContext)
+//
+// A SendLimiter is safe for concurrent use. Do not change its fields once it is
+// in use.
+type SendLimiter struct {
+	// PerPhoneRate is the number of messages per second each phone ID may
+	// send. Zero means unlimited.
+	PerPhoneRate float64
+	// Burst is how many messages a phone ID may send back to back before
+	// PerPhoneRate applies. Defaults to 1.
+	Burst int
+	// PairInterval is the minimum spacing between two messages from a phone
+	// ID to the same recipient. Zero disables the pair guard.
+	PairInterval time.Duration
+	// Mode chooses between queueing and rejecting sends over budget.
+	Mode SendLimitMode
+	// MaxWait bounds how long a queued send may wait. Sends that would wait
+	// longer are rejected. Zero means no bound.
+	MaxWait time.Duration
+	// OnSend, when set, is called for every send that went through the
+	// limiter, after its wait.
+	OnSend func(SendWait)
+
+	mu     sync.Mutex
+	phones map[string]*phoneBudget
+	pairs  map[string]time.Time // phoneID+"/"+recipient -> time of the last send
+	stats  map[string]*SendLimiterStats
+	sweeps int
+}
+
+// SendWait describes how a send fared in a SendLimiter.
+type SendWait struct {
+	PhoneID   string
+	Recipient string
+	Wait      time.Duration // time spent queued
+	Rejected  bool
+	// Pair is true when the pair guard, not the phone budget, set the wait.
+	Pair bool
+}
+
+// SendLimiterStats are the totals of a phone ID in a SendLimiter.
+type SendLimiterStats struct {
+	Sent      int
+	Rejected  int
+	TotalWait time.Duration
+	MaxWait   time.Duration
+}
+
+// SendRateLimitError is returned for sends rejected by a SendLimiter. It
+// matches ErrSendRateLimited.
+type SendRateLimitError struct {
+	PhoneID    string
+	Recipient  string
+	RetryAfter time.Duration
+	Pair       bool
+}
+
+func (e *SendRateLimitError) Error() string {
+	if e.Pair {
+		return fmt.Sprintf("send rate limited: %s -> %s, retry in %s", e.PhoneID, e.Recipient, e.RetryAfter)
+	}
+	return fmt.Sprintf("send rate limited: %s, retry in %s", e.PhoneID, e.RetryAfter)
+}
+
+func (e *SendRateLimitError) Is(target error) bool {
+	return target == ErrSendRateLimited
+}
+
+type phoneBudget struct {
+	tat time.Time // theoretical arrival time of the next send (GCRA)
+}
+
+// Wrap returns fn paced by the limiter.
+func (l *SendLimiter) Wrap(fn SendMessageWithContextFn) SendMessageWithContextFn {
+	return func(ctx context.Context, phoneID string, msg *MessageObject) (*MessageObjectResult, error) {
+		if err := l.Wait(ctx, phoneID, recipientOf(msg)); err != nil {
+			return nil, err
+		}
+		return fn(ctx, phoneID, msg)
+	}
+}
+
+// WrapFn is Wrap for the legacy SendMessageFn signature.
+func (l *SendLimiter) WrapFn(fn SendMessageFn) SendMessageFn {
+	return l.Wrap(func(_ context.Context, phoneID string, msg *MessageObject) (*MessageObjectResult, error) {
+		return fn(phoneID, msg)
+	}).WithoutContext()
+}
+
+// Wait blocks until phoneID may send to recipient, or rejects the send
+// according to Mode. A send cancelled through ctx while queued keeps its slot.
+func (l *SendLimiter) Wait(ctx context.Context, phoneID, recipient string) error {
+	wait, pair, ok := l.reserve(phoneID, recipient, time.Now())
+	if !ok {
+		l.record(SendWait{PhoneID: phoneID, Recipient: recipient, Rejected: true, Pair: pair})
+		return &SendRateLimitError{PhoneID: phoneID, Recipient: recipient, RetryAfter: wait, Pair: pair}
+	}
+	if wait > 0 {
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+	l.record(SendWait{PhoneID: phoneID, Recipient: recipient, Wait: wait, Pair: pair})
+	return nil
+}
+
+// Stats returns the totals of every phone ID seen so far.
+func (l *SendLimiter) Stats() map[string]SendLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make(map[string]SendLimiterStats, len(l.stats))
+	for k, v := range l.stats {
+		out[k] = *v
+	}
+	return out
+}
+
+// reserve books the earliest slot for a send. It returns the wait until that
+// slot, or ok == false (and the wait that would have been needed) when the
+// send must be rejected, in which case nothing is booked.
+func (l *SendLimiter) reserve(phoneID, recipient string, now time.Time) (wait time.Duration, pair, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.phones == nil {
+		l.phones = make(map[string]*phoneBudget)
+		l.pairs = make(map[string]time.Time)
+		l.stats = make(map[string]*SendLimiterStats)
+	}
+
+	at := now
+	var interval time.Duration
+	pb := l.phones[phoneID]
+	if pb == nil {
+		pb = &phoneBudget{}
+		l.phones[phoneID] = pb
+	}
+	if l.PerPhoneRate > 0 {
+		interval = time.Duration(float64(time.Second) / l.PerPhoneRate)
+		burst := max(l.Burst, 1)
+		if earliest := pb.tat.Add(-time.Duration(burst-1) * interval); earliest.After(at) {
+			at = earliest
+		}
+	}
+	pairKey := phoneID + "/" + recipient
+	if l.PairInterval > 0 && recipient != "" {
+		if last, seen := l.pairs[pairKey]; seen {
+			if earliest := last.Add(l.PairInterval); earliest.After(at) {
+				at = earliest
+				pair = true
+			}
+		}
+	}
+
+	wait = at.Sub(now)
+	if wait > 0 && (l.Mode == SendLimitReject || (l.MaxWait > 0 && wait > l.MaxWait)) {
+		return wait, pair, false
+	}
+
+	if interval > 0 {
+		pb.tat = maxTime(pb.tat, at).Add(interval)
+	}
+	if l.PairInterval > 0 && recipient != "" {
+		l.pairs[pairKey] = at
+		l.sweepPairs(now)
+	}
+	return wait, pair, true
+}
+
+// sweepPairs drops pairs that can no longer delay a send, every 1024 sends.
+func (l *SendLimiter) sweepPairs(now time.Time) {
+	l.sweeps++
+	if l.sweeps%1024 != 0 {
+		return
+	}
+	for k, last := range l.pairs {
+		if now.Sub(last) >= l.PairInterval {
+			delete(l.pairs, k)
+		}
+	}
+}
+
+func (l *SendLimiter) record(w SendWait) {
+	l.mu.Lock()
+	st := l.stats[w.PhoneID]
+	if st == nil {
+		st = &SendLimiterStats{}
+		l.stats[w.PhoneID] = st
+	}
+	if w.Rejected {
+		st.Rejected++
+	} else {
+		st.Sent++
+		st.TotalWait += w.Wait
+		st.MaxWait = max(st.MaxWait, w.Wait)
+	}
+	l.mu.Unlock()
+
+	if l.OnSend != nil {
+		l.OnSend(w)
+	}
+}
+
+func recipientOf(msg *MessageObject) string {
+	if msg == nil {
+		return ""
+	}
+	if msg.To != "" {
+		return msg.To
+	}
+	return msg.Recipient
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,107 @@
+package fbgraph
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSendLimiterPhoneBudget(t *testing.T) {
+	l := &SendLimiter{PerPhoneRate: 10, Burst: 2}
+	now := time.Unix(1700000000, 0)
+
+	var waits []time.Duration
+	for range 4 {
+		w, _, ok := l.reserve("p1", "", now)
+		if !ok {
+			t.Fatal("queue mode must not reject")
+		}
+		waits = append(waits, w)
+	}
+	want := []time.Duration{0, 0, 100 * time.Millisecond, 200 * time.Millisecond}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Fatalf("waits = %v, want %v", waits, want)
+		}
+	}
+
+	// another phone number has its own budget
+	if w, _, _ := l.reserve("p2", "", now); w != 0 {
+		t.Fatalf("p2 wait = %v", w)
+	}
+}
+
+func TestSendLimiterPairGuard(t *testing.T) {
+	l := &SendLimiter{PairInterval: 6 * time.Second, Mode: SendLimitReject}
+	now := time.Unix(1700000000, 0)
+
+	if _, _, ok := l.reserve("p1", "5511", now); !ok {
+		t.Fatal("first send rejected")
+	}
+	if _, _, ok := l.reserve("p1", "5512", now); !ok {
+		t.Fatal("send to another recipient rejected")
+	}
+	w, pair, ok := l.reserve("p1", "5511", now.Add(2*time.Second))
+	if ok || !pair || w != 4*time.Second {
+		t.Fatalf("wait=%v pair=%v ok=%v", w, pair, ok)
+	}
+	if _, _, ok := l.reserve("p1", "5511", now.Add(6*time.Second)); !ok {
+		t.Fatal("send after the interval rejected")
+	}
+}
+
+func TestSendLimiterWrap(t *testing.T) {
+	var mu sync.Mutex
+	var seen []SendWait
+	l := &SendLimiter{
+		PairInterval: 30 * time.Millisecond,
+		OnSend: func(w SendWait) {
+			mu.Lock()
+			seen = append(seen, w)
+			mu.Unlock()
+		},
+	}
+	sent := 0
+	send := l.Wrap(func(ctx context.Context, phoneID string, msg *MessageObject) (*MessageObjectResult, error) {
+		sent++
+		return &MessageObjectResult{}, nil
+	})
+	msg := &MessageObject{To: "5511"}
+
+	start := time.Now()
+	for range 2 {
+		if _, err := send(context.Background(), "p1", msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if el := time.Since(start); el < 30*time.Millisecond {
+		t.Fatalf("second send went out after %v", el)
+	}
+	if sent != 2 || len(seen) != 2 || seen[1].Wait <= 0 || !seen[1].Pair {
+		t.Fatalf("sent=%d seen=%+v", sent, seen)
+	}
+
+	l.MaxWait = time.Millisecond
+	_, err := send(context.Background(), "p1", msg)
+	var rle *SendRateLimitError
+	if !errors.Is(err, ErrSendRateLimited) || !errors.As(err, &rle) || rle.RetryAfter <= 0 {
+		t.Fatalf("err = %v", err)
+	}
+	st := l.Stats()["p1"]
+	if st.Sent != 2 || st.Rejected != 1 || st.MaxWait <= 0 {
+		t.Fatalf("stats = %+v", st)
+	}
+}
+
+func TestSendLimiterWaitHonoursContext(t *testing.T) {
+	l := &SendLimiter{PerPhoneRate: 0.001}
+	_ = l.Wait(context.Background(), "p1", "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, "p1", ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package fbgraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// TypingIndicator is the typing_indicator of a read status update.
+type TypingIndicator struct {
+	Type string `json:"type"` // always "text"
+}
+
+// MessageStatusUpdate is the body the messages edge takes to change the
+// status of a received message.
+type MessageStatusUpdate struct {
+	MessagingProduct string           `json:"messaging_product"` // always "whatsapp"
+	Status           string           `json:"status"`            // always "read"
+	MessageID        string           `json:"message_id"`
+	TypingIndicator  *TypingIndicator `json:"typing_indicator,omitempty"`
+}
+
+// MarkAsRead marks a received message, and every earlier message of the
+// conversation, as read: the user sees blue check marks.
+//
+// See https://developers.facebook.com/docs/whatsapp/cloud-api/guides/mark-message-as-read
+func (c *Client) MarkAsRead(ctx context.Context, phoneID, messageID string) error {
+	return c.updateMessageStatus(ctx, phoneID, MessageStatusUpdate{
+		MessagingProduct: "whatsapp",
+		Status:           "read",
+		MessageID:        messageID,
+	})
+}
+
+// SendTypingIndicator marks a received message as read and shows the user a
+// typing indicator, meaning a reply to it is on its way. WhatsApp dismisses
+// the indicator when the reply is sent, or after 25 seconds; call it again to
+// keep it up.
+//
+// See https://developers.facebook.com/docs/whatsapp/cloud-api/typing-indicators
+func (c *Client) SendTypingIndicator(ctx context.Context, phoneID, messageID string) error {
+	return c.updateMessageStatus(ctx, phoneID, MessageStatusUpdate{
+		MessagingProduct: "whatsapp",
+		Status:           "read",
+		MessageID:        messageID,
+		TypingIndicator:  &TypingIndicator{Type: "text"},
+	})
+}
+
+func (c *Client) updateMessageStatus(ctx context.Context, phoneID string, update MessageStatusUpdate) error {
+	c.resetLastError()
+
+	if update.MessageID == "" {
+		return fmt.Errorf("message id is empty")
+	}
+
+	url := fmt.Sprintf("%s/%s/%s/messages", c.baseURL(), c.graphVersion(), phoneID)
+	jd, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jd))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return c.errorFromResponse(resp)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
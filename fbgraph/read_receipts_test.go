@@ -0,0 +1,34 @@
+package fbgraph_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph/fbgraphtest"
+)
+
+func TestMarkAsReadAndTypingIndicator(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	if err := c.MarkAsRead(ctx, "phone1", "wamid.A"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SendTypingIndicator(ctx, "phone1", "wamid.B"); err != nil {
+		t.Fatal(err)
+	}
+	got := srv.ReadReceipts()
+	want := []fbgraphtest.ReadReceipt{{PhoneID: "phone1", MessageID: "wamid.A"}, {PhoneID: "phone1", MessageID: "wamid.B", Typing: true}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("read receipts = %+v", got)
+	}
+	if len(srv.Messages()) != 0 {
+		t.Errorf("status updates were taken as sends: %+v", srv.Messages())
+	}
+
+	if err := c.MarkAsRead(ctx, "phone1", ""); err == nil {
+		t.Error("expected an error for an empty message id")
+	}
+}
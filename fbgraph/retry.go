@@ -0,0 +1,201 @@
+package fbgraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RetryPolicy makes a Client retry requests that failed for a transient
+// reason: a Graph error flagged is_transient, one of the codes in
+// RetryableErrorCodes, a 5xx without a Graph error body, or a transport error.
+//
+// Message sends and call connects are not idempotent (Meta does not
+// deduplicate them), so they are only retried when IdempotencyGuard allows it.
+// Requests whose body cannot be replayed (e.g. streamed media uploads) are
+// never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, the first one included.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry. It doubles on every
+	// retry, with jitter. Defaults to 500ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between attempts. Defaults to 30s.
+	MaxDelay time.Duration
+	// IdempotencyGuard is asked before resending a non-idempotent request.
+	// It should return true only when a duplicate is acceptable, e.g. when
+	// the caller deduplicates by biz_opaque_callback_data. req.GetBody
+	// returns the payload. A nil guard means such requests are never retried.
+	IdempotencyGuard func(req *http.Request, last Attempt) bool
+}
+
+// RetryableErrorCodes are the Graph error codes a RetryPolicy retries on even
+// when is_transient is not set.
+var RetryableErrorCodes = map[int]bool{
+	1:      true, // unknown error
+	2:      true, // service temporarily unavailable
+	4:      true, // application rate limit
+	80007:  true, // WABA rate limit
+	130429: true, // cloud API throughput reached
+	131000: true, // something went wrong
+	131016: true, // service unavailable
+}
+
+// Attempt describes one try of a request.
+type Attempt struct {
+	Number     int    // 1 for the first try
+	Method     string // HTTP method
+	URL        string
+	StatusCode int         // zero when no response was received
+	GraphError *GraphError // the error in the response body, if any
+	Err        error       // transport error, if any
+	// Delay is how long the client waited before the next attempt. Zero when
+	// this was the last one.
+	Delay time.Duration
+}
+
+// Retried reports whether the client tried again after this attempt.
+func (a Attempt) Retried() bool {
+	return a.Delay > 0
+}
+
+// AttemptLog collects the attempts of the requests made with a context
+// returned by WithAttemptLog.
+type AttemptLog struct {
+	mu       sync.Mutex
+	attempts []Attempt
+}
+
+type attemptLogKey struct{}
+
+// WithAttemptLog returns a context that records every attempt made by the
+// client calls it is passed to:
+//
+//	ctx, log := fbgraph.WithAttemptLog(ctx)
+//	_, err := c.GetMediaWithContext(ctx, id)
+//	for _, a := range log.Attempts() { ... }
+func WithAttemptLog(ctx context.Context) (context.Context, *AttemptLog) {
+	l := &AttemptLog{}
+	return context.WithValue(ctx, attemptLogKey{}, l), l
+}
+
+// Attempts returns the recorded attempts, oldest first.
+func (l *AttemptLog) Attempts() []Attempt {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Attempt(nil), l.attempts...)
+}
+
+func (l *AttemptLog) add(a Attempt) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.attempts = append(l.attempts, a)
+	l.mu.Unlock()
+}
+
+func attemptLogFrom(ctx context.Context) *AttemptLog {
+	l, _ := ctx.Value(attemptLogKey{}).(*AttemptLog)
+	return l
+}
+
+// nonIdempotentEdges are POST edges that must not be replayed blindly.
+// request_code is among them because every replay sends the user another
+// code and spends the number's verification attempts; message_qrdls because
+// a replayed create makes a second QR code.
+var nonIdempotentEdges = []string{"/messages", "/marketing_messages", "/calls", "/request_code", "/message_qrdls"}
+
+func isNonIdempotent(req *http.Request) bool {
+	if req.Method != http.MethodPost {
+		return false
+	}
+	for _, e := range nonIdempotentEdges {
+		if strings.HasSuffix(req.URL.Path, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) shouldRetry(req *http.Request, a Attempt) bool {
+	if p == nil || a.Number >= p.MaxAttempts || req.Context().Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch {
+	case a.Err != nil:
+	case a.GraphError != nil:
+		if !a.GraphError.IsTransient && !RetryableErrorCodes[a.GraphError.Code] {
+			return false
+		}
+	case a.StatusCode < 500:
+		return false
+	}
+	if isNonIdempotent(req) {
+		return p.IdempotencyGuard != nil && p.IdempotencyGuard(req, a)
+	}
+	return true
+}
+
+// delay returns the wait after attempt n: exponential with jitter in its
+// upper half, or Retry-After when the server asks for longer.
+func (p *RetryPolicy) delay(n int, resp *http.Response) time.Duration {
+	base, maxd := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = 500 * time.Millisecond
+	}
+	if maxd <= 0 {
+		maxd = 30 * time.Second
+	}
+	d := base << (n - 1)
+	if d <= 0 || d > maxd {
+		d = maxd
+	}
+	d = d/2 + rand.N(d/2+1)
+	if resp != nil {
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+			d = max(d, min(time.Duration(s)*time.Second, maxd))
+		}
+	}
+	return d
+}
+
+// peekGraphError reads the Graph error in an error response, leaving the
+// body readable for errorFromResponse.
+func peekGraphError(resp *http.Response) *GraphError {
+	raw, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+
+	eparent := struct {
+		Error GraphError `json:"error"`
+	}{}
+	if err := json.Unmarshal(raw, &eparent); err != nil || eparent.Error.Code == 0 {
+		return nil
+	}
+	eparent.Error.HTTPStatusCode = resp.StatusCode
+	eparent.Error.RawBody = string(raw)
+	return &eparent.Error
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
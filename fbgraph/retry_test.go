@@ -0,0 +1,148 @@
+package fbgraph
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingServer answers the first len(bodies) requests with status and the
+// given bodies, then succeeds.
+func failingServer(t *testing.T, status int, bodies ...string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(n.Add(1)) - 1
+		if i < len(bodies) {
+			w.WriteHeader(status)
+			_, _ = fmt.Fprint(w, bodies[i])
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"id":"m1","url":"https://example.com/m1","messages":[{"id":"wamid.1"}]}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &n
+}
+
+func retryClient(url string, p *RetryPolicy) *Client {
+	c := NewClient("tok")
+	c.BaseURL = url
+	c.Retry = p
+	return c
+}
+
+func TestRetryTransientGraphError(t *testing.T) {
+	srv, n := failingServer(t, http.StatusServiceUnavailable,
+		`{"error":{"message":"Service temporarily unavailable","code":2,"fbtrace_id":"a"}}`,
+		`{"error":{"message":"Cloud API throughput","code":130429,"fbtrace_id":"b"}}`)
+	c := retryClient(srv.URL, &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	ctx, log := WithAttemptLog(context.Background())
+	if _, err := c.GetMediaWithContext(ctx, "m1"); err != nil {
+		t.Fatalf("GetMedia: %v", err)
+	}
+	if n.Load() != 3 {
+		t.Fatalf("server saw %d requests, want 3", n.Load())
+	}
+	attempts := log.Attempts()
+	if len(attempts) != 3 {
+		t.Fatalf("logged %d attempts, want 3", len(attempts))
+	}
+	if attempts[0].GraphError == nil || attempts[0].GraphError.Code != 2 || !attempts[0].Retried() {
+		t.Errorf("attempt 1 = %+v", attempts[0])
+	}
+	if attempts[2].StatusCode != http.StatusOK || attempts[2].Retried() {
+		t.Errorf("attempt 3 = %+v", attempts[2])
+	}
+}
+
+func TestRetryBare5xxAndExhaustion(t *testing.T) {
+	srv, n := failingServer(t, http.StatusBadGateway, "<html>bad gateway</html>", "<html>bad gateway</html>", "<html>bad gateway</html>")
+	c := retryClient(srv.URL, &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})
+
+	_, err := c.GetMediaWithContext(context.Background(), "m1")
+	if err == nil {
+		t.Fatal("expected an error once attempts are exhausted")
+	}
+	if n.Load() != 2 {
+		t.Fatalf("server saw %d requests, want 2", n.Load())
+	}
+}
+
+func TestRetryPermanentErrorIsNotRetried(t *testing.T) {
+	srv, n := failingServer(t, http.StatusBadRequest, `{"error":{"message":"Invalid parameter","code":100,"fbtrace_id":"a"}}`)
+	c := retryClient(srv.URL, &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond})
+
+	_, err := c.GetMediaWithContext(context.Background(), "m1")
+	if ge, ok := AsGraphError(err); !ok || ge.Code != 100 {
+		t.Fatalf("err = %v", err)
+	}
+	if n.Load() != 1 {
+		t.Fatalf("server saw %d requests, want 1", n.Load())
+	}
+}
+
+func TestRetrySendNeedsIdempotencyGuard(t *testing.T) {
+	const busy = `{"error":{"message":"Something went wrong","code":131000,"fbtrace_id":"a"}}`
+	msg := &MessageObject{MessagingProduct: "whatsapp", To: "1", Type: "text", Text: &TextObject{Body: "hi"}}
+
+	srv, n := failingServer(t, http.StatusInternalServerError, busy)
+	c := retryClient(srv.URL, &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+	if _, err := c.SendMessageWithContext(context.Background(), "123", msg); err == nil {
+		t.Fatal("a send without a guard must not be retried")
+	}
+	if n.Load() != 1 {
+		t.Fatalf("server saw %d requests, want 1", n.Load())
+	}
+
+	srv, n = failingServer(t, http.StatusInternalServerError, busy)
+	var guarded int
+	c = retryClient(srv.URL, &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		IdempotencyGuard: func(req *http.Request, last Attempt) bool {
+			guarded++
+			return last.GraphError != nil && last.GraphError.Code == 131000
+		},
+	})
+	res, err := c.SendMessageWithContext(context.Background(), "123", msg)
+	if err != nil {
+		t.Fatalf("guarded send: %v", err)
+	}
+	if res.Messages[0].ID != "wamid.1" || guarded != 1 || n.Load() != 2 {
+		t.Fatalf("res=%+v guarded=%d requests=%d", res, guarded, n.Load())
+	}
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	srv, n := failingServer(t, http.StatusServiceUnavailable, `{"error":{"message":"x","code":2,"is_transient":true}}`)
+	c := retryClient(srv.URL, &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := c.GetMediaWithContext(ctx, "m1")
+	if ge, ok := AsGraphError(err); !ok || ge.Code != 2 {
+		t.Fatalf("err = %v, want the last Graph error", err)
+	}
+	if n.Load() != 1 {
+		t.Fatalf("server saw %d requests, want 1", n.Load())
+	}
+}
+
+func TestRetryDelayIsBounded(t *testing.T) {
+	p := &RetryPolicy{BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+	for n := 1; n <= 70; n++ {
+		d := p.delay(n, nil)
+		if d < 0 || d > 4*time.Second {
+			t.Fatalf("delay(%d) = %v", n, d)
+		}
+	}
+	resp := &http.Response{Header: http.Header{"Retry-After": {"3"}}}
+	if d := p.delay(1, resp); d < 3*time.Second {
+		t.Fatalf("Retry-After ignored: %v", d)
+	}
+}
@@ -29,7 +29,7 @@ func (c *Client) PostSMBAppData(ctx context.Context, whatsappID string, syncType
 		apiVersion = c.GraphAPIVersion
 	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/smb_app_data", apiVersion, whatsappID)
+	url := fmt.Sprintf("%s/%s/%s/smb_app_data", c.baseURL(), apiVersion, whatsappID)
 
 	buf := new(bytes.Buffer)
 	if err := json.NewEncoder(buf).Encode(map[string]any{
@@ -46,7 +46,7 @@ func (c *Client) PostSMBAppData(ctx context.Context, whatsappID string, syncType
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -0,0 +1,347 @@
+package fbgraph
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TemplateSend builds the TemplateObject of a send from the template's
+// definition, as fetched with GetMessageTemplate or MessageTemplates. Values
+// are keyed by variable: "1", "2", ... for positional templates and the
+// variable name (e.g. "first_name") for templates with ParameterFormatNamed.
+//
+//	tpl, err := fbgraph.NewTemplateSend(def).
+//		BodyText("first_name", "Ana").
+//		Body("total", fbgraph.CurrencyParameter("R$ 10,00", "BRL", 10000)).
+//		ButtonURL(0, "abc123").
+//		Build()
+//
+// Build fails if a variable of the template is left without a value, or if a
+// value was set for something the template does not have.
+type TemplateSend struct {
+	def        MessageTemplate
+	components []MessageTemplateComponent
+	named      bool
+
+	header  map[string]TemplateComponentParameter
+	media   *TemplateComponentParameter
+	body    map[string]TemplateComponentParameter
+	buttons map[int]TemplateComponentParameter
+	cards   map[int]*TemplateSend
+}
+
+// NewTemplateSend starts a send of the template def.
+func NewTemplateSend(def MessageTemplate) *TemplateSend {
+	return newTemplateSend(def, def.Components)
+}
+
+func newTemplateSend(def MessageTemplate, components []MessageTemplateComponent) *TemplateSend {
+	return &TemplateSend{
+		def:        def,
+		components: components,
+		named:      strings.EqualFold(string(def.ParameterFormat), string(ParameterFormatNamed)),
+		header:     make(map[string]TemplateComponentParameter),
+		body:       make(map[string]TemplateComponentParameter),
+		buttons:    make(map[int]TemplateComponentParameter),
+		cards:      make(map[int]*TemplateSend),
+	}
+}
+
+// Header sets the value of a text header variable.
+func (s *TemplateSend) Header(key string, p TemplateComponentParameter) *TemplateSend {
+	s.header[key] = p
+	return s
+}
+
+// HeaderText is Header with a text parameter.
+func (s *TemplateSend) HeaderText(key, text string) *TemplateSend {
+	return s.Header(key, TextParameter(text))
+}
+
+// HeaderMedia sets the image, video or document of a media header.
+func (s *TemplateSend) HeaderMedia(p TemplateComponentParameter) *TemplateSend {
+	s.media = &p
+	return s
+}
+
+// Body sets the value of a body variable.
+func (s *TemplateSend) Body(key string, p TemplateComponentParameter) *TemplateSend {
+	s.body[key] = p
+	return s
+}
+
+// BodyText is Body with a text parameter.
+func (s *TemplateSend) BodyText(key, text string) *TemplateSend {
+	return s.Body(key, TextParameter(text))
+}
+
+// ButtonURL sets the suffix of the dynamic URL button at index (0-based, in
+// the order of the template's buttons).
+func (s *TemplateSend) ButtonURL(index int, suffix string) *TemplateSend {
+	s.buttons[index] = TextParameter(suffix)
+	return s
+}
+
+// ButtonPayload sets the payload sent back when the quick reply button at
+// index is tapped.
+func (s *TemplateSend) ButtonPayload(index int, payload string) *TemplateSend {
+	s.buttons[index] = TemplateComponentParameter{Type: "payload", Payload: payload}
+	return s
+}
+
+// ButtonCode sets the one-time password of the OTP button at index.
+func (s *TemplateSend) ButtonCode(index int, code string) *TemplateSend {
+	s.buttons[index] = TextParameter(code)
+	return s
+}
+
+// Card returns the builder of the carousel card at index (0-based). Its
+// values are set the same way as the template's.
+func (s *TemplateSend) Card(index int) *TemplateSend {
+	if c, ok := s.cards[index]; ok {
+		return c
+	}
+	var components []MessageTemplateComponent
+	for _, c := range s.components {
+		if c.Type.Equals(MTComponentCarousel) && index >= 0 && index < len(c.Cards) {
+			for _, cc := range c.Cards[index].Components {
+				components = append(components, MessageTemplateComponent{
+					Type:    cc.Type,
+					Format:  cc.Format,
+					Text:    cc.Text,
+					Example: cc.Example,
+					Buttons: cc.Buttons,
+				})
+			}
+		}
+	}
+	card := newTemplateSend(s.def, components)
+	s.cards[index] = card
+	return card
+}
+
+// Build returns the TemplateObject to send, or an error listing every missing
+// or unexpected value.
+func (s *TemplateSend) Build() (*TemplateObject, error) {
+	components, errs := s.build("")
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("template %s: %w", s.def.Name, err)
+	}
+	return &TemplateObject{
+		Name:       s.def.Name,
+		Language:   &LanguageObject{Code: s.def.Language},
+		Components: components,
+	}, nil
+}
+
+func (s *TemplateSend) build(path string) ([]TemplateComponent, []error) {
+	var (
+		out  []TemplateComponent
+		errs []error
+	)
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(path+format, args...))
+	}
+
+	var hasTextHeader, hasMediaHeader, hasBody, hasCarousel bool
+	buttons := make(map[int]bool)
+	for _, c := range s.components {
+		switch {
+		case c.Type.Equals(MTComponentHeader):
+			switch f := MessageTemplateComponentFormat(strings.ToUpper(c.Format)); f {
+			case "", MTCFormatText:
+				hasTextHeader = true
+				if params := s.variableParams(c.Text, s.header, "header", fail); len(params) > 0 {
+					out = append(out, TemplateComponent{Type: "header", Parameters: params})
+				}
+			case MTCFormatImage, MTCFormatVideo, MTCFormatDocument:
+				hasMediaHeader = true
+				switch {
+				case s.media == nil:
+					fail("header: a %s is required", strings.ToLower(string(f)))
+				case !strings.EqualFold(s.media.Type, string(f)):
+					fail("header: a %s is required, got %s", strings.ToLower(string(f)), s.media.Type)
+				default:
+					out = append(out, TemplateComponent{Type: "header", Parameters: []TemplateComponentParameter{*s.media}})
+				}
+			}
+		case c.Type.Equals(MTComponentBody):
+			hasBody = true
+			if params := s.variableParams(c.Text, s.body, "body", fail); len(params) > 0 {
+				out = append(out, TemplateComponent{Type: "body", Parameters: params})
+			}
+		case c.Type.Equals(MTComponentButtons):
+			for i, b := range c.Buttons {
+				buttons[i] = true
+				p, ok := s.buttons[i]
+				var subType string
+				switch MessageTemplateButtonType(strings.ToUpper(string(b.Type))) {
+				case MTBTypeURL:
+					if !strings.Contains(b.URL, "{{") {
+						if ok {
+							fail("buttons[%d]: URL %q has no variable", i, b.URL)
+						}
+						continue
+					}
+					if !ok {
+						fail("buttons[%d]: a URL suffix is required", i)
+						continue
+					}
+					subType = "url"
+				case MTBTypeOTP:
+					if !ok {
+						fail("buttons[%d]: a one-time password is required", i)
+						continue
+					}
+					subType = "url"
+				case MTBTypeQuickReply:
+					if !ok {
+						continue
+					}
+					subType = "quick_reply"
+				default:
+					if ok {
+						fail("buttons[%d]: %s buttons take no value", i, b.Type)
+					}
+					continue
+				}
+				idx := i
+				out = append(out, TemplateComponent{Type: "button", SubType: subType, Index: &idx, Parameters: []TemplateComponentParameter{p}})
+			}
+		case c.Type.Equals(MTComponentCarousel):
+			hasCarousel = true
+			carousel := TemplateComponent{Type: "carousel", Parameters: []TemplateComponentParameter{}}
+			for i := range c.Cards {
+				cardComponents, cardErrs := s.Card(i).build(fmt.Sprintf("%scards[%d].", path, i))
+				errs = append(errs, cardErrs...)
+				idx := i
+				carousel.Cards = append(carousel.Cards, TemplateCardComponent{CardIndex: &idx, Components: cardComponents})
+			}
+			out = append(out, carousel)
+		}
+	}
+
+	if !hasTextHeader && len(s.header) > 0 {
+		fail("header: the template has no text header")
+	}
+	if !hasMediaHeader && s.media != nil {
+		fail("header: the template has no media header")
+	}
+	if !hasBody && len(s.body) > 0 {
+		fail("body: the template has no body")
+	}
+	for _, i := range sortedKeys(s.buttons) {
+		if !buttons[i] {
+			fail("buttons[%d]: the template has no such button", i)
+		}
+	}
+	for _, i := range sortedKeys(s.cards) {
+		if !hasCarousel || s.Card(i).components == nil {
+			fail("cards[%d]: the template has no such card", i)
+		}
+	}
+	return out, errs
+}
+
+// variableParams returns the parameters for the variables of text, in the
+// order the Cloud API expects them, reporting missing and unknown keys.
+func (s *TemplateSend) variableParams(text string, values map[string]TemplateComponentParameter, path string, fail func(string, ...any)) []TemplateComponentParameter {
+	keys := s.variableKeys(text)
+	params := make([]TemplateComponentParameter, 0, len(keys))
+	for _, k := range keys {
+		p, ok := values[k]
+		if !ok {
+			fail("%s: a value for {{%s}} is required", path, k)
+			continue
+		}
+		if s.named {
+			p.ParameterName = k
+		}
+		params = append(params, p)
+	}
+	for _, k := range sortedKeys(values) {
+		if !slices.Contains(keys, k) {
+			fail("%s: the template has no {{%s}} variable", path, k)
+		}
+	}
+	return params
+}
+
+// variableKeys returns the variables of text: "1".."n" for positional
+// templates, the names in order of first use for named ones.
+func (s *TemplateSend) variableKeys(text string) []string {
+	var keys []string
+	highest := 0
+	for _, m := range templateVariableRegexp.FindAllStringSubmatch(text, -1) {
+		k := strings.TrimSpace(m[1])
+		if !s.named {
+			if n, err := strconv.Atoi(k); err == nil && n > highest {
+				highest = n
+			}
+			continue
+		}
+		if !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	for n := 1; n <= highest; n++ {
+		keys = append(keys, strconv.Itoa(n))
+	}
+	return keys
+}
+
+func sortedKeys[K string | int, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// TextParameter is a text template parameter.
+func TextParameter(text string) TemplateComponentParameter {
+	return TemplateComponentParameter{Type: "text", Text: text}
+}
+
+// CurrencyParameter is a currency template parameter. amount1000 is the amount
+// multiplied by 1000; fallback is shown where the amount can't be localized.
+func CurrencyParameter(fallback, code string, amount1000 float64) TemplateComponentParameter {
+	return TemplateComponentParameter{Type: "currency", Currency: &CurrencyParameters{FallbackValue: fallback, Code: code, Amount1000: amount1000}}
+}
+
+// DateTimeParameter is a date_time template parameter.
+func DateTimeParameter(fallback string) TemplateComponentParameter {
+	return TemplateComponentParameter{Type: "date_time", DateTime: &DateTimeParameters{FallbackValue: fallback}}
+}
+
+// ImageParameter is an image header parameter; media is a link or an uploaded
+// media ID.
+func ImageParameter(media string) TemplateComponentParameter {
+	id, link := splitMediaRef(media)
+	return TemplateComponentParameter{Type: "image", Image: &ImageParameters{ID: id, Link: link}}
+}
+
+// VideoParameter is a video header parameter; media is a link or an uploaded
+// media ID.
+func VideoParameter(media string) TemplateComponentParameter {
+	id, link := splitMediaRef(media)
+	return TemplateComponentParameter{Type: "video", Video: &VideoParameters{ID: id, Link: link}}
+}
+
+// DocumentParameter is a document header parameter; media is a link or an
+// uploaded media ID.
+func DocumentParameter(media, filename string) TemplateComponentParameter {
+	id, link := splitMediaRef(media)
+	return TemplateComponentParameter{Type: "document", Document: &MediaObject{ID: id, Link: link, Filename: filename}}
+}
+
+func splitMediaRef(media string) (id, link string) {
+	if strings.Contains(media, "://") {
+		return "", media
+	}
+	return media, ""
+}
@@ -0,0 +1,102 @@
+package fbgraph
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTemplateSendPositional(t *testing.T) {
+	def := validTemplate().MessageTemplate
+	tpl, err := NewTemplateSend(def).
+		HeaderText("1", "#42").
+		BodyText("1", "Ana").
+		Body("2", DateTimeParameter("10/05")).
+		ButtonURL(0, "abc").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(tpl)
+	want := `{"name":"order_shipped","language":{"code":"pt_BR"},"components":[` +
+		`{"type":"header","parameters":[{"type":"text","text":"#42"}]},` +
+		`{"type":"body","parameters":[{"type":"text","text":"Ana"},{"type":"date_time","date_time":{"fallback_value":"10/05"}}]},` +
+		`{"type":"button","sub_type":"url","parameters":[{"type":"text","text":"abc"}],"index":0}]}`
+	if string(b) != want {
+		t.Errorf("got  %s\nwant %s", b, want)
+	}
+}
+
+func TestTemplateSendNamed(t *testing.T) {
+	def := MessageTemplate{Name: "welcome", Language: "pt_BR", ParameterFormat: ParameterFormatNamed, Components: []MessageTemplateComponent{
+		{Type: MTComponentHeader, Format: "IMAGE"},
+		{Type: MTComponentBody, Text: "Olá {{first_name}}, use o cupom {{coupon}}. Até logo, {{first_name}}!"},
+	}}
+	tpl, err := NewTemplateSend(def).
+		HeaderMedia(ImageParameter("https://cdn.example.com/a.jpg")).
+		BodyText("coupon", "BEMVINDO").
+		BodyText("first_name", "Ana").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := tpl.Components[1].Parameters
+	if len(body) != 2 || body[0].ParameterName != "first_name" || body[0].Text != "Ana" || body[1].ParameterName != "coupon" {
+		t.Errorf("body parameters = %+v", body)
+	}
+	if h := tpl.Components[0].Parameters[0]; h.Image == nil || h.Image.Link != "https://cdn.example.com/a.jpg" {
+		t.Errorf("header parameter = %+v", h)
+	}
+}
+
+func TestTemplateSendCarousel(t *testing.T) {
+	card := MessageTemplateCard{Components: []MessageTemplateCardComponent{
+		{Type: MTComponentHeader, Format: "IMAGE"},
+		{Type: MTComponentBody, Text: "{{1}} por {{2}}"},
+		{Type: MTComponentButtons, Buttons: []MessageTemplateButton{{Type: MTBTypeQuickReply, Text: "Quero"}}},
+	}}
+	def := MessageTemplate{Name: "offers", Language: "pt_BR", Components: []MessageTemplateComponent{
+		{Type: MTComponentBody, Text: "Ofertas da semana"},
+		{Type: MTComponentCarousel, Cards: []MessageTemplateCard{card, card}},
+	}}
+	send := NewTemplateSend(def)
+	for i, name := range []string{"Tênis", "Camisa"} {
+		send.Card(i).HeaderMedia(ImageParameter("4490709327384033")).BodyText("1", name).BodyText("2", "R$ 50").ButtonPayload(0, name)
+	}
+	tpl, err := send.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cards := tpl.Components[0].Cards
+	if len(cards) != 2 || *cards[1].CardIndex != 1 || cards[1].Components[1].Parameters[0].Text != "Camisa" {
+		t.Fatalf("cards = %+v", cards)
+	}
+	if p := cards[0].Components[0].Parameters[0]; p.Image.ID != "4490709327384033" {
+		t.Errorf("card header = %+v", p.Image)
+	}
+	if b := cards[0].Components[2]; b.SubType != "quick_reply" || b.Parameters[0].Payload != "Tênis" {
+		t.Errorf("card button = %+v", b)
+	}
+}
+
+func TestTemplateSendRejectsMissingValues(t *testing.T) {
+	_, err := NewTemplateSend(validTemplate().MessageTemplate).
+		BodyText("1", "Ana").
+		BodyText("first_name", "Ana").
+		ButtonPayload(5, "x").
+		Build()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		"header: a value for {{1}} is required",
+		"body: a value for {{2}} is required",
+		"body: the template has no {{first_name}} variable",
+		"buttons[0]: a URL suffix is required",
+		"buttons[5]: the template has no such button",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
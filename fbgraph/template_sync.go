@@ -0,0 +1,346 @@
+package fbgraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TemplateSyncAction is a change a TemplateSyncPlan makes to a WABA.
+type TemplateSyncAction string
+
+const (
+	TemplateSyncCreate           TemplateSyncAction = "create"
+	TemplateSyncUnarchive        TemplateSyncAction = "unarchive"
+	TemplateSyncUpdateComponents TemplateSyncAction = "update_components"
+	TemplateSyncUpdateCategory   TemplateSyncAction = "update_category"
+	TemplateSyncDelete           TemplateSyncAction = "delete"
+)
+
+// TemplateSyncOptions tune PlanTemplateSync.
+type TemplateSyncOptions struct {
+	// Prune deletes remote templates whose name is not defined locally in any
+	// language. DeleteMessageTemplate works by name, so a name that is still
+	// defined in some language is never deleted.
+	Prune bool
+	// SkipCategory leaves category differences alone. Meta recategorizes
+	// templates on its own, so a local category may never stick.
+	SkipCategory bool
+}
+
+// TemplateSyncItem is one step of a TemplateSyncPlan.
+type TemplateSyncItem struct {
+	Action   TemplateSyncAction
+	Name     string
+	Language string
+	// TemplateID is the remote template ID. Empty for creates.
+	TemplateID string
+	// Local is the definition the step applies. Nil for deletes.
+	Local *NewMessageTemplate
+	// Remote is the template as found on the WABA. Nil for creates.
+	Remote *MessageTemplate
+	// Detail says why the step is needed, e.g. which components differ.
+	Detail string
+}
+
+func (it TemplateSyncItem) String() string {
+	s := fmt.Sprintf("%s %s (%s)", it.Action, it.Name, it.Language)
+	if it.Detail != "" {
+		s += ": " + it.Detail
+	}
+	return s
+}
+
+// TemplateSyncPlan is the difference between local template definitions and a
+// WABA, as computed by PlanTemplateSync. Items are in the order ApplyTemplateSync
+// runs them: unarchives come before the edits they enable.
+type TemplateSyncPlan struct {
+	WABAID    string
+	Items     []TemplateSyncItem
+	Unchanged []string // "name (language)" of templates already in sync
+}
+
+// Empty reports whether the plan has nothing to do.
+func (p *TemplateSyncPlan) Empty() bool {
+	return len(p.Items) == 0
+}
+
+// String renders the plan for review, one step per line.
+func (p *TemplateSyncPlan) String() string {
+	sb := new(strings.Builder)
+	counts := make(map[TemplateSyncAction]int)
+	for _, it := range p.Items {
+		counts[it.Action]++
+	}
+	fmt.Fprintf(sb, "WABA %s: %d create, %d unarchive, %d update, %d delete, %d unchanged\n",
+		p.WABAID, counts[TemplateSyncCreate], counts[TemplateSyncUnarchive],
+		counts[TemplateSyncUpdateComponents]+counts[TemplateSyncUpdateCategory],
+		counts[TemplateSyncDelete], len(p.Unchanged))
+	for _, it := range p.Items {
+		mark := "~"
+		switch it.Action {
+		case TemplateSyncCreate:
+			mark = "+"
+		case TemplateSyncDelete:
+			mark = "-"
+		}
+		fmt.Fprintf(sb, "%s %s\n", mark, it)
+	}
+	return sb.String()
+}
+
+// PlanTemplateSync fetches the templates of a WABA and compares them, by name
+// and language, with local. Component examples are not compared, as Meta does
+// not always return them as sent.
+func (c *Client) PlanTemplateSync(ctx context.Context, wabaID string, local []NewMessageTemplate, opts TemplateSyncOptions) (*TemplateSyncPlan, error) {
+	remote, err := c.AllMessageTemplates(ctx, GetMessageTemplatesParameters{WhatsAppBusinessAccountID: wabaID})
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+	return planTemplateSync(wabaID, local, remote, opts)
+}
+
+func templateKey(name, language string) string {
+	return name + " (" + language + ")"
+}
+
+func planTemplateSync(wabaID string, local []NewMessageTemplate, remote []MessageTemplate, opts TemplateSyncOptions) (*TemplateSyncPlan, error) {
+	plan := &TemplateSyncPlan{WABAID: wabaID}
+
+	remoteByKey := make(map[string]*MessageTemplate, len(remote))
+	for i := range remote {
+		remoteByKey[templateKey(remote[i].Name, remote[i].Language)] = &remote[i]
+	}
+	localNames := make(map[string]bool, len(local))
+	seen := make(map[string]bool, len(local))
+
+	var unarchives, edits, creates []TemplateSyncItem
+	for i := range local {
+		l := &local[i]
+		key := templateKey(l.Name, l.Language)
+		if seen[key] {
+			return nil, fmt.Errorf("template %s is defined twice", key)
+		}
+		seen[key] = true
+		localNames[l.Name] = true
+
+		r, ok := remoteByKey[key]
+		if !ok {
+			creates = append(creates, TemplateSyncItem{Action: TemplateSyncCreate, Name: l.Name, Language: l.Language, Local: l})
+			continue
+		}
+		item := TemplateSyncItem{Name: l.Name, Language: l.Language, TemplateID: r.ID, Local: l, Remote: r}
+		changed := false
+		if r.Status == MessageTemplateStatusArchived {
+			item.Action = TemplateSyncUnarchive
+			unarchives = append(unarchives, item)
+			changed = true
+		}
+		if diff := diffTemplateComponents(l.Components, r.Components); diff != "" {
+			item.Action, item.Detail = TemplateSyncUpdateComponents, diff
+			edits = append(edits, item)
+			changed = true
+		}
+		if !opts.SkipCategory && !strings.EqualFold(string(l.Category), string(r.Category)) {
+			item.Action, item.Detail = TemplateSyncUpdateCategory, fmt.Sprintf("%s -> %s", r.Category, l.Category)
+			edits = append(edits, item)
+			changed = true
+		}
+		if !changed {
+			plan.Unchanged = append(plan.Unchanged, key)
+		}
+	}
+
+	plan.Items = append(plan.Items, unarchives...)
+	plan.Items = append(plan.Items, edits...)
+	plan.Items = append(plan.Items, creates...)
+
+	if opts.Prune {
+		deleted := make(map[string]bool)
+		for i := range remote {
+			r := &remote[i]
+			if localNames[r.Name] || deleted[r.Name] {
+				continue
+			}
+			deleted[r.Name] = true
+			plan.Items = append(plan.Items, TemplateSyncItem{Action: TemplateSyncDelete, Name: r.Name, Language: r.Language, TemplateID: r.ID, Remote: r, Detail: "all languages"})
+		}
+	}
+	return plan, nil
+}
+
+// diffTemplateComponents describes how remote differs from local, or returns
+// "" when they match.
+func diffTemplateComponents(local, remote []MessageTemplateComponent) string {
+	lc, rc := normalizeTemplateComponents(local), normalizeTemplateComponents(remote)
+	if len(lc) != len(rc) {
+		return fmt.Sprintf("%d components, remote has %d", len(lc), len(rc))
+	}
+	var diffs []string
+	for i := range lc {
+		if !reflect.DeepEqual(lc[i], rc[i]) {
+			diffs = append(diffs, strings.ToLower(string(lc[i].Type)))
+		}
+	}
+	if len(diffs) == 0 {
+		return ""
+	}
+	return strings.Join(diffs, ", ") + " changed"
+}
+
+// normalizeTemplateComponents drops what Meta echoes back differently from what
+// was sent: examples and casing of enum values.
+func normalizeTemplateComponents(in []MessageTemplateComponent) []MessageTemplateComponent {
+	// a JSON round trip gives a deep copy with nil/empty slices unified
+	raw, _ := json.Marshal(in)
+	var out []MessageTemplateComponent
+	_ = json.Unmarshal(raw, &out)
+	for i := range out {
+		c := &out[i]
+		c.Type = MessageTemplateComponentType(strings.ToUpper(string(c.Type)))
+		c.Format = strings.ToUpper(c.Format)
+		c.Example = nil
+		for j := range c.Buttons {
+			c.Buttons[j].Example = nil
+		}
+		for j := range c.Cards {
+			for k := range c.Cards[j].Components {
+				cc := &c.Cards[j].Components[k]
+				cc.Type = MessageTemplateComponentType(strings.ToUpper(string(cc.Type)))
+				cc.Format = strings.ToUpper(cc.Format)
+				cc.Example = nil
+				for b := range cc.Buttons {
+					cc.Buttons[b].Example = nil
+				}
+			}
+		}
+	}
+	return out
+}
+
+// TemplateSyncStatus is the outcome of a TemplateSyncItem.
+type TemplateSyncStatus string
+
+const (
+	TemplateSyncApplied TemplateSyncStatus = "applied"
+	TemplateSyncFailed  TemplateSyncStatus = "failed"
+	// TemplateSyncDeferred items were not attempted because Meta would refuse
+	// them now: the template is in review, or it is approved and was already
+	// edited in this run (approved templates may be edited once a day). Run
+	// the sync again later.
+	TemplateSyncDeferred TemplateSyncStatus = "deferred"
+)
+
+// TemplateSyncResult is the outcome of one TemplateSyncItem.
+type TemplateSyncResult struct {
+	TemplateSyncItem
+	Status TemplateSyncStatus
+	// Reason explains a deferral.
+	Reason string
+	Err    error
+}
+
+// ApplyTemplateSync runs every step of plan, carrying on past failures, and
+// returns one result per item, in plan order. Unarchives are sent as one batch.
+func (c *Client) ApplyTemplateSync(ctx context.Context, plan *TemplateSyncPlan) []TemplateSyncResult {
+	results := make([]TemplateSyncResult, len(plan.Items))
+	for i, it := range plan.Items {
+		results[i] = TemplateSyncResult{TemplateSyncItem: it}
+	}
+
+	// status tracks the remote status as the steps change it
+	status := make(map[string]string)
+	edited := make(map[string]bool)
+	for _, it := range plan.Items {
+		if it.Remote != nil {
+			status[it.TemplateID] = it.Remote.Status
+		}
+	}
+
+	var unarchiveIDs []string
+	for _, it := range plan.Items {
+		if it.Action == TemplateSyncUnarchive {
+			unarchiveIDs = append(unarchiveIDs, it.TemplateID)
+		}
+	}
+	if len(unarchiveIDs) > 0 {
+		unarchived, failed, err := c.UnarchiveMessageTemplates(ctx, plan.WABAID, unarchiveIDs)
+		ok := make(map[string]bool, len(unarchived))
+		for _, id := range unarchived {
+			ok[id] = true
+		}
+		for i := range results {
+			r := &results[i]
+			if r.Action != TemplateSyncUnarchive {
+				continue
+			}
+			switch {
+			case err != nil:
+				r.Status, r.Err = TemplateSyncFailed, err
+			case ok[r.TemplateID]:
+				r.Status = TemplateSyncApplied
+				status[r.TemplateID] = "APPROVED"
+			default:
+				r.Status, r.Err = TemplateSyncFailed, fmt.Errorf("unarchive %s: %s", r.TemplateID, failed[r.TemplateID])
+			}
+		}
+	}
+
+	for i := range results {
+		r := &results[i]
+		if r.Action == TemplateSyncUnarchive {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			r.Status, r.Err = TemplateSyncFailed, err
+			continue
+		}
+
+		switch r.Action {
+		case TemplateSyncUpdateComponents, TemplateSyncUpdateCategory:
+			switch st := status[r.TemplateID]; {
+			case st == MessageTemplateStatusArchived:
+				r.Status, r.Reason = TemplateSyncDeferred, "template is still archived"
+				continue
+			case st == "PENDING" || st == "IN_APPEAL":
+				r.Status, r.Reason = TemplateSyncDeferred, "template is in review"
+				continue
+			case st == "APPROVED" && edited[r.TemplateID]:
+				r.Status, r.Reason = TemplateSyncDeferred, "approved templates can be edited once per day"
+				continue
+			}
+			var err error
+			if r.Action == TemplateSyncUpdateComponents {
+				err = c.UpdateMessageTemplate(ctx, r.TemplateID, r.Local.Components)
+			} else {
+				err = c.UpdateMessageTemplateCategory(ctx, r.TemplateID, r.Local.Category)
+			}
+			if err != nil {
+				r.Status, r.Err = TemplateSyncFailed, err
+				continue
+			}
+			r.Status = TemplateSyncApplied
+			edited[r.TemplateID] = true
+			if r.Action == TemplateSyncUpdateComponents {
+				// edited components go back to review
+				status[r.TemplateID] = "PENDING"
+			}
+		case TemplateSyncCreate:
+			id, err := c.CreateMessageTemplate(ctx, plan.WABAID, *r.Local)
+			if err != nil {
+				r.Status, r.Err = TemplateSyncFailed, err
+				continue
+			}
+			r.Status, r.TemplateID = TemplateSyncApplied, id
+		case TemplateSyncDelete:
+			if err := c.DeleteMessageTemplate(ctx, plan.WABAID, r.Name); err != nil {
+				r.Status, r.Err = TemplateSyncFailed, err
+				continue
+			}
+			r.Status = TemplateSyncApplied
+		}
+	}
+	return results
+}
@@ -0,0 +1,109 @@
+package fbgraph_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+	"github.com/pedidopago/wabaman-contrib/fbgraph/fbgraphtest"
+)
+
+func body(text string) []fbgraph.MessageTemplateComponent {
+	return []fbgraph.MessageTemplateComponent{{Type: fbgraph.MTComponentBody, Text: text}}
+}
+
+func TestTemplateSync(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	srv.AddTemplate("waba1", fbgraph.MessageTemplate{Name: "same", Language: "pt_BR", Category: fbgraph.MTCategoryUtility, Status: "APPROVED",
+		Components: []fbgraph.MessageTemplateComponent{{Type: "body", Text: "Oi {{1}}", Example: &fbgraph.MessageTemplateExample{BodyText: [][]string{{"Ana"}}}}}})
+	editedID := srv.AddTemplate("waba1", fbgraph.MessageTemplate{Name: "edited", Language: "pt_BR", Category: fbgraph.MTCategoryUtility, Status: "APPROVED", Components: body("old")})
+	archivedID := srv.AddTemplate("waba1", fbgraph.MessageTemplate{Name: "archived", Language: "pt_BR", Category: fbgraph.MTCategoryUtility, Status: "ARCHIVED", Components: body("x")})
+	srv.AddTemplate("waba1", fbgraph.MessageTemplate{Name: "reviewing", Language: "pt_BR", Category: fbgraph.MTCategoryUtility, Status: "PENDING", Components: body("old")})
+	srv.AddTemplate("waba1", fbgraph.MessageTemplate{Name: "gone", Language: "en_US", Category: fbgraph.MTCategoryUtility, Status: "APPROVED"})
+	srv.AddTemplate("waba1", fbgraph.MessageTemplate{Name: "same", Language: "en_US", Category: fbgraph.MTCategoryUtility, Status: "APPROVED"})
+
+	local := []fbgraph.NewMessageTemplate{
+		{MessageTemplate: fbgraph.MessageTemplate{Name: "same", Language: "pt_BR", Category: fbgraph.MTCategoryUtility,
+			Components: []fbgraph.MessageTemplateComponent{{Type: fbgraph.MTComponentBody, Text: "Oi {{1}}", Example: &fbgraph.MessageTemplateExample{BodyText: [][]string{{"Bia"}}}}}}},
+		{MessageTemplate: fbgraph.MessageTemplate{Name: "edited", Language: "pt_BR", Category: fbgraph.MTCategoryMarketing, Components: body("new")}},
+		{MessageTemplate: fbgraph.MessageTemplate{Name: "archived", Language: "pt_BR", Category: fbgraph.MTCategoryUtility, Components: body("x")}},
+		{MessageTemplate: fbgraph.MessageTemplate{Name: "reviewing", Language: "pt_BR", Category: fbgraph.MTCategoryUtility, Components: body("new")}},
+		{MessageTemplate: fbgraph.MessageTemplate{Name: "brand_new", Language: "pt_BR", Category: fbgraph.MTCategoryUtility, Components: body("hi")}},
+	}
+
+	plan, err := c.PlanTemplateSync(ctx, "waba1", local, fbgraph.TemplateSyncOptions{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, it := range plan.Items {
+		got = append(got, string(it.Action)+" "+it.Name)
+	}
+	want := []string{
+		"unarchive archived",
+		"update_components edited",
+		"update_category edited",
+		"update_components reviewing",
+		"create brand_new",
+		"delete gone",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("plan:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(plan.Unchanged) != 1 || plan.Unchanged[0] != "same (pt_BR)" {
+		t.Fatalf("unchanged = %v", plan.Unchanged)
+	}
+	if out := plan.String(); !strings.Contains(out, "+ create brand_new (pt_BR)") || !strings.Contains(out, "1 unchanged") {
+		t.Fatalf("plan printout:\n%s", out)
+	}
+
+	results := c.ApplyTemplateSync(ctx, plan)
+	statuses := map[string]fbgraph.TemplateSyncStatus{}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.TemplateSyncItem, r.Err)
+		}
+		statuses[string(r.Action)+" "+r.Name] = r.Status
+	}
+	wantStatus := map[string]fbgraph.TemplateSyncStatus{
+		"unarchive archived":          fbgraph.TemplateSyncApplied,
+		"update_components edited":    fbgraph.TemplateSyncApplied,
+		"update_category edited":      fbgraph.TemplateSyncDeferred,
+		"update_components reviewing": fbgraph.TemplateSyncDeferred,
+		"create brand_new":            fbgraph.TemplateSyncApplied,
+		"delete gone":                 fbgraph.TemplateSyncApplied,
+	}
+	for k, v := range wantStatus {
+		if statuses[k] != v {
+			t.Errorf("%s: %s, want %s", k, statuses[k], v)
+		}
+	}
+
+	remote := map[string]fbgraph.MessageTemplate{}
+	for _, tpl := range srv.Templates("waba1") {
+		remote[tpl.ID] = tpl
+	}
+	if remote[editedID].Components[0].Text != "new" || remote[editedID].Category != fbgraph.MTCategoryUtility {
+		t.Errorf("edited = %+v", remote[editedID])
+	}
+	if remote[archivedID].Status != "APPROVED" {
+		t.Errorf("archived status = %s", remote[archivedID].Status)
+	}
+	if len(remote) != 6 {
+		t.Errorf("%d remote templates, want 6", len(remote))
+	}
+}
+
+func TestTemplateSyncRejectsDuplicates(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	tpl := fbgraph.NewMessageTemplate{MessageTemplate: fbgraph.MessageTemplate{Name: "a", Language: "pt_BR"}}
+	if _, err := srv.Client("tok").PlanTemplateSync(context.Background(), "waba1", []fbgraph.NewMessageTemplate{tpl, tpl}, fbgraph.TemplateSyncOptions{}); err == nil {
+		t.Fatal("expected an error for a template defined twice")
+	}
+}
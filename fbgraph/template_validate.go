@@ -0,0 +1,420 @@
+package fbgraph
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pedidopago/wabaman-contrib/util"
+)
+
+// ValidationIssue is a problem ValidateTemplate found in a template.
+type ValidationIssue struct {
+	// Path locates the problem, e.g. "components[1].buttons[0].text". Empty
+	// for template-level problems.
+	Path    string
+	Message string
+}
+
+func (vi ValidationIssue) Error() string {
+	if vi.Path == "" {
+		return vi.Message
+	}
+	return vi.Path + ": " + vi.Message
+}
+
+// Template limits enforced by Meta on submission.
+const (
+	MaxTemplateNameLength       = 512
+	MaxTemplateHeaderTextLength = 60
+	MaxTemplateBodyTextLength   = 1024
+	MaxTemplateFooterTextLength = 60
+	MaxTemplateButtons          = 10
+	MaxTemplateURLButtons       = 2
+	MaxTemplatePhoneButtons     = 1
+	MaxTemplateButtonTextLength = 25
+	MaxTemplateButtonURLLength  = 2000
+	MaxTemplatePhoneLength      = 20
+	MinCarouselCards            = 2
+	MaxCarouselCards            = 10
+	MaxCarouselCardBodyLength   = 160
+	MaxCarouselCardButtons      = 2
+)
+
+var (
+	templateNameRegexp     = regexp.MustCompile(`^[a-z0-9_]+$`)
+	templateVariableRegexp = regexp.MustCompile(`\{\{([^{}]*)\}\}`)
+	namedVariableRegexp    = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+	signatureHashRegexp    = regexp.MustCompile(`^[A-Za-z0-9+/]{11}$`)
+)
+
+// ValidateTemplate checks a template against the rules Meta applies on
+// submission, without calling the API. It returns every issue found, or nil.
+func ValidateTemplate(tpl NewMessageTemplate) []ValidationIssue {
+	v := &templateValidator{category: MessageTemplateCategory(strings.ToUpper(string(tpl.Category)))}
+	switch TemplateParameterFormat(strings.ToUpper(string(tpl.ParameterFormat))) {
+	case "", ParameterFormatPositional:
+	case ParameterFormatNamed:
+		v.named = true
+	default:
+		v.add("parameter_format", "must be POSITIONAL or NAMED, not %q", tpl.ParameterFormat)
+	}
+
+	switch {
+	case tpl.Name == "":
+		v.add("name", "is required")
+	case utf8.RuneCountInString(tpl.Name) > MaxTemplateNameLength:
+		v.add("name", "is longer than %d characters", MaxTemplateNameLength)
+	case !templateNameRegexp.MatchString(tpl.Name):
+		v.add("name", "may only contain lowercase letters, digits and underscores")
+	}
+	if tpl.Language == "" {
+		v.add("language", "is required")
+	}
+	switch v.category {
+	case MTCategoryUtility, MTCategoryMarketing, MTCategoryAuthentication:
+	case "":
+		v.add("category", "is required")
+	default:
+		v.add("category", "must be UTILITY, MARKETING or AUTHENTICATION, not %q", tpl.Category)
+	}
+
+	seen := make(map[MessageTemplateComponentType]int)
+	hasBody := false
+	for i, c := range tpl.Components {
+		path := fmt.Sprintf("components[%d]", i)
+		t := MessageTemplateComponentType(strings.ToUpper(string(c.Type)))
+		if prev, dup := seen[t]; dup {
+			v.add(path, "duplicate %s component (first at components[%d])", t, prev)
+			continue
+		}
+		seen[t] = i
+
+		switch t {
+		case MTComponentHeader:
+			v.header(path, c)
+		case MTComponentBody:
+			hasBody = true
+			v.body(path, c, MaxTemplateBodyTextLength)
+		case MTComponentFooter:
+			v.footer(path, c)
+		case MTComponentButtons:
+			v.buttons(path, c.Buttons, MaxTemplateButtons)
+		case MTComponentCarousel:
+			v.carousel(path, c.Cards)
+		case "":
+			v.add(path+".type", "is required")
+		default:
+			v.add(path+".type", "unknown component type %q", c.Type)
+		}
+		if v.category != MTCategoryAuthentication && (c.AddSecurityRecommendation != nil || c.CodeExpirationMinutes != nil) {
+			v.add(path, "add_security_recommendation and code_expiration_minutes are only allowed in AUTHENTICATION templates")
+		}
+		if c.CodeExpirationMinutes != nil && (*c.CodeExpirationMinutes < 1 || *c.CodeExpirationMinutes > 90) {
+			v.add(path+".code_expiration_minutes", "must be between 1 and 90")
+		}
+	}
+	if !hasBody {
+		v.add("components", "a BODY component is required")
+	}
+	return v.issues
+}
+
+type templateValidator struct {
+	category MessageTemplateCategory
+	named    bool
+	issues   []ValidationIssue
+}
+
+func (v *templateValidator) add(path, format string, args ...any) {
+	v.issues = append(v.issues, ValidationIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// variables validates the {{n}} placeholders of text and returns their count.
+func (v *templateValidator) variables(path, text string) int {
+	count, ok := util.CountAndValidateTemplateVariables(text)
+	if !ok {
+		v.add(path, "variables must be numbered {{1}}, {{2}}, ... in order")
+	}
+	if all := len(templateVariableRegexp.FindAllString(text, -1)); all != count && ok {
+		v.add(path, "has malformed variables; use {{1}}, {{2}}, ...")
+	}
+	return count
+}
+
+// namedVariables validates the {{name}} placeholders of text and returns the
+// names, in order of first use.
+func (v *templateValidator) namedVariables(path, text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range templateVariableRegexp.FindAllStringSubmatch(text, -1) {
+		name := m[1]
+		if !namedVariableRegexp.MatchString(name) {
+			v.add(path, "variable {{%s}} must be named with lowercase letters, digits and underscores", name)
+			continue
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// namedExamples checks that examples has one sample per name.
+func (v *templateValidator) namedExamples(path string, names []string, examples []NamedParamExample) {
+	have := make(map[string]bool, len(examples))
+	for _, e := range examples {
+		have[e.ParamName] = true
+	}
+	for _, name := range names {
+		if !have[name] {
+			v.add(path, "a sample value for {{%s}} is required", name)
+		}
+	}
+	if len(examples) > len(names) {
+		v.add(path, "has %d sample value(s), the text has %d variable(s)", len(examples), len(names))
+	}
+}
+
+// textVariables validates the variables of a header or body text in the
+// template's parameter format and returns their names ("1", "2", ... for
+// positional templates).
+func (v *templateValidator) textVariables(path, text string) []string {
+	if v.named {
+		return v.namedVariables(path, text)
+	}
+	n := v.variables(path, text)
+	names := make([]string, n)
+	for i := range names {
+		names[i] = strconv.Itoa(i + 1)
+	}
+	return names
+}
+
+func (v *templateValidator) textLength(path, text string, maxLen int) {
+	if n := utf8.RuneCountInString(text); n > maxLen {
+		v.add(path, "is %d characters long, the limit is %d", n, maxLen)
+	}
+}
+
+func (v *templateValidator) header(path string, c MessageTemplateComponent) {
+	switch MessageTemplateComponentFormat(strings.ToUpper(c.Format)) {
+	case MTCFormatText, "":
+		if c.Text == "" {
+			v.add(path+".text", "is required for TEXT headers")
+			return
+		}
+		v.textLength(path+".text", c.Text, MaxTemplateHeaderTextLength)
+		names := v.textVariables(path+".text", c.Text)
+		n := len(names)
+		if n > 1 {
+			v.add(path+".text", "headers take at most 1 variable, found %d", n)
+		}
+		switch {
+		case n == 0:
+		case v.named:
+			var examples []NamedParamExample
+			if c.Example != nil {
+				examples = c.Example.HeaderTextNamedParams
+			}
+			v.namedExamples(path+".example.header_text_named_params", names, examples)
+		case c.Example == nil || len(c.Example.HeaderText) != n:
+			v.add(path+".example.header_text", "must have %d sample value(s)", n)
+		}
+	case MTCFormatImage, MTCFormatVideo, MTCFormatDocument:
+		if c.Example == nil || len(c.Example.HeaderHandle) == 0 {
+			v.add(path+".example.header_handle", "a sample media handle is required for %s headers", strings.ToUpper(c.Format))
+		}
+	case "LOCATION":
+	default:
+		v.add(path+".format", "unknown header format %q", c.Format)
+	}
+}
+
+func (v *templateValidator) body(path string, c MessageTemplateComponent, maxLen int) {
+	if v.category == MTCategoryAuthentication {
+		// Meta supplies the text of authentication templates
+		return
+	}
+	if strings.TrimSpace(c.Text) == "" {
+		v.add(path+".text", "is required")
+		return
+	}
+	v.textLength(path+".text", c.Text, maxLen)
+	names := v.textVariables(path+".text", c.Text)
+	n := len(names)
+	if n == 0 {
+		return
+	}
+	trimmed := strings.TrimSpace(c.Text)
+	if strings.HasPrefix(trimmed, "{{") || strings.HasSuffix(trimmed, "}}") {
+		v.add(path+".text", "cannot start or end with a variable")
+	}
+	switch {
+	case v.named:
+		var examples []NamedParamExample
+		if c.Example != nil {
+			examples = c.Example.BodyTextNamedParams
+		}
+		v.namedExamples(path+".example.body_text_named_params", names, examples)
+	case c.Example == nil || len(c.Example.BodyText) == 0:
+		v.add(path+".example.body_text", "sample values for %d variable(s) are required", n)
+	case len(c.Example.BodyText[0]) != n:
+		v.add(path+".example.body_text", "has %d sample value(s), the text has %d variable(s)", len(c.Example.BodyText[0]), n)
+	}
+}
+
+func (v *templateValidator) footer(path string, c MessageTemplateComponent) {
+	if v.category == MTCategoryAuthentication {
+		return
+	}
+	if c.Text == "" {
+		v.add(path+".text", "is required")
+		return
+	}
+	v.textLength(path+".text", c.Text, MaxTemplateFooterTextLength)
+	if templateVariableRegexp.MatchString(c.Text) {
+		v.add(path+".text", "footers cannot have variables")
+	}
+}
+
+func (v *templateValidator) buttons(path string, buttons []MessageTemplateButton, maxButtons int) {
+	if len(buttons) == 0 {
+		v.add(path+".buttons", "at least one button is required")
+		return
+	}
+	if len(buttons) > maxButtons {
+		v.add(path+".buttons", "has %d buttons, the limit is %d", len(buttons), maxButtons)
+	}
+	urls, phones := 0, 0
+	for i, b := range buttons {
+		bp := fmt.Sprintf("%s.buttons[%d]", path, i)
+		bt := MessageTemplateButtonType(strings.ToUpper(string(b.Type)))
+		if bt != MTBTypeOTP {
+			if b.Text == "" {
+				v.add(bp+".text", "is required")
+			}
+			v.textLength(bp+".text", b.Text, MaxTemplateButtonTextLength)
+		}
+		switch bt {
+		case MTBTypeQuickReply:
+		case MTBTypeURL:
+			urls++
+			v.urlButton(bp, b)
+		case MTBTypePhoneNumber:
+			phones++
+			if b.PhoneNumber == "" {
+				v.add(bp+".phone_number", "is required")
+			} else if len(b.PhoneNumber) > MaxTemplatePhoneLength {
+				v.add(bp+".phone_number", "is longer than %d characters", MaxTemplatePhoneLength)
+			}
+		case MTBTypeOTP:
+			v.otpButton(bp, b)
+		case "":
+			v.add(bp+".type", "is required")
+		default:
+			v.add(bp+".type", "unknown button type %q", b.Type)
+		}
+	}
+	if urls > MaxTemplateURLButtons {
+		v.add(path+".buttons", "has %d URL buttons, the limit is %d", urls, MaxTemplateURLButtons)
+	}
+	if phones > MaxTemplatePhoneButtons {
+		v.add(path+".buttons", "has %d phone number buttons, the limit is %d", phones, MaxTemplatePhoneButtons)
+	}
+}
+
+func (v *templateValidator) urlButton(path string, b MessageTemplateButton) {
+	if b.URL == "" {
+		v.add(path+".url", "is required")
+		return
+	}
+	if len(b.URL) > MaxTemplateButtonURLLength {
+		v.add(path+".url", "is longer than %d characters", MaxTemplateButtonURLLength)
+	}
+	if !strings.HasPrefix(b.URL, "https://") && !strings.HasPrefix(b.URL, "http://") {
+		v.add(path+".url", "must be an http(s) URL")
+	}
+	n := v.variables(path+".url", b.URL)
+	switch {
+	case n > 1:
+		v.add(path+".url", "takes at most 1 variable, found %d", n)
+	case n == 1 && !strings.HasSuffix(b.URL, "{{1}}"):
+		v.add(path+".url", "the variable must be at the end of the URL")
+	}
+	if n > 0 && len(b.Example) == 0 {
+		v.add(path+".example", "a sample URL is required for dynamic URLs")
+	}
+}
+
+func (v *templateValidator) otpButton(path string, b MessageTemplateButton) {
+	if v.category != MTCategoryAuthentication {
+		v.add(path+".type", "OTP buttons are only allowed in AUTHENTICATION templates")
+	}
+	switch OTPType(strings.ToUpper(string(b.OTPType))) {
+	case OTPTypeCopyCode:
+	case OTPTypeOneTap:
+		if b.PackageName == "" {
+			v.add(path+".package_name", "is required for ONE_TAP buttons")
+		}
+		if !signatureHashRegexp.MatchString(b.SignatureHash) {
+			v.add(path+".signature_hash", "must be the 11 character app signature hash")
+		}
+	case "":
+		v.add(path+".otp_type", "is required")
+	default:
+		v.add(path+".otp_type", "must be COPY_CODE or ONE_TAP, not %q", b.OTPType)
+	}
+}
+
+func (v *templateValidator) carousel(path string, cards []MessageTemplateCard) {
+	if len(cards) < MinCarouselCards || len(cards) > MaxCarouselCards {
+		v.add(path+".cards", "has %d cards, carousels take %d to %d", len(cards), MinCarouselCards, MaxCarouselCards)
+	}
+	var format string
+	var buttonTypes []MessageTemplateButtonType
+	for i, card := range cards {
+		cp := fmt.Sprintf("%s.cards[%d]", path, i)
+		hasHeader := false
+		for j, c := range card.Components {
+			ccp := fmt.Sprintf("%s.components[%d]", cp, j)
+			switch MessageTemplateComponentType(strings.ToUpper(string(c.Type))) {
+			case MTComponentHeader:
+				hasHeader = true
+				f := strings.ToUpper(c.Format)
+				if f != string(MTCFormatImage) && f != string(MTCFormatVideo) {
+					v.add(ccp+".format", "card headers must be IMAGE or VIDEO")
+				} else if format == "" {
+					format = f
+				} else if f != format {
+					v.add(ccp+".format", "all cards must share the same header format (%s)", format)
+				}
+				if c.Example == nil || len(c.Example.HeaderHandle) == 0 {
+					v.add(ccp+".example.header_handle", "a sample media handle is required")
+				}
+			case MTComponentBody:
+				v.body(ccp, MessageTemplateComponent{Type: c.Type, Text: c.Text, Example: c.Example}, MaxCarouselCardBodyLength)
+			case MTComponentButtons:
+				v.buttons(ccp, c.Buttons, MaxCarouselCardButtons)
+				types := make([]MessageTemplateButtonType, len(c.Buttons))
+				for k, b := range c.Buttons {
+					types[k] = MessageTemplateButtonType(strings.ToUpper(string(b.Type)))
+				}
+				if buttonTypes == nil {
+					buttonTypes = types
+				} else if !slices.Equal(types, buttonTypes) {
+					v.add(ccp+".buttons", "all cards must have the same buttons, in the same order")
+				}
+			default:
+				v.add(ccp+".type", "cards take HEADER, BODY and BUTTONS components, not %q", c.Type)
+			}
+		}
+		if !hasHeader {
+			v.add(cp, "a media HEADER is required")
+		}
+	}
+}
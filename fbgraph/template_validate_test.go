@@ -0,0 +1,197 @@
+package fbgraph
+
+import (
+	"strings"
+	"testing"
+)
+
+func validTemplate() NewMessageTemplate {
+	return NewMessageTemplate{MessageTemplate: MessageTemplate{
+		Name:     "order_shipped",
+		Language: "pt_BR",
+		Category: MTCategoryUtility,
+		Components: []MessageTemplateComponent{
+			{Type: MTComponentHeader, Format: "TEXT", Text: "Pedido {{1}}", Example: &MessageTemplateExample{HeaderText: []string{"#123"}}},
+			{Type: MTComponentBody, Text: "Olá {{1}}, seu pedido saiu para entrega em {{2}}.", Example: &MessageTemplateExample{BodyText: [][]string{{"Ana", "10/05"}}}},
+			{Type: MTComponentFooter, Text: "Pedido Pago"},
+			{Type: MTComponentButtons, Buttons: []MessageTemplateButton{
+				{Type: MTBTypeURL, Text: "Rastrear", URL: "https://example.com/t/{{1}}", Example: []string{"https://example.com/t/abc"}},
+				{Type: MTBTypeQuickReply, Text: "Falar com atendente"},
+			}},
+		},
+	}}
+}
+
+func issuePaths(issues []ValidationIssue) string {
+	paths := make([]string, len(issues))
+	for i, is := range issues {
+		paths[i] = is.Error()
+	}
+	return strings.Join(paths, "\n")
+}
+
+func TestValidateTemplateValid(t *testing.T) {
+	if issues := ValidateTemplate(validTemplate()); issues != nil {
+		t.Fatalf("unexpected issues:\n%s", issuePaths(issues))
+	}
+}
+
+func TestValidateTemplateIssues(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*NewMessageTemplate)
+		path   string
+	}{
+		{"bad name", func(t *NewMessageTemplate) { t.Name = "Order Shipped" }, "name"},
+		{"long header", func(t *NewMessageTemplate) {
+			t.Components[0] = MessageTemplateComponent{Type: MTComponentHeader, Format: "TEXT", Text: strings.Repeat("a", 61)}
+		}, "components[0].text"},
+		{"long body", func(t *NewMessageTemplate) {
+			t.Components[1].Text = "Olá " + strings.Repeat("a", 1024)
+			t.Components[1].Example = nil
+		}, "components[1].text"},
+		{"non sequential", func(t *NewMessageTemplate) { t.Components[1].Text = "Olá {{1}} e {{3}}." }, "components[1].text"},
+		{"missing examples", func(t *NewMessageTemplate) { t.Components[1].Example = nil }, "components[1].example.body_text"},
+		{"example count", func(t *NewMessageTemplate) {
+			t.Components[1].Example = &MessageTemplateExample{BodyText: [][]string{{"Ana"}}}
+		}, "components[1].example.body_text"},
+		{"trailing variable", func(t *NewMessageTemplate) {
+			t.Components[1].Text = "Olá {{1}}, entrega em {{2}}"
+		}, "components[1].text"},
+		{"footer variable", func(t *NewMessageTemplate) { t.Components[2].Text = "Oi {{1}}" }, "components[2].text"},
+		{"too many buttons", func(t *NewMessageTemplate) {
+			for range 10 {
+				t.Components[3].Buttons = append(t.Components[3].Buttons, MessageTemplateButton{Type: MTBTypeQuickReply, Text: "x"})
+			}
+		}, "components[3].buttons"},
+		{"long button text", func(t *NewMessageTemplate) {
+			t.Components[3].Buttons[1].Text = strings.Repeat("b", 26)
+		}, "components[3].buttons[1].text"},
+		{"url variable not at end", func(t *NewMessageTemplate) {
+			t.Components[3].Buttons[0].URL = "https://example.com/{{1}}/track"
+		}, "components[3].buttons[0].url"},
+		{"otp outside authentication", func(t *NewMessageTemplate) {
+			t.Components[3].Buttons[1] = MessageTemplateButton{Type: MTBTypeOTP, OTPType: OTPTypeCopyCode}
+		}, "components[3].buttons[1].type"},
+		{"duplicate body", func(t *NewMessageTemplate) { t.Components = append(t.Components, t.Components[1]) }, "components[4]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl := validTemplate()
+			tt.mutate(&tpl)
+			issues := ValidateTemplate(tpl)
+			for _, is := range issues {
+				if is.Path == tt.path {
+					return
+				}
+			}
+			t.Fatalf("no issue at %s, got:\n%s", tt.path, issuePaths(issues))
+		})
+	}
+}
+
+func TestValidateTemplateReportsEveryIssue(t *testing.T) {
+	tpl := NewMessageTemplate{MessageTemplate: MessageTemplate{
+		Components: []MessageTemplateComponent{{Type: MTComponentHeader, Format: "IMAGE"}},
+	}}
+	issues := ValidateTemplate(tpl)
+	want := []string{"name", "language", "category", "components[0].example.header_handle", "components"}
+	if len(issues) != len(want) {
+		t.Fatalf("got:\n%s", issuePaths(issues))
+	}
+	for i, p := range want {
+		if issues[i].Path != p {
+			t.Errorf("issue %d at %q, want %q", i, issues[i].Path, p)
+		}
+	}
+}
+
+func TestValidateTemplateAuthentication(t *testing.T) {
+	tpl := NewMessageTemplate{MessageTemplate: MessageTemplate{
+		Name:     "otp",
+		Language: "en_US",
+		Category: MTCategoryAuthentication,
+		Components: []MessageTemplateComponent{
+			{Type: MTComponentBody, AddSecurityRecommendation: new(true)},
+			{Type: MTComponentFooter, CodeExpirationMinutes: new(10)},
+			{Type: MTComponentButtons, Buttons: []MessageTemplateButton{{Type: MTBTypeOTP, OTPType: OTPTypeOneTap, PackageName: "com.example", SignatureHash: "short"}}},
+		},
+	}}
+	issues := ValidateTemplate(tpl)
+	if len(issues) != 1 || issues[0].Path != "components[2].buttons[0].signature_hash" {
+		t.Fatalf("got:\n%s", issuePaths(issues))
+	}
+}
+
+func TestValidateTemplateCarousel(t *testing.T) {
+	card := func(format string, buttons ...MessageTemplateButtonType) MessageTemplateCard {
+		bs := make([]MessageTemplateButton, len(buttons))
+		for i, b := range buttons {
+			bs[i] = MessageTemplateButton{Type: b, Text: "Ver", URL: "https://example.com"}
+		}
+		return MessageTemplateCard{Components: []MessageTemplateCardComponent{
+			{Type: MTComponentHeader, Format: format, Example: &MessageTemplateExample{HeaderHandle: []string{"4::aW"}}},
+			{Type: MTComponentBody, Text: "Produto"},
+			{Type: MTComponentButtons, Buttons: bs},
+		}}
+	}
+	tpl := NewMessageTemplate{MessageTemplate: MessageTemplate{
+		Name:     "catalog",
+		Language: "pt_BR",
+		Category: MTCategoryMarketing,
+		Components: []MessageTemplateComponent{
+			{Type: MTComponentBody, Text: "Veja as ofertas"},
+			{Type: MTComponentCarousel, Cards: []MessageTemplateCard{
+				card("IMAGE", MTBTypeURL),
+				card("VIDEO", MTBTypeURL),
+				card("IMAGE", MTBTypeQuickReply),
+			}},
+		},
+	}}
+	issues := ValidateTemplate(tpl)
+	want := []string{"components[1].cards[1].components[0].format", "components[1].cards[2].components[2].buttons"}
+	if len(issues) != len(want) {
+		t.Fatalf("got:\n%s", issuePaths(issues))
+	}
+	for i, p := range want {
+		if issues[i].Path != p {
+			t.Errorf("issue %d at %q, want %q", i, issues[i].Path, p)
+		}
+	}
+
+	tpl.Components[1].Cards = tpl.Components[1].Cards[:1]
+	if issues := ValidateTemplate(tpl); len(issues) != 1 || issues[0].Path != "components[1].cards" {
+		t.Fatalf("single card: %s", issuePaths(issues))
+	}
+}
+
+func TestValidateTemplateNamed(t *testing.T) {
+	tpl := validTemplate()
+	tpl.ParameterFormat = ParameterFormatNamed
+	tpl.Components[0].Text = "Pedido {{order_id}}"
+	tpl.Components[0].Example = &MessageTemplateExample{HeaderTextNamedParams: []NamedParamExample{{ParamName: "order_id", Example: "#123"}}}
+	tpl.Components[1].Text = "Olá {{first_name}}, seu pedido {{order_id}} saiu para entrega."
+	tpl.Components[1].Example = &MessageTemplateExample{BodyTextNamedParams: []NamedParamExample{
+		{ParamName: "first_name", Example: "Ana"},
+		{ParamName: "order_id", Example: "#123"},
+	}}
+	if issues := ValidateTemplate(tpl); issues != nil {
+		t.Fatalf("unexpected issues:\n%s", issuePaths(issues))
+	}
+
+	tpl.Components[1].Text = "Olá {{First Name}}, seu pedido {{order_id}} saiu para entrega."
+	tpl.Components[1].Example.BodyTextNamedParams = tpl.Components[1].Example.BodyTextNamedParams[1:]
+	tpl.Components[0].Example = nil
+	got := issuePaths(ValidateTemplate(tpl))
+	for _, want := range []string{"components[1].text", "components[0].example.header_text_named_params"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("issues do not mention %s:\n%s", want, got)
+		}
+	}
+
+	tpl = validTemplate()
+	tpl.ParameterFormat = "MIXED"
+	if got := issuePaths(ValidateTemplate(tpl)); !strings.Contains(got, "parameter_format") {
+		t.Errorf("issues = %s", got)
+	}
+}
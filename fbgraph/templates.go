@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"net/http"
 	"net/url"
 	"strings"
@@ -53,7 +54,7 @@ const (
 )
 
 var templateParamsDefaultFields = []string{
-	"category", "language", "name", "quality_score", "rejected_reason", "status", "content", "components",
+	"category", "language", "name", "quality_score", "rejected_reason", "status", "content", "components", "parameter_format",
 }
 
 // fields=category,language,name,quality_score,status,content,components&limit=3&after=MgZDZD
@@ -63,6 +64,8 @@ type GetMessageTemplatesParameters struct {
 	Limit                     int
 	After                     string
 	Status                    string // e.g. "ARCHIVED"
+	Category                  MessageTemplateCategory
+	Language                  string // e.g. "pt_BR"
 }
 
 type GetMessageTemplatesResponse struct {
@@ -70,15 +73,28 @@ type GetMessageTemplatesResponse struct {
 	Paging MessageTemplatesPaging `json:"paging"`
 }
 
+// TemplateParameterFormat is how the variables of a template are written.
+type TemplateParameterFormat string
+
+const (
+	// ParameterFormatPositional variables are numbered: {{1}}, {{2}}, ...
+	// It is the default.
+	ParameterFormatPositional TemplateParameterFormat = "POSITIONAL"
+	// ParameterFormatNamed variables are named: {{first_name}}. Sends must set
+	// TemplateComponentParameter.ParameterName.
+	ParameterFormatNamed TemplateParameterFormat = "NAMED"
+)
+
 type MessageTemplate struct {
-	Category       MessageTemplateCategory    `json:"category"`
-	Language       string                     `json:"language"`
-	Name           string                     `json:"name"`
-	QualityScore   *MessageTemplateScore      `json:"quality_score,omitempty"`
-	RejectedReason string                     `json:"rejected_reason,omitempty"`
-	Status         string                     `json:"status,omitempty"`
-	Components     []MessageTemplateComponent `json:"components"`
-	ID             string                     `json:"id,omitempty"`
+	Category        MessageTemplateCategory    `json:"category"`
+	Language        string                     `json:"language"`
+	Name            string                     `json:"name"`
+	ParameterFormat TemplateParameterFormat    `json:"parameter_format,omitempty"`
+	QualityScore    *MessageTemplateScore      `json:"quality_score,omitempty"`
+	RejectedReason  string                     `json:"rejected_reason,omitempty"`
+	Status          string                     `json:"status,omitempty"`
+	Components      []MessageTemplateComponent `json:"components"`
+	ID              string                     `json:"id,omitempty"`
 }
 
 func ConvertMessageTemplateToNew(tpl MessageTemplate, allowCategoryChange bool) NewMessageTemplate {
@@ -121,6 +137,15 @@ type MessageTemplateExample struct {
 	HeaderHandle []string   `json:"header_handle,omitempty"`
 	BodyText     [][]string `json:"body_text,omitempty"`
 	HeaderText   []string   `json:"header_text,omitempty"`
+	// for templates with ParameterFormatNamed
+	BodyTextNamedParams   []NamedParamExample `json:"body_text_named_params,omitempty"`
+	HeaderTextNamedParams []NamedParamExample `json:"header_text_named_params,omitempty"`
+}
+
+// NamedParamExample is the sample value of a named variable.
+type NamedParamExample struct {
+	ParamName string `json:"param_name"`
+	Example   string `json:"example"`
 }
 
 type MessageTemplateButton struct {
@@ -167,7 +192,7 @@ func (c *Client) GetMessageTemplate(ctx context.Context, id string) (*MessageTem
 		apiversion = c.GraphAPIVersion
 	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s", apiversion, id)
+	url := fmt.Sprintf("%s/%s/%s", c.baseURL(), apiversion, id)
 
 	req, err := NewRequest(http.MethodGet, url, nil)
 	if err != nil {
@@ -177,7 +202,7 @@ func (c *Client) GetMessageTemplate(ctx context.Context, id string) (*MessageTem
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -213,8 +238,14 @@ func (c *Client) GetMessageTemplates(ctx context.Context, params GetMessageTempl
 	if params.Status != "" {
 		encfields.Set("status", params.Status)
 	}
+	if params.Category != "" {
+		encfields.Set("category", string(params.Category))
+	}
+	if params.Language != "" {
+		encfields.Set("language", params.Language)
+	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/message_templates?%s", apiversion, params.WhatsAppBusinessAccountID, encfields.Encode())
+	url := fmt.Sprintf("%s/%s/%s/message_templates?%s", c.baseURL(), apiversion, params.WhatsAppBusinessAccountID, encfields.Encode())
 
 	req, err := NewRequest(http.MethodGet, url, nil)
 	if err != nil {
@@ -224,7 +255,7 @@ func (c *Client) GetMessageTemplates(ctx context.Context, params GetMessageTempl
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -239,43 +270,83 @@ func (c *Client) GetMessageTemplates(ctx context.Context, params GetMessageTempl
 	return result, nil
 }
 
+// MessageTemplates iterates over every template matching params, following
+// Paging.Cursors.After from params.After on. Iteration stops after the first
+// error, which is yielded with a zero MessageTemplate; ctx is checked before
+// each page is requested.
+//
+//	for tpl, err := range c.MessageTemplates(ctx, params) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (c *Client) MessageTemplates(ctx context.Context, params GetMessageTemplatesParameters) iter.Seq2[MessageTemplate, error] {
+	return func(yield func(MessageTemplate, error) bool) {
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(MessageTemplate{}, err)
+				return
+			}
+			page, err := c.GetMessageTemplates(ctx, params)
+			if err != nil {
+				yield(MessageTemplate{}, err)
+				return
+			}
+			for _, tpl := range page.Data {
+				if !yield(tpl, nil) {
+					return
+				}
+			}
+			if page.Paging.Next == "" || page.Paging.Cursors.After == "" || len(page.Data) == 0 {
+				return
+			}
+			params.After = page.Paging.Cursors.After
+		}
+	}
+}
+
+// AllMessageTemplates collects MessageTemplates into a slice. On error it
+// returns the templates read so far along with the error.
+func (c *Client) AllMessageTemplates(ctx context.Context, params GetMessageTemplatesParameters) ([]MessageTemplate, error) {
+	var out []MessageTemplate
+	for tpl, err := range c.MessageTemplates(ctx, params) {
+		if err != nil {
+			return out, err
+		}
+		out = append(out, tpl)
+	}
+	return out, nil
+}
+
 func (c *Client) CreateMessageTemplate(ctx context.Context, wabaID string, template NewMessageTemplate) (id string, err error) {
-	c.lastGraphError = nil
-	c.lastErrorRawBody = ""
+	c.resetLastError()
 
 	apiVersion := DefaultGraphAPIVersion
 	if c.GraphAPIVersion != "" {
 		apiVersion = c.GraphAPIVersion
 	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/message_templates", apiVersion, wabaID)
+	url := fmt.Sprintf("%s/%s/%s/message_templates", c.baseURL(), apiVersion, wabaID)
 
 	buf := new(bytes.Buffer)
 	if err := json.NewEncoder(buf).Encode(template); err != nil {
 		return "", fmt.Errorf("encode template: %w", err)
 	}
 
-	req, err := NewRequest(http.MethodPost, url, buf)
+	req, err := NewRequestWithContext(ctx, http.MethodPost, url, buf)
 	if err != nil {
 		return "", fmt.Errorf("new request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 	if resp.StatusCode != http.StatusOK {
-		gerr := c.errorFromResponse(resp)
-
-		if ge, ok := AsGraphError(gerr); ok {
-			if ge.Code == 4 {
-				return "", ErrApplicationRateLimitReached
-			}
-		}
-
-		return "", gerr
+		return "", rateLimitError(c.errorFromResponse(resp))
 	}
 
 	result := struct {
@@ -292,8 +363,7 @@ func (c *Client) CreateMessageTemplate(ctx context.Context, wabaID string, templ
 const MessageTemplateStatusArchived = "ARCHIVED"
 
 func (c *Client) post(ctx context.Context, url string, body any) error {
-	c.lastGraphError = nil
-	c.lastErrorRawBody = ""
+	c.resetLastError()
 
 	buf := new(bytes.Buffer)
 	if err := json.NewEncoder(buf).Encode(body); err != nil {
@@ -308,22 +378,20 @@ func (c *Client) post(ctx context.Context, url string, body any) error {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		gerr := c.errorFromResponse(resp)
-		if ge, ok := AsGraphError(gerr); ok && ge.Code == 4 {
-			return ErrApplicationRateLimitReached
-		}
-		return gerr
+		return rateLimitError(c.errorFromResponse(resp))
 	}
 
 	// HTTP 200 is the success signal; drain body and return nil
-	_ = json.NewDecoder(resp.Body).Decode(&struct{ Success bool `json:"success"` }{})
+	_ = json.NewDecoder(resp.Body).Decode(&struct {
+		Success bool `json:"success"`
+	}{})
 	return nil
 }
 
@@ -332,7 +400,7 @@ func (c *Client) postToTemplate(ctx context.Context, templateID string, body any
 	if c.GraphAPIVersion != "" {
 		apiVersion = c.GraphAPIVersion
 	}
-	return c.post(ctx, fmt.Sprintf("https://graph.facebook.com/%s/%s", apiVersion, templateID), body)
+	return c.post(ctx, fmt.Sprintf("%s/%s/%s", c.baseURL(), apiVersion, templateID), body)
 }
 
 func (c *Client) UpdateMessageTemplateCategory(ctx context.Context, templateID string, newCategory MessageTemplateCategory) error {
@@ -349,9 +417,9 @@ func (c *Client) UpdateMessageTemplate(ctx context.Context, templateID string, c
 
 // UnarchiveMessageTemplates unarchives a batch of templates for a WABA.
 // Uses api.facebook.com (no version prefix) — the versioned graph.facebook.com endpoint returns 2500.
+// When Client.BaseURL is set the request goes there, still without a version.
 func (c *Client) UnarchiveMessageTemplates(ctx context.Context, wabaID string, templateIDs []string) (unarchived []string, failed map[string]string, err error) {
-	c.lastGraphError = nil
-	c.lastErrorRawBody = ""
+	c.resetLastError()
 
 	buf := new(bytes.Buffer)
 	if err := json.NewEncoder(buf).Encode(struct {
@@ -360,7 +428,7 @@ func (c *Client) UnarchiveMessageTemplates(ctx context.Context, wabaID string, t
 		return nil, nil, fmt.Errorf("encode: %w", err)
 	}
 
-	req, err := NewRequest(http.MethodPost, "https://api.facebook.com/"+wabaID+"/message_templates/unarchive", buf)
+	req, err := NewRequest(http.MethodPost, c.unversionedBaseURL()+"/"+wabaID+"/message_templates/unarchive", buf)
 	if err != nil {
 		return nil, nil, fmt.Errorf("new request: %w", err)
 	}
@@ -368,18 +436,14 @@ func (c *Client) UnarchiveMessageTemplates(ctx context.Context, wabaID string, t
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		gerr := c.errorFromResponse(resp)
-		if ge, ok := AsGraphError(gerr); ok && ge.Code == 4 {
-			return nil, nil, ErrApplicationRateLimitReached
-		}
-		return nil, nil, gerr
+		return nil, nil, rateLimitError(c.errorFromResponse(resp))
 	}
 
 	var result struct {
@@ -393,8 +457,7 @@ func (c *Client) UnarchiveMessageTemplates(ctx context.Context, wabaID string, t
 }
 
 func (c *Client) DeleteMessageTemplate(ctx context.Context, whatsappBusinessAccountID, templateName string) error {
-	c.lastGraphError = nil
-	c.lastErrorRawBody = ""
+	c.resetLastError()
 
 	apiVersion := DefaultGraphAPIVersion
 	if c.GraphAPIVersion != "" {
@@ -403,29 +466,21 @@ func (c *Client) DeleteMessageTemplate(ctx context.Context, whatsappBusinessAcco
 
 	urlv := make(url.Values)
 	urlv.Set("name", templateName)
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/message_templates?%s", apiVersion, whatsappBusinessAccountID, urlv.Encode())
+	url := fmt.Sprintf("%s/%s/%s/message_templates?%s", c.baseURL(), apiVersion, whatsappBusinessAccountID, urlv.Encode())
 
-	req, err := NewRequest(http.MethodDelete, url, nil)
+	req, err := NewRequestWithContext(ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return fmt.Errorf("new request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 	if resp.StatusCode != http.StatusOK {
-		gerr := c.errorFromResponse(resp)
-
-		if ge, ok := AsGraphError(gerr); ok {
-			if ge.Code == 4 {
-				return ErrApplicationRateLimitReached
-			}
-		}
-
-		return gerr
+		return rateLimitError(c.errorFromResponse(resp))
 	}
 
 	result := struct {
@@ -0,0 +1,107 @@
+package fbgraph_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+	"github.com/pedidopago/wabaman-contrib/fbgraph/fbgraphtest"
+)
+
+func seedTemplates(srv *fbgraphtest.Server, n int) {
+	for i := range n {
+		cat, lang := fbgraph.MTCategoryUtility, "pt_BR"
+		if i%2 == 1 {
+			cat, lang = fbgraph.MTCategoryMarketing, "en_US"
+		}
+		srv.AddTemplate("waba1", fbgraph.MessageTemplate{Name: fmt.Sprintf("tpl_%02d", i), Language: lang, Category: cat, Status: "APPROVED"})
+	}
+}
+
+func TestMessageTemplatesFollowsCursors(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	seedTemplates(srv, 7)
+	c := srv.Client("tok")
+
+	all, err := c.AllMessageTemplates(context.Background(), fbgraph.GetMessageTemplatesParameters{WhatsAppBusinessAccountID: "waba1", Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 7 || all[6].Name != "tpl_06" {
+		t.Fatalf("got %d templates: %+v", len(all), all)
+	}
+	if got := srv.CountRequests(http.MethodGet, "/message_templates"); got != 3 {
+		t.Fatalf("requested %d pages, want 3", got)
+	}
+
+	marketing, err := c.AllMessageTemplates(context.Background(), fbgraph.GetMessageTemplatesParameters{
+		WhatsAppBusinessAccountID: "waba1",
+		Limit:                     2,
+		Category:                  fbgraph.MTCategoryMarketing,
+		Language:                  "en_US",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(marketing) != 3 {
+		t.Fatalf("got %d marketing templates, want 3", len(marketing))
+	}
+}
+
+func TestMessageTemplatesStopsEarly(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	seedTemplates(srv, 10)
+	c := srv.Client("tok")
+
+	n := 0
+	for _, err := range c.MessageTemplates(context.Background(), fbgraph.GetMessageTemplatesParameters{WhatsAppBusinessAccountID: "waba1", Limit: 2}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n++; n == 3 {
+			break
+		}
+	}
+	if got := srv.CountRequests(http.MethodGet, "/message_templates"); got != 2 {
+		t.Fatalf("requested %d pages, want 2", got)
+	}
+}
+
+func TestMessageTemplatesErrors(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	seedTemplates(srv, 5)
+	c := srv.Client("tok")
+	params := fbgraph.GetMessageTemplatesParameters{WhatsAppBusinessAccountID: "waba1", Limit: 2}
+
+	// the second page fails
+	var got []fbgraph.MessageTemplate
+	first := true
+	for tpl, err := range c.MessageTemplates(context.Background(), params) {
+		if err != nil {
+			if !errors.Is(err, fbgraph.ErrSomethingWentWrong) {
+				t.Fatalf("err = %v", err)
+			}
+			break
+		}
+		got = append(got, tpl)
+		if first {
+			srv.Fail(fbgraphtest.Failure{PathSuffix: "/message_templates", Error: fbgraphtest.ErrInternal})
+			first = false
+		}
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d templates before the error, want 2", len(got))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.AllMessageTemplates(ctx, params); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
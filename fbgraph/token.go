@@ -41,7 +41,7 @@ func (c *Client) DebugToken(ctx context.Context, inputToken string) (TokenInfo,
 		apiVersion = c.GraphAPIVersion
 	}
 
-	url := fmt.Sprintf("https://graph.facebook.com/%s/debug_token?input_token=%s&access_token=%s", apiVersion, inputToken, c.AccessToken)
+	url := fmt.Sprintf("%s/%s/debug_token?input_token=%s&access_token=%s", c.baseURL(), apiVersion, inputToken, c.AccessToken)
 
 	req, err := NewRequest(http.MethodGet, url, nil)
 	if err != nil {
@@ -49,7 +49,7 @@ func (c *Client) DebugToken(ctx context.Context, inputToken string) (TokenInfo,
 	}
 	req = req.WithContext(ctx)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return emptyd, fmt.Errorf("request failed: %w", err)
 	}
@@ -68,7 +68,7 @@ func (c *Client) DebugToken(ctx context.Context, inputToken string) (TokenInfo,
 
 func (c *Client) NewPermanentAccessToken(ctx context.Context, appID, appSecret, tempToken string) (string, error) {
 
-	url := fmt.Sprintf("https://graph.facebook.com/v13.0/oauth/access_token?grant_type=fb_exchange_token&client_id=%s&client_secret=%s&fb_exchange_token=%s", appID, appSecret, tempToken)
+	url := fmt.Sprintf("%s/%s/oauth/access_token?grant_type=fb_exchange_token&client_id=%s&client_secret=%s&fb_exchange_token=%s", c.baseURL(), c.graphVersion(), appID, appSecret, tempToken)
 
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
@@ -76,7 +76,7 @@ func (c *Client) NewPermanentAccessToken(ctx context.Context, appID, appSecret,
 	}
 	req = req.WithContext(ctx)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
 	}
@@ -0,0 +1,131 @@
+package fbgraph_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+	"github.com/pedidopago/wabaman-contrib/fbgraph/fbgraphtest"
+)
+
+// dropUploadAfter delivers only the first n bytes of the next upload chunk and
+// then fails the request, like a connection dropped mid-upload.
+func dropUploadAfter(n int64) fbgraph.Middleware {
+	dropped := false
+	return func(next http.RoundTripper) http.RoundTripper {
+		return fbgraph.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if dropped || r.Method != http.MethodPost || !strings.Contains(r.URL.Path, "/upload:") {
+				return next.RoundTrip(r)
+			}
+			dropped = true
+			partial := r.Clone(r.Context())
+			partial.Body = io.NopCloser(io.LimitReader(r.Body, n))
+			partial.ContentLength = n
+			resp, err := next.RoundTrip(partial)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			return nil, errors.New("connection reset by peer")
+		})
+	}
+}
+
+func TestUploadResumableContinuesAfterDrop(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+	c.Retry = &fbgraph.RetryPolicy{BaseDelay: time.Millisecond}
+	c.Middleware = []fbgraph.Middleware{dropUploadAfter(300)}
+
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	var offsets []int64
+	h, err := c.UploadResumable(context.Background(), "app", fbgraph.NewUploadSessionParams{FileLength: int64(len(data)), FileName: "v.mp4", FileType: "video/mp4"},
+		bytes.NewReader(data), fbgraph.ResumableUploadOptions{ChunkSize: 400, OnProgress: func(p fbgraph.UploadProgress) { offsets = append(offsets, p.Offset) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h == "" {
+		t.Fatal("empty handle")
+	}
+
+	sessions := map[string]bool{}
+	for _, r := range srv.Requests() {
+		if strings.Contains(r.Path, "/upload:") {
+			sessions[r.Path] = true
+		}
+	}
+	if len(sessions) != 1 {
+		t.Errorf("upload used sessions %v", sessions)
+	}
+	for path := range sessions {
+		us, _ := srv.UploadSession(path[strings.LastIndex(path, "/")+1:])
+		if !bytes.Equal(us.Data, data) || !us.Finished {
+			t.Errorf("session holds %d bytes, finished = %v", len(us.Data), us.Finished)
+		}
+	}
+	want := []int64{0, 300, 700, 1000}
+	if len(offsets) != len(want) {
+		t.Fatalf("progress = %v, want %v", offsets, want)
+	}
+	for i := range want {
+		if offsets[i] != want[i] {
+			t.Fatalf("progress = %v, want %v", offsets, want)
+		}
+	}
+}
+
+func TestResumeUploadGivesUp(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+	c.Retry = &fbgraph.RetryPolicy{BaseDelay: time.Millisecond}
+	ctx := context.Background()
+
+	id, err := c.NewUploadSessionWithContext(ctx, "app", fbgraph.NewUploadSessionParams{FileLength: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Fail(fbgraphtest.Failure{Method: http.MethodPost, PathSuffix: id, Error: fbgraphtest.ErrInvalidToken, Status: http.StatusUnauthorized})
+	_, err = c.ResumeUpload(ctx, id, strings.NewReader("0123456789"), 10, fbgraph.ResumableUploadOptions{})
+	var ue *fbgraph.ResumableUploadError
+	if !errors.As(err, &ue) || ue.SessionID != id || ue.Offset != 0 {
+		t.Fatalf("err = %v", err)
+	}
+	if !errors.Is(err, fbgraph.ErrAccessTokenExpired) {
+		t.Errorf("err = %v, want the token error in its chain", err)
+	}
+
+	h, err := c.ResumeUpload(ctx, id, strings.NewReader("0123456789"), 10, fbgraph.ResumableUploadOptions{})
+	if err != nil || h == "" {
+		t.Fatalf("resume: h = %q, err = %v", h, err)
+	}
+}
+
+func TestResumeUploadCompleteSession(t *testing.T) {
+	srv := fbgraphtest.NewServer()
+	defer srv.Close()
+	c := srv.Client("tok")
+	ctx := context.Background()
+
+	id, err := c.NewUploadSessionWithContext(ctx, "app", fbgraph.NewUploadSessionParams{FileLength: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ResumeUpload(ctx, id, strings.NewReader("0123456789"), 10, fbgraph.ResumableUploadOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	posts := srv.CountRequests(http.MethodPost, id)
+	_, err = c.ResumeUpload(ctx, id, strings.NewReader("0123456789"), 10, fbgraph.ResumableUploadOptions{})
+	if !errors.Is(err, fbgraph.ErrUploadSessionComplete) {
+		t.Errorf("err = %v, want ErrUploadSessionComplete", err)
+	}
+	if n := srv.CountRequests(http.MethodPost, id); n != posts {
+		t.Errorf("%d chunk(s) posted to a complete session", n-posts)
+	}
+}
@@ -133,8 +133,8 @@ func (c *Client) SetPaymentMethodMigrationIntent(ctx context.Context, sourceWABA
 		return nil, fmt.Errorf("marshal migration intent: %w", err)
 	}
 
-	u := fmt.Sprintf("https://graph.facebook.com/%s/%s/set_payment_method_migration_intent",
-		c.graphVersion(), url.PathEscape(sourceWABAID))
+	u := fmt.Sprintf("%s/%s/%s/set_payment_method_migration_intent",
+		c.baseURL(), c.graphVersion(), url.PathEscape(sourceWABAID))
 
 	req, err := NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
 	if err != nil {
@@ -143,7 +143,7 @@ func (c *Client) SetPaymentMethodMigrationIntent(ctx context.Context, sourceWABA
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -167,8 +167,8 @@ func (c *Client) SetPaymentMethodMigrationIntent(ctx context.Context, sourceWABA
 //
 // GET /{MIGRATION_ID}
 func (c *Client) GetMigrationStatus(ctx context.Context, migrationID string) (*MigrationStatusResponse, error) {
-	u := fmt.Sprintf("https://graph.facebook.com/%s/%s",
-		c.graphVersion(), url.PathEscape(migrationID))
+	u := fmt.Sprintf("%s/%s/%s",
+		c.baseURL(), c.graphVersion(), url.PathEscape(migrationID))
 
 	req, err := NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
@@ -176,7 +176,7 @@ func (c *Client) GetMigrationStatus(ctx context.Context, migrationID string) (*M
 	}
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -203,8 +203,8 @@ func (c *Client) GetMigrationStatus(ctx context.Context, migrationID string) (*M
 //
 // POST /{MIGRATION_ID}/resume_migration
 func (c *Client) ResumeMigration(ctx context.Context, migrationID string) (*MigrationStatusResponse, error) {
-	u := fmt.Sprintf("https://graph.facebook.com/%s/%s/resume_migration",
-		c.graphVersion(), url.PathEscape(migrationID))
+	u := fmt.Sprintf("%s/%s/%s/resume_migration",
+		c.baseURL(), c.graphVersion(), url.PathEscape(migrationID))
 
 	req, err := NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader([]byte("{}")))
 	if err != nil {
@@ -213,7 +213,7 @@ func (c *Client) ResumeMigration(ctx context.Context, migrationID string) (*Migr
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -280,8 +280,8 @@ func (c *Client) GetWABAPhoneNumbers(ctx context.Context, wabaID string) ([]WABA
 			q.Set("after", after)
 		}
 
-		u := fmt.Sprintf("https://graph.facebook.com/%s/%s/phone_numbers?%s",
-			c.graphVersion(), url.PathEscape(wabaID), q.Encode())
+		u := fmt.Sprintf("%s/%s/%s/phone_numbers?%s",
+			c.baseURL(), c.graphVersion(), url.PathEscape(wabaID), q.Encode())
 
 		req, err := NewRequestWithContext(ctx, http.MethodGet, u, nil)
 		if err != nil {
@@ -289,7 +289,7 @@ func (c *Client) GetWABAPhoneNumbers(ctx context.Context, wabaID string) ([]WABA
 		}
 		req.Header.Set("Authorization", "Bearer "+c.AccessToken)
 
-		resp, err := c.HTTPClient.Do(req)
+		resp, err := c.do(req)
 		if err != nil {
 			return nil, fmt.Errorf("request failed: %w", err)
 		}
@@ -385,8 +385,8 @@ func (c *Client) getWABAInfo(ctx context.Context, wabaID, fields string) (*WABAI
 	q := make(url.Values)
 	q.Set("fields", fields)
 
-	u := fmt.Sprintf("https://graph.facebook.com/%s/%s?%s",
-		c.graphVersion(), url.PathEscape(wabaID), q.Encode())
+	u := fmt.Sprintf("%s/%s/%s?%s",
+		c.baseURL(), c.graphVersion(), url.PathEscape(wabaID), q.Encode())
 
 	req, err := NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
@@ -394,7 +394,7 @@ func (c *Client) getWABAInfo(ctx context.Context, wabaID, fields string) (*WABAI
 	}
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
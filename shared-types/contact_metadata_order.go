@@ -5,7 +5,10 @@ import "encoding/json"
 type ContactMetadataOrder struct {
 	Status       OrderStatus        `json:"status,omitzero"`
 	Prescription *OrderPrescription `json:"prescription,omitzero"`
-	OtherFields  map[string]any     `json:"-" zajson:"-,remain"`
+	// PaymentReferenceID is the reference_id of the order_details message the
+	// order was sent with; payment status webhooks carry it.
+	PaymentReferenceID *string        `json:"payment_reference_id,omitzero"`
+	OtherFields        map[string]any `json:"-" zajson:"-,remain"`
 }
 
 type OrderPrescription struct {
@@ -81,8 +84,9 @@ func (p *OrderPrescription) UnmarshalJSON(data []byte) error {
 }
 
 var contactMetadataOrderKnownKeys = map[string]struct{}{
-	"status":       {},
-	"prescription": {},
+	"status":               {},
+	"prescription":         {},
+	"payment_reference_id": {},
 }
 
 func (o ContactMetadataOrder) MarshalJSON() ([]byte, error) {
@@ -128,6 +132,8 @@ func (o *ContactMetadataOrder) UnmarshalJSON(data []byte) error {
 			o.Status, err = internOrderStatus(v)
 		case "prescription":
 			err = json.Unmarshal(v, &o.Prescription)
+		case "payment_reference_id":
+			err = json.Unmarshal(v, &o.PaymentReferenceID)
 		default:
 			if o.OtherFields == nil {
 				o.OtherFields = make(map[string]any)
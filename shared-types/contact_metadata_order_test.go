@@ -41,6 +41,9 @@ func TestContactMetadataOrder_StatusInterning(t *testing.T) {
 		{`{"status": "finalized"}`, OrderStatusFinalized},
 		{`{"status": "prescription_collection"}`, OrderStatusPrescriptionCollection},
 		{`{"status": "cancelled"}`, OrderStatusCancelled},
+		{`{"status": "awaiting_payment"}`, OrderStatusAwaitingPayment},
+		{`{"status": "paid"}`, OrderStatusPaid},
+		{`{"status": "payment_failed"}`, OrderStatusPaymentFailed},
 		{`{"status": "$del"}`, OrderStatusDel},
 	} {
 		var o ContactMetadataOrder
@@ -228,3 +231,29 @@ func TestEqualOrderStatus(t *testing.T) {
 		t.Error("nil != non-nil should be false")
 	}
 }
+
+func TestContactMetadataOrder_PaymentReferenceID(t *testing.T) {
+	input := `{"status":"paid","payment_reference_id":"pedido-42"}`
+
+	var o ContactMetadataOrder
+	if err := json.Unmarshal([]byte(input), &o); err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != OrderStatusPaid {
+		t.Errorf("expected paid sentinel, got %v", o.Status)
+	}
+	if o.PaymentReferenceID == nil || *o.PaymentReferenceID != "pedido-42" {
+		t.Fatalf("payment_reference_id not decoded: %v", o.PaymentReferenceID)
+	}
+	if len(o.OtherFields) != 0 {
+		t.Errorf("payment_reference_id leaked into OtherFields: %v", o.OtherFields)
+	}
+
+	out, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != input {
+		t.Errorf("got %s, want %s", out, input)
+	}
+}
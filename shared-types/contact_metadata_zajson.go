@@ -940,6 +940,10 @@ func (v *ContactMetadataOrder) ZMarshalJSON(w *zajson.Writer) error {
 			return err
 		}
 	}
+	if v.PaymentReferenceID != nil {
+		w.WriteCommaFieldName(",\"payment_reference_id\":")
+		w.WriteQuotedString((*v.PaymentReferenceID))
+	}
 	if v.OtherFields != nil {
 		_rkeys := make([]string, 0, len(v.OtherFields))
 		for _rk := range v.OtherFields {
@@ -948,7 +952,7 @@ func (v *ContactMetadataOrder) ZMarshalJSON(w *zajson.Writer) error {
 		slices.Sort(_rkeys)
 		for _, _rk := range _rkeys {
 			switch _rk {
-			case "status", "prescription":
+			case "status", "prescription", "payment_reference_id":
 				// skip: known field wins on collision
 			default:
 				w.WriteDynamicFieldName(_rk)
@@ -994,6 +998,23 @@ func (v *ContactMetadataOrder) ZUnmarshalJSON(r *zajson.Reader) error {
 					return _err
 				}
 			}
+		case "payment_reference_id":
+			if r.PeekNull() {
+				if _err := r.ReadNull(); _err != nil {
+					return _err
+				}
+				v.PaymentReferenceID = nil
+			} else {
+				_ptr := new(string)
+				v.PaymentReferenceID = _ptr
+				{
+					_val, _err := r.ReadString()
+					if _err != nil {
+						return _err
+					}
+					(*v.PaymentReferenceID) = strings.Clone(_val)
+				}
+			}
 		default:
 			if v.OtherFields == nil {
 				v.OtherFields = make(map[string]any)
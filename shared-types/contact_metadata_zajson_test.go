@@ -476,6 +476,9 @@ func TestZ_ContactMetadataOrder_StatusInterning(t *testing.T) {
 		{`{"status": "finalized"}`, OrderStatusFinalized},
 		{`{"status": "prescription_collection"}`, OrderStatusPrescriptionCollection},
 		{`{"status": "cancelled"}`, OrderStatusCancelled},
+		{`{"status": "awaiting_payment"}`, OrderStatusAwaitingPayment},
+		{`{"status": "paid"}`, OrderStatusPaid},
+		{`{"status": "payment_failed"}`, OrderStatusPaymentFailed},
 		{`{"status": "$del"}`, OrderStatusDel},
 	} {
 		o := zunmarshal[*ContactMetadataOrder](t, []byte(tc.json))
@@ -485,6 +488,21 @@ func TestZ_ContactMetadataOrder_StatusInterning(t *testing.T) {
 	}
 }
 
+func TestZ_ContactMetadataOrder_PaymentReferenceID(t *testing.T) {
+	input := []byte(`{"status":"awaiting_payment","payment_reference_id":"pedido-42"}`)
+
+	o := zunmarshal[*ContactMetadataOrder](t, input)
+	if o.Status != OrderStatusAwaitingPayment {
+		t.Error("Status pointer comparison failed")
+	}
+	if o.PaymentReferenceID == nil || *o.PaymentReferenceID != "pedido-42" {
+		t.Fatalf("PaymentReferenceID = %v", o.PaymentReferenceID)
+	}
+	if out := zmarshal(t, o); string(out) != string(input) {
+		t.Errorf("got %s, want %s", out, input)
+	}
+}
+
 func TestZ_ContactMetadataOrder_Overflow(t *testing.T) {
 	input := []byte(`{"status": "created", "tracking_code": "BR123", "weight": 1.5}`)
 
@@ -17,6 +17,9 @@ var (
 	orderStatusFinalized              = "finalized"
 	orderStatusPrescriptionCollection = "prescription_collection"
 	orderStatusCancelled              = "cancelled"
+	orderStatusAwaitingPayment        = "awaiting_payment"
+	orderStatusPaid                   = "paid"
+	orderStatusPaymentFailed          = "payment_failed"
 
 	OrderStatusAssembling             OrderStatus = &orderStatusAssembling
 	OrderStatusApproved               OrderStatus = &orderStatusApproved
@@ -24,6 +27,9 @@ var (
 	OrderStatusFinalized              OrderStatus = &orderStatusFinalized
 	OrderStatusPrescriptionCollection OrderStatus = &orderStatusPrescriptionCollection
 	OrderStatusCancelled              OrderStatus = &orderStatusCancelled
+	OrderStatusAwaitingPayment        OrderStatus = &orderStatusAwaitingPayment
+	OrderStatusPaid                   OrderStatus = &orderStatusPaid
+	OrderStatusPaymentFailed          OrderStatus = &orderStatusPaymentFailed
 	OrderStatusDel                    OrderStatus = &delStr
 )
 
@@ -34,6 +40,9 @@ var orderStatusIntern = map[string]OrderStatus{
 	"finalized":               OrderStatusFinalized,
 	"prescription_collection": OrderStatusPrescriptionCollection,
 	"cancelled":               OrderStatusCancelled,
+	"awaiting_payment":        OrderStatusAwaitingPayment,
+	"paid":                    OrderStatusPaid,
+	"payment_failed":          OrderStatusPaymentFailed,
 	"$del":                    OrderStatusDel,
 }
 
@@ -3,6 +3,7 @@ package whapi
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 
 	"github.com/pedidopago/wabaman-contrib/fbgraph"
 	wtypes "github.com/pedidopago/wabaman-contrib/shared-types"
@@ -276,6 +277,7 @@ const (
 	MOTypeReaction    MessageObjectType = "reaction"
 	MOTypeLocation    MessageObjectType = "location"
 	MOTypeUnsupported MessageObjectType = "unsupported"
+	MOTypeOrder       MessageObjectType = "order"
 )
 
 func (m MessageObjectType) String() string {
@@ -284,7 +286,7 @@ func (m MessageObjectType) String() string {
 
 func (m MessageObjectType) IsValid() bool {
 	switch m {
-	case MOTypeAudio, MOTypeButton, MOTypeDocument, MOTypeText, MOTypeImage, MOTypeInteractive, MOTypeSticker, MOTypeSystem, MOTypeUnknown, MOTypeVideo, MOTypeReaction, MOTypeContacts, MOTypeLocation:
+	case MOTypeAudio, MOTypeButton, MOTypeDocument, MOTypeText, MOTypeImage, MOTypeInteractive, MOTypeSticker, MOTypeSystem, MOTypeUnknown, MOTypeVideo, MOTypeReaction, MOTypeContacts, MOTypeLocation, MOTypeOrder:
 		return true
 	}
 	return false
@@ -350,6 +352,9 @@ type MessageObject struct {
 	System *MessageObjectSystem `json:"system,omitempty"`
 	// When messages type is set to location.
 	Location *MessageObjectLocation `json:"location,omitempty"`
+	// When messages type is set to order, the cart a customer sent from a
+	// product or product_list message.
+	Order *MessageObjectOrder `json:"order,omitempty"`
 	// When messages type is set to text, this object is included. This object
 	// includes the following field:
 	Text *MessageObjectText `json:"text,omitempty"`
@@ -452,7 +457,7 @@ type MessageObjectImage struct {
 }
 
 type MessageObjectInteractive struct {
-	// button_reply, list_reply, or call_permission_reply
+	// button_reply, list_reply, call_permission_reply or nfm_reply
 	Type string `json:"type"`
 	// Sent when a customer clicks a button
 	ButtonReply *ButtonReply `json:"button_reply,omitempty"`
@@ -461,8 +466,16 @@ type MessageObjectInteractive struct {
 	// Sent when a customer responds to a call permission request (accept/reject, temporary/permanent),
 	// or when permission is automatically granted/revoked.
 	CallPermissionReply *CallPermissionReply `json:"call_permission_reply,omitempty"`
+	// Sent when a customer completes a flow or an address message. Decode
+	// its response_json with NfmReply.Decode, or NfmReply.Address.
+	//
+	// A location_request_message is answered with a regular location
+	// message, and a voice_call button with a call in the calls webhook.
+	NfmReply *NfmReply `json:"nfm_reply,omitempty"`
 }
 
+type NfmReply = fbgraph.NfmReply
+
 type ButtonReply struct {
 	// Unique ID of a button
 	ID string `json:"id"`
@@ -530,6 +543,77 @@ type MessageObjectSystem struct {
 
 type MessageObjectLocation = fbgraph.LocationObject
 
+// MessageObjectOrder is the cart a customer checked out from a product or
+// product_list message.
+type MessageObjectOrder struct {
+	CatalogID string `json:"catalog_id"`
+	// Text is the message the customer wrote along with the cart.
+	Text         string                   `json:"text,omitempty"`
+	ProductItems []MessageObjectOrderItem `json:"product_items"`
+}
+
+type MessageObjectOrderItem = wsapi.OrderItem
+
+// Total returns the sum of item_price * quantity of the items and their
+// currency. Prices are rounded to cents before they are added up. A cart whose
+// items are in different currencies has no total and returns an error.
+func (o *MessageObjectOrder) Total() (float64, string, error) {
+	var cents int64
+	var currency string
+	for i, it := range o.ProductItems {
+		if i == 0 {
+			currency = it.Currency
+		} else if it.Currency != currency {
+			return 0, "", fmt.Errorf("order has items in %s and %s", currency, it.Currency)
+		}
+		cents += int64(math.Round(it.ItemPrice*100)) * int64(it.Quantity)
+	}
+	return float64(cents) / 100, currency, nil
+}
+
+// ClientOrder returns the order as shown in wsapi.ClientMessage. Total and
+// Currency are left unset when the items are in different currencies.
+func (o *MessageObjectOrder) ClientOrder() *wsapi.Order {
+	co := &wsapi.Order{
+		CatalogID: o.CatalogID,
+		Text:      o.Text,
+		Items:     o.ProductItems,
+	}
+	if total, currency, err := o.Total(); err == nil {
+		co.Total = &total
+		co.Currency = currency
+	}
+	return co
+}
+
+// ContactMetadataOrder returns a created order to prefill the contact
+// metadata with. The cart goes to OtherFields, under catalog_id, items,
+// total and currency; total and currency are left out when the items are in
+// different currencies.
+func (o *MessageObjectOrder) ContactMetadataOrder() wtypes.ContactMetadataOrder {
+	items := make([]any, 0, len(o.ProductItems))
+	for _, it := range o.ProductItems {
+		items = append(items, map[string]any{
+			"product_retailer_id": it.ProductRetailerID,
+			"quantity":            float64(it.Quantity),
+			"item_price":          it.ItemPrice,
+			"currency":            it.Currency,
+		})
+	}
+	fields := map[string]any{
+		"catalog_id": o.CatalogID,
+		"items":      items,
+	}
+	if total, currency, err := o.Total(); err == nil {
+		fields["total"] = total
+		fields["currency"] = currency
+	}
+	return wtypes.ContactMetadataOrder{
+		Status:      wtypes.OrderStatusCreated,
+		OtherFields: fields,
+	}
+}
+
 type SystemMessageType string
 
 const (
@@ -606,13 +690,55 @@ type StatusObject struct {
 	Timestamp             string                      `json:"timestamp,omitempty"`
 	Errors                []wsapi.FBStatusObjectError `json:"errors,omitempty"`
 	// Type is the kind of status entry. Currently empty (default) for message status webhooks,
-	// and "call" for the calls status webhook variant introduced for business-initiated calling.
+	// "call" for the calls status webhook variant introduced for business-initiated calling,
+	// and "payment" for the payment status of an order_details message.
 	Type string `json:"type,omitempty"`
+	// Payment is set when Type == "payment".
+	Payment *StatusPaymentObject `json:"payment,omitempty"`
 	// BizOpaqueCallbackData is the arbitrary string the business passed in when initiating
 	// a call (or accepting one). Echoed back in subsequent call-related webhooks.
 	BizOpaqueCallbackData string `json:"biz_opaque_callback_data,omitempty"`
 }
 
+// PaymentOrderStatus maps the status of a payment webhook to the order
+// status kept in the contact metadata. It returns nil when s is not a
+// payment status.
+func (s StatusObject) PaymentOrderStatus() wtypes.OrderStatus {
+	if s.Type != "payment" {
+		return nil
+	}
+	switch s.Status {
+	case MessageStatusPaymentCaptured:
+		return wtypes.OrderStatusPaid
+	case MessageStatusPaymentPending:
+		return wtypes.OrderStatusAwaitingPayment
+	case MessageStatusPaymentFailed:
+		return wtypes.OrderStatusPaymentFailed
+	}
+	return nil
+}
+
+// StatusPaymentObject is the payment of an order_details message, identified
+// by the reference_id it was sent with.
+type StatusPaymentObject struct {
+	ReferenceID string               `json:"reference_id"`
+	Amount      *fbgraph.OrderAmount `json:"amount,omitempty"`
+	Currency    string               `json:"currency,omitempty"`
+	Transaction *PaymentTransaction  `json:"transaction,omitempty"`
+}
+
+// PaymentTransaction is the transaction of the payment provider behind a
+// payment status.
+type PaymentTransaction struct {
+	ID string `json:"id"`
+	// Type is the payment method, e.g. "pix" or "boleto".
+	Type             string       `json:"type,omitempty"`
+	Status           string       `json:"status,omitempty"`
+	CreatedTimestamp Timestamp    `json:"created_timestamp,omitempty"`
+	UpdatedTimestamp Timestamp    `json:"updated_timestamp,omitempty"`
+	Error            *ErrorObject `json:"error,omitempty"`
+}
+
 func (s StatusObject) JSONPrintErrors() string {
 	if len(s.Errors) == 1 {
 		eout, err := json.MarshalIndent(s.Errors[0], "", "  ")
@@ -804,6 +930,11 @@ const (
 	MessageStatusCallRinging  MessageStatus = "RINGING"
 	MessageStatusCallAccepted MessageStatus = "ACCEPTED"
 	MessageStatusCallRejected MessageStatus = "REJECTED"
+
+	// Payment status values for StatusObject when Type == "payment".
+	MessageStatusPaymentCaptured MessageStatus = "captured"
+	MessageStatusPaymentPending  MessageStatus = "pending"
+	MessageStatusPaymentFailed   MessageStatus = "failed"
 )
 
 // WhatsApp defines a conversation as a 24-hour session of messaging between a
@@ -1041,6 +1172,25 @@ type ErrorObject struct {
 	} `json:"error_data"`
 }
 
+func (e ErrorObject) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s (%d): %s", e.Title, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s (%d)", e.Title, e.Code)
+}
+
+// Is matches e against the fbgraph error catalog, so that e.g.
+// errors.Is(e, fbgraph.ErrReEngagementRequired) works on webhook errors.
+func (e ErrorObject) Is(target error) bool {
+	return fbgraph.MatchErrorCode(int(e.Code), 0, target)
+}
+
+// GraphCode makes e a fbgraph.GraphCoder, so fbgraph.ClassifyError works on
+// webhook errors.
+func (e ErrorObject) GraphCode() int {
+	return int(e.Code)
+}
+
 type ErrorCode int
 
 const (
@@ -1072,176 +1222,122 @@ const (
 	ECodeInvalidParameter ErrorCode = 100
 )
 
-// TODO: add error codes below
-
-// 190 – Token de acesso expirado
-
-// O token de acesso expirou.
-// Solução possível: obtenha um novo token de acesso.
-
-// 200-299 – Permissão de API (múltiplos valores dependem da permissão)
-
-// A permissão não foi concedida ou foi removida. Saiba como lidar com permissões ausentes.
-
-// 200 (subcódigo = 2494049) — Telefone não permitido
-
-// Esse telefone não pode ser integrado à API de Nuvem.
-
-// 368 – Bloqueio temporário devido a violações das políticas
-
-// Aguarde um momento e refaça a operação. Saiba mais sobre a nossa Aplicação de políticas.
-
-// 506 – Publicação duplicada
-
-// Publicações duplicadas não podem ser publicadas consecutivamente.
-// Solução possível: altere o conteúdo da publicação e tente novamente.
-
-// 80007 – Problemas de limite de volume
-
-// Você atingiu a limitação de volume da plataforma.
-
-// 131052 – Erro ao baixar mídia
-
-// Falha ao baixar a mídia do remetente.
-
-// 131042 – Elegibilidade da empresa (problema de pagamento)
-
-// Falha ao enviar mensagem devido a um ou mais erros relacionados à forma de pagamento.
-// Para resolver esse erro, verifique se a sua conta do WhatsApp Business tem os seguintes itens configurados: fuso horário, moeda e forma de pagamento. Depois, verifique se a sua conta tem solicitações MessagingFor pendentes ou recusadas. Saiba mais sobre a configuração de cobrança.
-
-// 131043 – Mensagem expirada
-
-// Mensagem não enviada durante seu TTL (tempo de vida).
-
-// 130429 – Limitação de volume atingida
-
-// Falha ao enviar a mensagem porque o número de telefone fez envios demais em um curto período.
-
-// 131045 – Certificado não assinado
-
-// Falha ao enviar a mensagem porque ocorreu um erro relacionado ao certificado.
-
-// 131016 – Serviço sobrecarregado
-
-// O serviço está sobrecarregado. Aguarde um momento e refaça a operação. Em caso de problemas, visite o painel de status da Plataforma do WhatsApp Business.
-
-// 131047 – Mensagem de reengajamento
-
-// Falha ao enviar a mensagem porque mais de 24 horas se passaram desde a última vez que o cliente respondeu a este número.
-// Solução possível: envie uma mensagem iniciada pela empresa usando um modelo de mensagem para responder.
-
-// 131048 – Limite de taxa de spam atingido
-
-// Falha ao enviar a mensagem porque há um limite de envios que podem ser feitos deste número de telefone. É possível que muitas mensagens anteriores tenham sido bloqueadas ou marcadas como spam.
-// Verifique seu status de qualidade no Gerenciador do WhatsApp e visite a documentação Limitações de volume com base em qualidade para saber mais.
-
-// 131000 – Erro genérico
-
-// Falha ao enviar a mensagem devido a um erro desconhecido.
-
-// 131001 – Mensagem muito longa
-
-// O tamanho da mensagem é superior a 4.096 caracteres.
-
-// 131002 – Tipo de destinatário inválido
-
-// O valor recipient_type só pode ser individual.
-
-// 131005 – Acesso negado
-
-// O número já está registrado no WhatsApp. Consulte Como migrar um número de telefone.
-
-// 131006 – Recurso não encontrado
-
-// Arquivo ou recurso não encontrado.
-
-// 131008 – Parâmetro obrigatório ausente
-
-// Um parâmetro obrigatório está ausente. Acesse a referência de ponto de extremidade para saber mais sobre os requisitos de parâmetro.
-
-// 131009 – Valor do parâmetro inválido
-
-// O valor inserido para um parâmetro não é do tipo correto ou há outro problema. Acesse a referência de ponto de extremidade para saber mais sobre os requisitos de parâmetro. Esse erro também é retornado se o telefone do destinatário não for um número de telefone válido do WhatsApp ou se o telefone do remetente não for um número de telefone registrado no WhatsApp.
-
-// 131021 – Usuário incorreto
-
-// Essa mensagem será recebida quando você enviar uma mensagem para si mesmo.
-
-// 131031 – A conta do remetente está bloqueada
-
-// Sua conta foi bloqueada e não poderá enviar mensagens devido a uma violação da política de integridade.
-// Consulte a Aplicação da Política da Plataforma do WhatsApp Business para saber mais.
-
-// 131051 – Tipo de mensagem não aceito
-
-// No momento, esse tipo de mensagem não é aceito.
-
-// 131055 — Método não permitido
-
-// O método que você está tentando usar não é permitido.
-
-// 132000 – Incompatibilidade na contagem de parâmetros do modelo
-
-// O número de parâmetros fornecidos não corresponde à quantidade esperada.
-
-// 132001 – Modelo inexistente
-
-// O modelo não existe no idioma especificado ou não foi aprovado.
-// Verifique se o modelo foi aprovado e se o nome do modelo e a localidade do idioma estão corretos. Saiba mais.
-
-// 132005 – Texto hidratado do modelo longo demais
-
-// O texto traduzido é longo demais
-// Verifique se o modelo foi traduzido corretamente. Saiba mais.
-
-// 132007 – Política de caracteres do formato do modelo violada
-
-// A política de caracteres do formato foi violada
-// Saiba mais sobre as diretrizes de modelo de mensagem.
-
-// 132012 – Incompatibilidade no formato do parâmetro do modelo
-
-// O formato do parâmetro não corresponde ao do modelo criado.
-// Verifique se o número de respostas do cliente retornado por um modelo é o número exigido por seu modelo. Saiba mais.
-
-// 133000 – Exclusão incompleta
-
-// Uma operação anterior de exclusão falhou. Antes de se registrar novamente, é necessária uma nova tentativa de exclusão.
-
-// 133001 – Erro ao descriptografar
-
-// Por um motivo desconhecido, a descriptografia do blob de backup falhou.
-
-// 133002 – Erro ao descriptografar o blob de backup
-
-// Falha ao descriptografar o blob de backup devido a formato inválido ou senha incorreta.
-
-// 133003 – Erro ao descriptografar o token de recuperação
-
-// Falha ao descriptografar o token de recuperação devido a formato inválido ou senha incorreta.
-
-// 133004 – Servidor temporariamente indisponível
-
-// O servidor de registro está temporariamente indisponível.
-
-// 133005 – PIN de segurança incompatível
-
-// O PIN está incorreto.
-
-// Verifique se você está usando o PIN correto e tente de novo. Saiba mais.
-
-// 133006 – Token de recuperação incorreto
-
-// O token de recuperação usado para a migração está obsoleto.
-
-// 133007 – Conta bloqueada
-
-// A conta foi bloqueada pelo servidor de registro.
-
-// 133008 – Muitas tentativas de PIN
-
-// Foram feitas muitas tentativas de PIN nesta conta.
-
-// 133009 – PIN inserido muito rapidamente
+const (
+	// O token de acesso expirou. Solução possível: obtenha um novo token de acesso.
+	ECodeAccessTokenExpired ErrorCode = 190
+	// 200-299: a permissão não foi concedida ou foi removida.
+	ECodeAPIPermission ErrorCode = 200
+	// Bloqueio temporário devido a violações das políticas.
+	ECodePolicyBlock ErrorCode = 368
+	// Publicações duplicadas não podem ser publicadas consecutivamente.
+	ECodeDuplicatePost ErrorCode = 506
+	// Você atingiu a limitação de volume da plataforma.
+	ECodeRateLimitIssues ErrorCode = 80007
+	// Falha ao enviar a mensagem porque o número de telefone fez envios demais
+	// em um curto período.
+	ECodeRateLimitHit ErrorCode = 130429
+	// O número do cliente faz parte de um experimento do Meta.
+	ECodeExperimentNumber ErrorCode = 130472
+	// A conta está proibida de enviar mensagens para usuários deste país.
+	ECodeCountryRestricted ErrorCode = 130497
+	// Falha ao enviar a mensagem devido a um erro desconhecido.
+	ECodeGeneric ErrorCode = 131000
+	// O tamanho da mensagem é superior a 4.096 caracteres.
+	ECodeMessageTooLong ErrorCode = 131001
+	// O valor recipient_type só pode ser individual.
+	ECodeInvalidRecipientType ErrorCode = 131002
+	// O número já está registrado no WhatsApp.
+	ECodeAccessDenied ErrorCode = 131005
+	// Arquivo ou recurso não encontrado.
+	ECodeResourceNotFound ErrorCode = 131006
+	// Um parâmetro obrigatório está ausente.
+	ECodeRequiredParamMissing ErrorCode = 131008
+	// O valor inserido para um parâmetro não é do tipo correto, ou o telefone
+	// do destinatário não é um número de WhatsApp válido.
+	ECodeParamValueInvalid ErrorCode = 131009
+	// O serviço está sobrecarregado. Aguarde um momento e refaça a operação.
+	ECodeServiceUnavailable ErrorCode = 131016
+	// A mensagem foi enviada para o próprio remetente.
+	ECodeRecipientIsSender ErrorCode = 131021
+	// A mensagem não pode ser entregue ao destinatário.
+	ECodeUndeliverable ErrorCode = 131026
+	// A conta do remetente está bloqueada por violação da política de integridade.
+	ECodeAccountLocked ErrorCode = 131031
+	// Falha ao enviar a mensagem devido a um problema com a forma de pagamento.
+	ECodePaymentIssue ErrorCode = 131042
+	// Mensagem não enviada durante seu TTL (tempo de vida).
+	ECodeMessageExpired ErrorCode = 131043
+	// Falha ao enviar a mensagem porque ocorreu um erro relacionado ao certificado.
+	ECodeIncorrectCertificate ErrorCode = 131045
+	// Mais de 24 horas se passaram desde a última resposta do cliente; envie
+	// um modelo de mensagem.
+	ECodeReEngagementRequired ErrorCode = 131047
+	// Limite de envios atingido porque muitas mensagens foram bloqueadas ou
+	// marcadas como spam.
+	ECodeSpamRateLimit ErrorCode = 131048
+	// O Meta escolheu não entregar esta mensagem.
+	ECodeEcosystemEngagement ErrorCode = 131049
+	// O cliente parou de receber mensagens de marketing.
+	ECodeMarketingOptOut ErrorCode = 131050
+	// No momento, esse tipo de mensagem não é aceito.
+	ECodeUnsupportedMessageType ErrorCode = 131051
+	// Falha ao baixar a mídia do remetente.
+	ECodeMediaDownload ErrorCode = 131052
+	// Falha ao enviar a mídia.
+	ECodeMediaUpload ErrorCode = 131053
+	// O método que você está tentando usar não é permitido.
+	ECodeMethodNotAllowed ErrorCode = 131055
+	// Muitas mensagens enviadas para o mesmo cliente em um curto período.
+	ECodePairRateLimit ErrorCode = 131056
+	// A conta está em modo de manutenção.
+	ECodeMaintenanceMode ErrorCode = 131057
+	// O número de parâmetros fornecidos não corresponde ao do modelo.
+	ECodeTemplateParamCount ErrorCode = 132000
+	// O modelo não existe no idioma especificado ou não foi aprovado.
+	ECodeTemplateNotFound ErrorCode = 132001
+	// O texto traduzido do modelo é longo demais.
+	ECodeTemplateTextTooLong ErrorCode = 132005
+	// A política de caracteres do formato do modelo foi violada.
+	ECodeTemplateFormatPolicy ErrorCode = 132007
+	// O formato do parâmetro não corresponde ao do modelo criado.
+	ECodeTemplateParamFormat ErrorCode = 132012
+	// O modelo está pausado por baixa qualidade.
+	ECodeTemplatePaused ErrorCode = 132015
+	// O modelo foi desativado por baixa qualidade.
+	ECodeTemplateDisabled ErrorCode = 132016
+	// Uma operação anterior de exclusão falhou; refaça-a antes de registrar.
+	ECodeIncompleteDeregistration ErrorCode = 133000
+	// Por um motivo desconhecido, a descriptografia do blob de backup falhou.
+	ECodeDecryptionError ErrorCode = 133001
+	// Falha ao descriptografar o blob de backup devido a formato inválido ou
+	// senha incorreta.
+	ECodeBackupBlobDecryption ErrorCode = 133002
+	// Falha ao descriptografar o token de recuperação devido a formato
+	// inválido ou senha incorreta.
+	ECodeRecoveryTokenDecryption ErrorCode = 133003
+	// O servidor de registro está temporariamente indisponível.
+	ECodeServerTemporarilyUnavailable ErrorCode = 133004
+	// O PIN de segurança está incorreto.
+	ECodeTwoStepPINMismatch ErrorCode = 133005
+	// O token de recuperação usado para a migração está obsoleto.
+	ECodePhoneNeedsReverification ErrorCode = 133006
+	// A conta foi bloqueada pelo servidor de registro.
+	ECodeAccountBlocked ErrorCode = 133007
+	// Foram feitas muitas tentativas de PIN nesta conta.
+	ECodeTooManyPINGuesses ErrorCode = 133008
+	// A solicitação de registro foi feita com muita rapidez.
+	ECodePINGuessedTooFast ErrorCode = 133009
+	// O número de telefone não está registrado na WhatsApp Business Platform.
+	ECodePhoneNotRegistered ErrorCode = 133010
+	// Aguarde alguns minutos antes de tentar registrar novamente.
+	ECodeRegisterTooSoon ErrorCode = 133015
+	// Muitas tentativas de registro ou exclusão.
+	ECodeRegisterRateLimit ErrorCode = 133016
+	// Erro genérico do usuário.
+	ECodeGenericUserError ErrorCode = 135000
+)
 
-// A solicitação de registro foi feita com muita rapidez.
+// Category returns the category of the code in the fbgraph error catalog.
+func (c ErrorCode) Category() fbgraph.ErrorCategory {
+	return fbgraph.ErrorCodeCategory(int(c), 0)
+}
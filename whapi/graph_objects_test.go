@@ -2,7 +2,12 @@ package whapi
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+	wtypes "github.com/pedidopago/wabaman-contrib/shared-types"
 )
 
 func TestUserIDUpdateUnmarshal(t *testing.T) {
@@ -87,3 +92,169 @@ func TestUserChangedUserIDSystemMessageUnmarshal(t *testing.T) {
 		t.Errorf("System.ParentUserID = %q", m.System.ParentUserID)
 	}
 }
+
+func TestNfmReplyUnmarshal(t *testing.T) {
+	input := `{
+		"from": "5511987654321",
+		"id": "wamid.HBgNNTUxMTk4NzY1NDMyMRUCABIYFDNBQjM0QUE1RjM2NjlCOTI5RTQ0AA==",
+		"timestamp": "1750100472",
+		"type": "interactive",
+		"interactive": {
+			"type": "nfm_reply",
+			"nfm_reply": {
+				"name": "flow",
+				"body": "Sent",
+				"response_json": "{\"flow_token\":\"order-42\",\"delivery_date\":\"2026-10-20\"}"
+			}
+		}
+	}`
+
+	var m MessageObject
+	if err := json.Unmarshal([]byte(input), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Interactive == nil || m.Interactive.NfmReply == nil {
+		t.Fatal("Interactive.NfmReply is nil")
+	}
+	token, err := m.Interactive.NfmReply.FlowToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "order-42" {
+		t.Errorf("FlowToken() = %q", token)
+	}
+	var resp struct {
+		DeliveryDate string `json:"delivery_date"`
+	}
+	if err := m.Interactive.NfmReply.Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.DeliveryDate != "2026-10-20" {
+		t.Errorf("delivery_date = %q", resp.DeliveryDate)
+	}
+}
+
+func TestPaymentStatusUnmarshal(t *testing.T) {
+	input := `{
+		"id": "wamid.HBgNNTUxMTk4NzY1NDMyMRUCABEYEjQ2QjVCRjM1QzQ4NzE4MzI2NwA=",
+		"recipient_id": "5511987654321",
+		"status": "captured",
+		"timestamp": "1750100472",
+		"type": "payment",
+		"payment": {
+			"reference_id": "pedido-42",
+			"amount": {"value": 2490, "offset": 100},
+			"currency": "BRL",
+			"transaction": {
+				"id": "E1234567820261017123456789012345",
+				"type": "pix",
+				"status": "success",
+				"created_timestamp": 1750100400,
+				"updated_timestamp": 1750100470
+			}
+		}
+	}`
+
+	var s StatusObject
+	if err := json.Unmarshal([]byte(input), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Payment == nil || s.Payment.ReferenceID != "pedido-42" {
+		t.Fatalf("Payment = %+v", s.Payment)
+	}
+	if s.Payment.Amount == nil || s.Payment.Amount.Value != 2490 {
+		t.Errorf("Amount = %+v", s.Payment.Amount)
+	}
+	if s.Payment.Transaction == nil || s.Payment.Transaction.CreatedTimestamp != "1750100400" {
+		t.Errorf("Transaction = %+v", s.Payment.Transaction)
+	}
+	if s.PaymentOrderStatus() != wtypes.OrderStatusPaid {
+		t.Errorf("PaymentOrderStatus() = %v", s.PaymentOrderStatus())
+	}
+
+	s.Type = ""
+	if s.PaymentOrderStatus() != nil {
+		t.Error("PaymentOrderStatus() of a message status is not nil")
+	}
+}
+
+func TestOrderMessageUnmarshal(t *testing.T) {
+	input := `{
+		"from": "5511987654321",
+		"id": "wamid.HBgNNTUxMTk4NzY1NDMyMRUCABIYFDNBNTVCMjU0QkU2RjY5QjM3RTlGAA==",
+		"timestamp": "1750100472",
+		"type": "order",
+		"order": {
+			"catalog_id": "1234567890",
+			"text": "Pode entregar hoje?",
+			"product_items": [
+				{"product_retailer_id": "dipirona", "quantity": 3, "item_price": 12.9, "currency": "BRL"},
+				{"product_retailer_id": "soro", "quantity": 1, "item_price": 7.35, "currency": "BRL"}
+			]
+		}
+	}`
+
+	var m MessageObject
+	if err := json.Unmarshal([]byte(input), &m); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Type.IsValid() || m.Order == nil {
+		t.Fatalf("Type = %q, Order = %+v", m.Type, m.Order)
+	}
+
+	co := m.Order.ClientOrder()
+	if co.Total == nil || *co.Total != 46.05 || co.Currency != "BRL" {
+		t.Errorf("Total = %v %s, want 46.05 BRL", co.Total, co.Currency)
+	}
+	if len(co.Items) != 2 || co.Items[0].ProductRetailerID != "dipirona" || co.Text != "Pode entregar hoje?" {
+		t.Errorf("ClientOrder() = %+v", co)
+	}
+
+	md := m.Order.ContactMetadataOrder()
+	if md.Status != wtypes.OrderStatusCreated {
+		t.Errorf("Status = %v", md.Status)
+	}
+	if md.OtherFields["catalog_id"] != "1234567890" || md.OtherFields["total"] != 46.05 {
+		t.Errorf("OtherFields = %v", md.OtherFields)
+	}
+	if items, _ := md.OtherFields["items"].([]any); len(items) != 2 {
+		t.Errorf("items = %v", md.OtherFields["items"])
+	}
+
+	m.Order.ProductItems = append(m.Order.ProductItems, MessageObjectOrderItem{ProductRetailerID: "seringa", Quantity: 1, ItemPrice: 2, Currency: "USD"})
+	if _, _, err := m.Order.Total(); err == nil {
+		t.Error("Total() of a mixed-currency cart did not fail")
+	}
+	if co := m.Order.ClientOrder(); co.Total != nil || co.Currency != "" {
+		t.Errorf("mixed-currency ClientOrder() = %+v", co)
+	}
+	if b, _ := json.Marshal(m.Order.ClientOrder()); strings.Contains(string(b), `"total"`) {
+		t.Errorf("mixed-currency order has a total: %s", b)
+	}
+	if _, ok := m.Order.ContactMetadataOrder().OtherFields["total"]; ok {
+		t.Error("mixed-currency metadata has a total")
+	}
+}
+
+func TestErrorObjectIsCatalogued(t *testing.T) {
+	var v ValueObject
+	if err := json.Unmarshal([]byte(`{"messaging_product":"whatsapp","errors":[{"code":131047,"title":"Re-engagement message"}]}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	var err error = v.Errors[0]
+	if !errors.Is(err, fbgraph.ErrReEngagementRequired) {
+		t.Errorf("%v is not ErrReEngagementRequired", err)
+	}
+	if !errors.Is(err, fbgraph.CategoryNeedsUserAction) || errors.Is(err, fbgraph.CategoryRetryable) {
+		t.Errorf("%v has the wrong category", err)
+	}
+	if ECodePairRateLimit.Category() != fbgraph.CategoryRetryable {
+		t.Errorf("131056 category = %v", ECodePairRateLimit.Category())
+	}
+	if fbgraph.ClassifyError(err) != fbgraph.CategoryNeedsUserAction {
+		t.Errorf("ClassifyError(%v) = %v", err, fbgraph.ClassifyError(err))
+	}
+	if errors.Is(ErrorObject{}, fbgraph.ErrAuthException) {
+		t.Error("a zero ErrorObject matches ErrAuthException")
+	}
+}
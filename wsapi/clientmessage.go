@@ -33,6 +33,7 @@ type ClientMessage struct {
 	Contacts    []fbgraph.ContactObject `json:"contacts,omitempty"`
 	Button      *Button                 `json:"button,omitempty"`
 	Location    *Location               `json:"location,omitempty"`
+	Order       *Order                  `json:"order,omitempty"`
 	Preview     string                  `json:"preview,omitempty"`
 
 	Context   *MessageContext   `json:"context,omitempty"`
@@ -101,6 +102,7 @@ type InteractiveType string
 const (
 	InteractiveButtonReply InteractiveType = "button_reply"
 	InteractiveListReply   InteractiveType = "list_reply"
+	InteractiveNfmReply    InteractiveType = "nfm_reply"
 )
 
 // Interactive is present in a message if type=interactive
@@ -109,6 +111,8 @@ type Interactive struct {
 	ID          string          `json:"id"`
 	Title       string          `json:"title"`
 	Description string          `json:"description"` // if type=list_reply
+	// NfmReply is the completed flow, if type=nfm_reply.
+	NfmReply *fbgraph.NfmReply `json:"nfm_reply,omitempty"`
 }
 
 // Button is present in a message if type=button
@@ -124,3 +128,24 @@ type Location struct {
 	Longitude float64 `json:"longitude"`
 	Name      string  `json:"name"`
 }
+
+// Order is present in a message if type=order: a cart the client sent from a
+// product or product_list message.
+type Order struct {
+	CatalogID string      `json:"catalog_id"`
+	Text      string      `json:"text,omitempty"`
+	Items     []OrderItem `json:"items"`
+	// Total is the sum of item_price * quantity of the items, in Currency.
+	// Both are omitted when the items are in different currencies, as such a
+	// cart has no total.
+	Total    *float64 `json:"total,omitempty"`
+	Currency string   `json:"currency,omitempty"`
+}
+
+// OrderItem is a product of an Order.
+type OrderItem struct {
+	ProductRetailerID string  `json:"product_retailer_id"`
+	Quantity          int     `json:"quantity"`
+	ItemPrice         float64 `json:"item_price"`
+	Currency          string  `json:"currency"`
+}
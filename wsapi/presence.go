@@ -14,6 +14,9 @@ type PresenceTypingToClient struct {
 	AgentID   string `json:"agent_id,omitempty"`
 	AgentName string `json:"agent_name,omitempty"` // optional, but it is required if the agent_id is empty
 	ClientID  uint64 `json:"client_id,omitempty"`
+	// WABAMessageID is the contact's message being replied to. When set, the
+	// contact sees WhatsApp's typing indicator (see SendToWhatsApp).
+	WABAMessageID string `json:"waba_message_id,omitempty"`
 }
 
 // PresenceRequest is sent by a browser client to request the current presence state
@@ -0,0 +1,336 @@
+package wsapi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+var (
+	tplVariableRegexp      = regexp.MustCompile(`\{\{\s*([0-9]+)\s*\}\}`)
+	tplNamedVariableRegexp = regexp.MustCompile(`\{\{\s*([a-z_][a-z0-9_]*)\s*\}\}`)
+)
+
+// RenderTemplate renders the template send tpl, made against the template
+// definition def, into the ParsedTemplate shown in the inbox. Text parameters,
+// and the fallback values of currency and date_time parameters, replace the
+// {{n}} variables of the header, body and URL buttons; media parameters become
+// the header content. Carousels are rendered card by card. For templates with
+// the NAMED parameter format, header and body variables are {{name}}s and are
+// matched to parameters by their parameter_name.
+//
+// Every mismatch between def and tpl (a missing component, a wrong number of
+// parameters, a parameter of the wrong type) is reported in the returned error.
+func RenderTemplate(def fbgraph.MessageTemplate, tpl *fbgraph.TemplateObject) (*ParsedTemplate, error) {
+	if tpl == nil {
+		return nil, errors.New("render template: nil template object")
+	}
+	r := &tplRenderer{named: strings.EqualFold(string(def.ParameterFormat), string(fbgraph.ParameterFormatNamed))}
+	out := &ParsedTemplate{TemplateName: def.Name, LanguageCode: def.Language}
+	if tpl.Language != nil && tpl.Language.Code != "" {
+		out.LanguageCode = tpl.Language.Code
+	}
+	if tpl.Name != "" && def.Name != "" && tpl.Name != def.Name {
+		r.fail("template name %q does not match definition %q", tpl.Name, def.Name)
+	}
+
+	send := indexTemplateComponents(tpl.Components)
+	for _, c := range def.Components {
+		switch {
+		case c.Type.Equals(fbgraph.MTComponentHeader):
+			out.Header = r.header("header", c.Format, c.Text, c.Example, send.header)
+		case c.Type.Equals(fbgraph.MTComponentBody):
+			out.Body = r.varText("body", c.Text, send.body)
+		case c.Type.Equals(fbgraph.MTComponentFooter):
+			out.Footer = c.Text
+		case c.Type.Equals(fbgraph.MTComponentButtons):
+			out.Buttons = r.buttons("", c.Buttons, send.buttons)
+		case c.Type.Equals(fbgraph.MTComponentCarousel):
+			out.Cards = r.carousel(c.Cards, send.carousel)
+		}
+	}
+	fillDeprecatedButtons(out)
+
+	if err := errors.Join(r.errs...); err != nil {
+		return nil, fmt.Errorf("render template %s: %w", def.Name, err)
+	}
+	return out, nil
+}
+
+type tplRenderer struct {
+	named bool
+	errs  []error
+}
+
+func (r *tplRenderer) fail(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Errorf(format, args...))
+}
+
+// sentComponents are the components of a template send, by role.
+type sentComponents struct {
+	header   *fbgraph.TemplateComponent
+	body     *fbgraph.TemplateComponent
+	buttons  map[int]*fbgraph.TemplateComponent // by button index
+	carousel *fbgraph.TemplateComponent
+}
+
+func indexTemplateComponents(components []fbgraph.TemplateComponent) sentComponents {
+	sc := sentComponents{buttons: make(map[int]*fbgraph.TemplateComponent)}
+	for i := range components {
+		c := &components[i]
+		switch strings.ToLower(c.Type) {
+		case "header":
+			sc.header = c
+		case "body":
+			sc.body = c
+		case "button":
+			idx := 0
+			if c.Index != nil {
+				idx = *c.Index
+			}
+			sc.buttons[idx] = c
+		case "carousel":
+			sc.carousel = c
+		}
+	}
+	return sc
+}
+
+// paramText is the text a parameter stands for in rendered text.
+func paramText(p fbgraph.TemplateComponentParameter) (string, bool) {
+	switch strings.ToLower(p.Type) {
+	case "text":
+		return p.Text, true
+	case "currency":
+		if p.Currency != nil {
+			return p.Currency.FallbackValue, true
+		}
+	case "date_time":
+		if p.DateTime != nil {
+			return p.DateTime.FallbackValue, true
+		}
+	}
+	return "", false
+}
+
+// text substitutes the {{n}} variables of text with the parameters of sent.
+func (r *tplRenderer) text(path, text string, sent *fbgraph.TemplateComponent) string {
+	var params []fbgraph.TemplateComponentParameter
+	if sent != nil {
+		params = sent.Parameters
+	}
+	want := countTplVariables(text)
+	if len(params) != want {
+		r.fail("%s: template has %d variable(s), got %d parameter(s)", path, want, len(params))
+	}
+	values := make([]string, len(params))
+	for i, p := range params {
+		v, ok := paramText(p)
+		if !ok {
+			r.fail("%s: parameter %d: type %q cannot be used in text", path, i+1, p.Type)
+		}
+		values[i] = v
+	}
+	return tplVariableRegexp.ReplaceAllStringFunc(text, func(m string) string {
+		n, _ := strconv.Atoi(tplVariableRegexp.FindStringSubmatch(m)[1])
+		if n < 1 || n > len(values) {
+			return m
+		}
+		return values[n-1]
+	})
+}
+
+// varText renders a header or body text, whose variables follow the
+// template's parameter format. URL buttons are always positional.
+func (r *tplRenderer) varText(path, text string, sent *fbgraph.TemplateComponent) string {
+	if r.named {
+		return r.namedText(path, text, sent)
+	}
+	return r.text(path, text, sent)
+}
+
+// namedText substitutes the {{name}} variables of text with the parameters of
+// sent that carry the same parameter_name.
+func (r *tplRenderer) namedText(path, text string, sent *fbgraph.TemplateComponent) string {
+	values := make(map[string]string)
+	if sent != nil {
+		for i, p := range sent.Parameters {
+			if p.ParameterName == "" {
+				r.fail("%s: parameter %d: missing parameter_name", path, i+1)
+				continue
+			}
+			v, ok := paramText(p)
+			if !ok {
+				r.fail("%s: parameter %q: type %q cannot be used in text", path, p.ParameterName, p.Type)
+			}
+			values[p.ParameterName] = v
+		}
+	}
+	used := make(map[string]bool)
+	out := tplNamedVariableRegexp.ReplaceAllStringFunc(text, func(m string) string {
+		name := tplNamedVariableRegexp.FindStringSubmatch(m)[1]
+		v, ok := values[name]
+		if !ok {
+			if !used[name] {
+				r.fail("%s: no parameter for {{%s}}", path, name)
+			}
+			used[name] = true
+			return m
+		}
+		used[name] = true
+		return v
+	})
+	for name := range values {
+		if !used[name] {
+			r.fail("%s: template has no {{%s}} variable", path, name)
+		}
+	}
+	return out
+}
+
+// countTplVariables returns the highest {{n}} in text, which is the number of
+// parameters a send must carry for it.
+func countTplVariables(text string) int {
+	highest := 0
+	for _, m := range tplVariableRegexp.FindAllStringSubmatch(text, -1) {
+		if n, _ := strconv.Atoi(m[1]); n > highest {
+			highest = n
+		}
+	}
+	return highest
+}
+
+func (r *tplRenderer) header(path, format, text string, example *fbgraph.MessageTemplateExample, sent *fbgraph.TemplateComponent) *ParsedTemplateHeader {
+	switch f := strings.ToUpper(format); f {
+	case "", string(fbgraph.MTCFormatText):
+		return &ParsedTemplateHeader{
+			HeaderType:     TplHeaderTypeText,
+			Type:           "text",
+			ContentExample: text,
+			Content:        r.varText(path, text, sent),
+		}
+	case string(fbgraph.MTCFormatImage), string(fbgraph.MTCFormatVideo), string(fbgraph.MTCFormatDocument):
+		h := &ParsedTemplateHeader{HeaderType: TplHeaderTypeMedia, Type: strings.ToLower(f)}
+		if example != nil && len(example.HeaderHandle) > 0 {
+			h.ContentExample = example.HeaderHandle[0]
+		}
+		if sent == nil || len(sent.Parameters) != 1 {
+			r.fail("%s: %s header needs exactly 1 parameter", path, h.Type)
+			return h
+		}
+		p := sent.Parameters[0]
+		if !strings.EqualFold(p.Type, h.Type) {
+			r.fail("%s: %s header got a %q parameter", path, h.Type, p.Type)
+			return h
+		}
+		h.Content = mediaParamContent(p)
+		return h
+	default:
+		// e.g. LOCATION headers have no text to show
+		return &ParsedTemplateHeader{HeaderType: TplHeaderTypeNone, Type: strings.ToLower(f)}
+	}
+}
+
+// mediaParamContent is the link of a media parameter, or its media ID.
+func mediaParamContent(p fbgraph.TemplateComponentParameter) string {
+	switch {
+	case p.Image != nil:
+		return firstNonEmpty(p.Image.Link, p.Image.ID)
+	case p.Video != nil:
+		return firstNonEmpty(p.Video.Link, p.Video.ID)
+	case p.Document != nil:
+		return firstNonEmpty(p.Document.Link, p.Document.ID)
+	}
+	return ""
+}
+
+func firstNonEmpty(s ...string) string {
+	for _, v := range s {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// buttons renders template buttons. OTP buttons are left out: their text is
+// supplied by WhatsApp.
+func (r *tplRenderer) buttons(prefix string, def []fbgraph.MessageTemplateButton, sent map[int]*fbgraph.TemplateComponent) []TplButton {
+	var out []TplButton
+	for i, b := range def {
+		path := fmt.Sprintf("%sbuttons[%d]", prefix, i)
+		switch fbgraph.MessageTemplateButtonType(strings.ToUpper(string(b.Type))) {
+		case fbgraph.MTBTypeQuickReply:
+			out = append(out, TplButton{Type: TemplateButtonQuickReply, Text: b.Text})
+		case fbgraph.MTBTypePhoneNumber:
+			out = append(out, TplButton{Type: TemplateButtonCall, Text: b.Text, Call: &TplCallToActionCall{Phone: b.PhoneNumber}})
+		case fbgraph.MTBTypeURL:
+			u := &TplCallToActionURL{Type: "static", Href: b.URL}
+			if countTplVariables(b.URL) > 0 {
+				u.Type = "dynamic"
+				u.Href = r.text(path, b.URL, sent[i])
+			}
+			out = append(out, TplButton{Type: TemplateButtonURL, Text: b.Text, URL: u})
+		}
+	}
+	return out
+}
+
+func (r *tplRenderer) carousel(def []fbgraph.MessageTemplateCard, sent *fbgraph.TemplateComponent) []TplCard {
+	sentCards := make(map[int]*fbgraph.TemplateCardComponent)
+	if sent != nil {
+		for i := range sent.Cards {
+			idx := i
+			if sent.Cards[i].CardIndex != nil {
+				idx = *sent.Cards[i].CardIndex
+			}
+			sentCards[idx] = &sent.Cards[i]
+		}
+	}
+	if len(sentCards) != len(def) {
+		r.fail("carousel: template has %d card(s), got %d", len(def), len(sentCards))
+	}
+
+	cards := make([]TplCard, len(def))
+	for i, dc := range def {
+		path := fmt.Sprintf("cards[%d]", i)
+		var sc sentComponents
+		if c, ok := sentCards[i]; ok {
+			sc = indexTemplateComponents(c.Components)
+		} else {
+			sc = indexTemplateComponents(nil)
+		}
+		for _, c := range dc.Components {
+			switch {
+			case c.Type.Equals(fbgraph.MTComponentHeader):
+				cards[i].Header = r.header(path+".header", c.Format, c.Text, c.Example, sc.header)
+			case c.Type.Equals(fbgraph.MTComponentBody):
+				cards[i].Body = r.varText(path+".body", c.Text, sc.body)
+			case c.Type.Equals(fbgraph.MTComponentButtons):
+				cards[i].Buttons = r.buttons(path+".", c.Buttons, sc.buttons)
+			}
+		}
+	}
+	return cards
+}
+
+// fillDeprecatedButtons mirrors Buttons into the fields older inbox clients
+// still read.
+func fillDeprecatedButtons(p *ParsedTemplate) {
+	p.ButtonsType = TplButtonsTypeNone
+	for _, b := range p.Buttons {
+		if b.Type == TemplateButtonQuickReply {
+			p.ButtonsType = TplButtonsTypeQuickReply
+			p.QuickReplyButtons = append(p.QuickReplyButtons, TplQuickReplyButton{Text: b.Text})
+			continue
+		}
+		if p.ButtonsType == TplButtonsTypeNone {
+			p.ButtonsType = TplButtonsTypeCallToAction
+		}
+		p.CallToActionButtons = append(p.CallToActionButtons, TplCallToActionButton(b))
+	}
+}
@@ -0,0 +1,150 @@
+package wsapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	def := fbgraph.MessageTemplate{
+		Name:     "order_update",
+		Language: "pt_BR",
+		Components: []fbgraph.MessageTemplateComponent{
+			{Type: fbgraph.MTComponentHeader, Format: "TEXT", Text: "Pedido {{1}}"},
+			{Type: fbgraph.MTComponentBody, Text: "Olá {{1}}, o total de {{2}} vence em {{3}}."},
+			{Type: fbgraph.MTComponentFooter, Text: "Pedido Pago"},
+			{Type: fbgraph.MTComponentButtons, Buttons: []fbgraph.MessageTemplateButton{
+				{Type: fbgraph.MTBTypeURL, Text: "Pagar", URL: "https://pay.example.com/{{1}}"},
+				{Type: fbgraph.MTBTypeQuickReply, Text: "Já paguei"},
+			}},
+		},
+	}
+	idx := 0
+	send := &fbgraph.TemplateObject{
+		Name:     "order_update",
+		Language: &fbgraph.LanguageObject{Code: "pt_BR"},
+		Components: []fbgraph.TemplateComponent{
+			{Type: "header", Parameters: []fbgraph.TemplateComponentParameter{{Type: "text", Text: "#42"}}},
+			{Type: "body", Parameters: []fbgraph.TemplateComponentParameter{
+				{Type: "text", Text: "Ana"},
+				{Type: "currency", Currency: &fbgraph.CurrencyParameters{FallbackValue: "R$ 10,00", Code: "BRL", Amount1000: 10000}},
+				{Type: "date_time", DateTime: &fbgraph.DateTimeParameters{FallbackValue: "10/05"}},
+			}},
+			{Type: "button", SubType: "url", Index: &idx, Parameters: []fbgraph.TemplateComponentParameter{{Type: "text", Text: "abc"}}},
+		},
+	}
+
+	p, err := RenderTemplate(def, send)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Header == nil || p.Header.Content != "Pedido #42" || p.Header.HeaderType != TplHeaderTypeText {
+		t.Errorf("header = %+v", p.Header)
+	}
+	if p.Body != "Olá Ana, o total de R$ 10,00 vence em 10/05." {
+		t.Errorf("body = %q", p.Body)
+	}
+	if p.Footer != "Pedido Pago" {
+		t.Errorf("footer = %q", p.Footer)
+	}
+	if len(p.Buttons) != 2 || p.Buttons[0].URL.Href != "https://pay.example.com/abc" || p.Buttons[0].URL.Type != "dynamic" {
+		t.Errorf("buttons = %+v", p.Buttons)
+	}
+	if len(p.QuickReplyButtons) != 1 || len(p.CallToActionButtons) != 1 {
+		t.Errorf("deprecated buttons = %+v / %+v", p.QuickReplyButtons, p.CallToActionButtons)
+	}
+}
+
+func TestRenderTemplateCarousel(t *testing.T) {
+	card := fbgraph.MessageTemplateCard{Components: []fbgraph.MessageTemplateCardComponent{
+		{Type: fbgraph.MTComponentHeader, Format: "IMAGE"},
+		{Type: fbgraph.MTComponentBody, Text: "{{1}} por {{2}}"},
+		{Type: fbgraph.MTComponentButtons, Buttons: []fbgraph.MessageTemplateButton{{Type: fbgraph.MTBTypeURL, Text: "Ver", URL: "https://shop.example.com/p/{{1}}"}}},
+	}}
+	def := fbgraph.MessageTemplate{Name: "offers", Language: "pt_BR", Components: []fbgraph.MessageTemplateComponent{
+		{Type: fbgraph.MTComponentBody, Text: "Ofertas da semana"},
+		{Type: fbgraph.MTComponentCarousel, Cards: []fbgraph.MessageTemplateCard{card, card}},
+	}}
+	sendCard := func(i int, img, name, price, sku string) fbgraph.TemplateCardComponent {
+		zero := 0
+		return fbgraph.TemplateCardComponent{CardIndex: &i, Components: []fbgraph.TemplateComponent{
+			{Type: "header", Parameters: []fbgraph.TemplateComponentParameter{{Type: "image", Image: &fbgraph.ImageParameters{Link: img}}}},
+			{Type: "body", Parameters: []fbgraph.TemplateComponentParameter{{Type: "text", Text: name}, {Type: "text", Text: price}}},
+			{Type: "button", SubType: "url", Index: &zero, Parameters: []fbgraph.TemplateComponentParameter{{Type: "text", Text: sku}}},
+		}}
+	}
+	send := &fbgraph.TemplateObject{Name: "offers", Components: []fbgraph.TemplateComponent{
+		{Type: "carousel", Cards: []fbgraph.TemplateCardComponent{
+			sendCard(1, "https://cdn/b.jpg", "Camisa", "R$ 50", "b"),
+			sendCard(0, "https://cdn/a.jpg", "Tênis", "R$ 200", "a"),
+		}},
+	}}
+
+	p, err := RenderTemplate(def, send)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Cards) != 2 {
+		t.Fatalf("cards = %+v", p.Cards)
+	}
+	c0 := p.Cards[0]
+	if c0.Header.Content != "https://cdn/a.jpg" || c0.Header.Type != "image" || c0.Body != "Tênis por R$ 200" || c0.Buttons[0].URL.Href != "https://shop.example.com/p/a" {
+		t.Errorf("card 0 = %+v %+v", c0, c0.Header)
+	}
+	if p.Cards[1].Body != "Camisa por R$ 50" {
+		t.Errorf("card 1 = %+v", p.Cards[1])
+	}
+}
+
+func TestRenderTemplateMismatches(t *testing.T) {
+	def := fbgraph.MessageTemplate{Name: "t", Language: "pt_BR", Components: []fbgraph.MessageTemplateComponent{
+		{Type: fbgraph.MTComponentHeader, Format: "IMAGE"},
+		{Type: fbgraph.MTComponentBody, Text: "Olá {{1}} {{2}}"},
+	}}
+	send := &fbgraph.TemplateObject{Name: "t", Components: []fbgraph.TemplateComponent{
+		{Type: "body", Parameters: []fbgraph.TemplateComponentParameter{{Type: "text", Text: "Ana"}}},
+	}}
+	_, err := RenderTemplate(def, send)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"header: image header needs exactly 1 parameter", "body: template has 2 variable(s), got 1 parameter(s)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestRenderTemplateNamed(t *testing.T) {
+	def := fbgraph.MessageTemplate{Name: "order_update", Language: "pt_BR", ParameterFormat: fbgraph.ParameterFormatNamed, Components: []fbgraph.MessageTemplateComponent{
+		{Type: fbgraph.MTComponentHeader, Format: "TEXT", Text: "Pedido {{order_id}}"},
+		{Type: fbgraph.MTComponentBody, Text: "Olá {{first_name}}, seu pedido {{order_id}} saiu para entrega."},
+	}}
+	send := &fbgraph.TemplateObject{Name: "order_update", Components: []fbgraph.TemplateComponent{
+		{Type: "header", Parameters: []fbgraph.TemplateComponentParameter{{Type: "text", ParameterName: "order_id", Text: "#42"}}},
+		{Type: "body", Parameters: []fbgraph.TemplateComponentParameter{
+			{Type: "text", ParameterName: "order_id", Text: "#42"},
+			{Type: "text", ParameterName: "first_name", Text: "Ana"},
+		}},
+	}}
+	p, err := RenderTemplate(def, send)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Header.Content != "Pedido #42" || p.Body != "Olá Ana, seu pedido #42 saiu para entrega." {
+		t.Errorf("header = %q, body = %q", p.Header.Content, p.Body)
+	}
+
+	send.Components[1].Parameters[1].ParameterName = "name"
+	_, err = RenderTemplate(def, send)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"body: no parameter for {{first_name}}", "body: template has no {{name}} variable"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package wsapi
+
+import (
+	"context"
+
+	"github.com/pedidopago/wabaman-contrib/fbgraph"
+)
+
+// SendToWhatsApp marks the receipt's message as read on WhatsApp, so the
+// contact sees blue check marks.
+func (r *ReadByHostReceipt) SendToWhatsApp(ctx context.Context, c *fbgraph.Client, phoneID string) error {
+	return c.MarkAsRead(ctx, phoneID, r.WABAMessageID)
+}
+
+// SendToWhatsApp shows the contact WhatsApp's typing indicator, which also
+// marks WABAMessageID as read. Presence without a WABAMessageID stays in the
+// inbox and is not sent. WhatsApp hides the indicator after 25 seconds, so
+// repeated typing presence keeps it up.
+func (p *PresenceTypingToClient) SendToWhatsApp(ctx context.Context, c *fbgraph.Client, phoneID string) error {
+	if p.WABAMessageID == "" {
+		return nil
+	}
+	return c.SendTypingIndicator(ctx, phoneID, p.WABAMessageID)
+}